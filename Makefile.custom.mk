@@ -0,0 +1,16 @@
+##@ Testing
+
+ENVTEST_K8S_VERSION ?= 1.22.x
+CONTROLLER_GEN_VERSION ?= v0.17.3
+
+.PHONY: test-integration
+test-integration: ## Run envtest integration tests with real Cluster API CRDs.
+	KUBEBUILDER_ASSETS="$$(go run sigs.k8s.io/controller-runtime/tools/setup-envtest@latest use $(ENVTEST_K8S_VERSION) -p path)" \
+		go test -tags integration -count=1 ./pkg/integration/...
+
+##@ Generate
+
+.PHONY: generate-mock-crd
+generate-mock-crd: ## Generate mock provider CRD used by integration tests.
+	go run sigs.k8s.io/controller-tools/cmd/controller-gen@$(CONTROLLER_GEN_VERSION) crd \
+		paths=./pkg/internal/... output:crd:dir=./pkg/integration/testdata/crds
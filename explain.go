@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/giantswarm/conditions-handler/pkg/catalog"
+)
+
+// runExplain prints registered condition types, statuses, severities,
+// messages, descriptions and remediation hints of the specified reasons. It
+// returns exitFailure when any reason is not registered.
+func runExplain(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: conditions-handler explain reason...\n")
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	c := catalog.NewDefaultCatalog()
+	exitCode := exitOK
+	for i, reason := range flags.Args() {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+
+		explanations := c.Explain(reason)
+		if len(explanations) == 0 {
+			fmt.Fprintf(stderr, "reason %q is not registered\n", catalog.TrimSourceRef(reason))
+			exitCode = exitFailure
+			continue
+		}
+
+		for j, explanation := range explanations {
+			if j > 0 {
+				fmt.Fprintln(stdout)
+			}
+			printExplanation(stdout, explanation)
+		}
+	}
+
+	return exitCode
+}
+
+func printExplanation(w io.Writer, explanation catalog.Explanation) {
+	conditionType := explanation.ConditionType
+	reason := explanation.Reason
+
+	conditionTypeName := string(conditionType.Type)
+	if conditionTypeName == "" {
+		conditionTypeName = "configured in handler"
+	}
+	handlers := strings.Join(conditionType.Handlers, ", ")
+	if handlers == "" {
+		handlers = "all handlers"
+	}
+	var statuses, severities []string
+	for _, status := range reason.Statuses {
+		statuses = append(statuses, string(status))
+	}
+	for _, severity := range reason.Severities {
+		severities = append(severities, string(severity))
+	}
+
+	fmt.Fprintf(w, "%s\n", reason.Name)
+	printField(w, "Condition", conditionTypeName)
+	printField(w, "Kinds", strings.Join(conditionType.Kinds, ", "))
+	printField(w, "Handlers", handlers)
+	printField(w, "Status", strings.Join(statuses, ", "))
+	printField(w, "Severity", strings.Join(severities, ", "))
+	for _, messageTemplate := range reason.MessageTemplates {
+		printField(w, "Message", messageTemplate)
+	}
+	printField(w, "Description", reason.Description)
+	printField(w, "Remediation", reason.Remediation)
+}
+
+func printField(w io.Writer, name, value string) {
+	if value == "" {
+		return
+	}
+
+	fmt.Fprintf(w, "  %-12s %s\n", name+":", value)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunExplain(t *testing.T) {
+	testCases := []struct {
+		name             string
+		args             []string
+		expectedExitCode int
+		expectedStdout   []string
+		expectedStderr   string
+	}{
+		{
+			name:             "case 0: reason of one condition type",
+			args:             []string{"explain", "ScalingUp"},
+			expectedExitCode: exitOK,
+			expectedStdout: []string{
+				"ScalingUp",
+				"  Condition:   Scaling",
+				"  Kinds:       MachinePool",
+				"  Handlers:    scaling",
+				"  Status:      True",
+				"  Message:     Scaling from %d to %d replicas",
+				"  Description: The desired number of replicas is greater than the current number of replicas.",
+			},
+		},
+		{
+			name:             "case 1: aggregated reason",
+			args:             []string{"explain", "NoReplicas @ MachinePool/np1"},
+			expectedExitCode: exitOK,
+			expectedStdout: []string{
+				"NoReplicas",
+				"  Condition:   ReplicasReady",
+				"  Kinds:       MachinePool",
+				"  Handlers:    replicasReady",
+				"  Status:      False",
+				"  Severity:    Info",
+				"  Message:     MachinePool does not have any replicas",
+				"  Description: The MachinePool does not have any replicas and the NotReady zero replicas policy is configured.",
+			},
+		},
+		{
+			name:             "case 2: reason with remediation",
+			args:             []string{"explain", "SkipAnnotation"},
+			expectedExitCode: exitOK,
+			expectedStdout: []string{
+				"SkipAnnotation",
+				"  Condition:   Frozen",
+				"  Kinds:       Cluster, MachinePool",
+				"  Handlers:    frozen",
+				"  Status:      True",
+				"  Message:     Conditions frozen with %s annotation: %s",
+				"  Description: Conditions are listed in the conditions.giantswarm.io/skip annotation of the object.",
+				"  Remediation: Remove the conditions.giantswarm.io/skip annotation when the conditions should be updated again.",
+			},
+		},
+		{
+			name:             "case 3: unregistered reason",
+			args:             []string{"explain", "ScalingUp", "Foo"},
+			expectedExitCode: exitFailure,
+			expectedStdout: []string{
+				"ScalingUp",
+				"  Condition:   Scaling",
+				"  Kinds:       MachinePool",
+				"  Handlers:    scaling",
+				"  Status:      True",
+				"  Message:     Scaling from %d to %d replicas",
+				"  Description: The desired number of replicas is greater than the current number of replicas.",
+				"",
+			},
+			expectedStderr: "reason \"Foo\" is not registered\n",
+		},
+		{
+			name:             "case 4: missing reason",
+			args:             []string{"explain"},
+			expectedExitCode: exitUsage,
+			expectedStderr:   "Usage: conditions-handler explain reason...\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			// act
+			exitCode := run(tc.args, strings.NewReader(""), stdout, stderr)
+
+			// assert
+			if exitCode != tc.expectedExitCode {
+				t.Errorf("expected exit code %d, got %d, stderr: %s", tc.expectedExitCode, exitCode, stderr)
+			}
+			var lines []string
+			if stdout.Len() > 0 {
+				lines = strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			}
+			if strings.Join(lines, "\n") != strings.Join(tc.expectedStdout, "\n") {
+				t.Errorf("expected output:\n%s\ngot:\n%s", strings.Join(tc.expectedStdout, "\n"), stdout)
+			}
+			if stderr.String() != tc.expectedStderr {
+				t.Errorf("expected error output %q, got %q", tc.expectedStderr, stderr)
+			}
+		})
+	}
+}
+
+func TestRunExplainMultipleConditionTypes(t *testing.T) {
+	// arrange
+	stdout := &bytes.Buffer{}
+
+	// act
+	exitCode := run([]string{"explain", "UpgradeCompleted"}, strings.NewReader(""), stdout, &bytes.Buffer{})
+
+	// assert
+	if exitCode != exitOK {
+		t.Errorf("expected exit code %d, got %d", exitOK, exitCode)
+	}
+	for _, conditionType := range []string{"NodePoolsUpgrading", "Upgrading"} {
+		if !strings.Contains(stdout.String(), "  Condition:   "+conditionType+"\n") {
+			t.Errorf("expected explanation for %s condition, got:\n%s", conditionType, stdout)
+		}
+	}
+}
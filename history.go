@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/conditions-handler/pkg/history"
+)
+
+// objectHistory is the condition history of a single object, merged from its
+// history annotation and its companion ConfigMap.
+type objectHistory struct {
+	name    string
+	entries []history.Entry
+}
+
+// runHistory prints condition transitions recorded in history annotations of
+// all objects in the specified files, or in stdin when the file is "-", and in
+// companion ConfigMaps of the objects. It returns exitFailure when any
+// recorded history cannot be decoded.
+func runHistory(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var conditionTypes conditionTypesFlag
+
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var(&conditionTypes, "type", "Comma-separated condition types to show. By default transitions of all condition types are shown.")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: conditions-handler history [flags] file... | -\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	exitCode := exitOK
+	var histories []*objectHistory
+	index := map[string]*objectHistory{}
+	for _, file := range flags.Args() {
+		objects, err := readObjects(file, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", file, err)
+			return exitUsage
+		}
+
+		for _, object := range objects {
+			name, entries, err := decodeHistory(object)
+			if err != nil {
+				fmt.Fprintf(stderr, "%s: %s %s: invalid condition history: %s\n", file, object.GetKind(), objectName(object), err)
+				exitCode = exitFailure
+				continue
+			}
+			if len(entries) == 0 {
+				continue
+			}
+
+			h, ok := index[name]
+			if !ok {
+				h = &objectHistory{name: name}
+				index[name] = h
+				histories = append(histories, h)
+			}
+			h.entries = history.Merge(h.entries, entries)
+		}
+	}
+
+	printed := 0
+	for _, h := range histories {
+		var entries []history.Entry
+		for _, entry := range h.entries {
+			if len(conditionTypes) == 0 || containsConditionType(conditionTypes, entry) {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		if printed > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "%s\n", h.name)
+		for _, entry := range entries {
+			fmt.Fprintf(stdout, "  %s\n", entry)
+		}
+		printed++
+	}
+
+	return exitCode
+}
+
+// decodeHistory returns the condition history recorded in the history
+// annotation of the object, or in the data of a companion ConfigMap, together
+// with the kind and the name of the object the history belongs to.
+func decodeHistory(object *unstructured.Unstructured) (string, []history.Entry, error) {
+	name := fmt.Sprintf("%s %s", object.GetKind(), objectName(object))
+
+	if object.GetKind() == "ConfigMap" && object.GetAPIVersion() == "v1" {
+		data, _, _ := unstructured.NestedStringMap(object.Object, "data")
+		if _, ok := data[history.ConfigMapKey]; !ok {
+			return name, nil, nil
+		}
+
+		for _, owner := range object.GetOwnerReferences() {
+			if history.ConfigMapName(owner.Kind, owner.Name) == object.GetName() {
+				name = fmt.Sprintf("%s %s/%s", owner.Kind, object.GetNamespace(), owner.Name)
+			}
+		}
+
+		entries, err := history.DecodeConfigMap(&corev1.ConfigMap{Data: data})
+		return name, entries, err
+	}
+
+	entries, err := history.DecodeAnnotation(object.GetAnnotations()[history.Annotation])
+	return name, entries, err
+}
+
+func containsConditionType(conditionTypes conditionTypesFlag, entry history.Entry) bool {
+	for _, conditionType := range conditionTypes {
+		if conditionType == entry.Type {
+			return true
+		}
+	}
+
+	return false
+}
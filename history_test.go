@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/history"
+)
+
+var testHistory = []history.Entry{
+	{
+		Type:   capi.ReadyCondition,
+		From:   corev1.ConditionTrue,
+		To:     corev1.ConditionFalse,
+		Reason: capi.WaitingForInfrastructureFallbackReason,
+		Time:   metav1.NewTime(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)),
+	},
+	{
+		Type: capi.InfrastructureReadyCondition,
+		From: corev1.ConditionFalse,
+		To:   corev1.ConditionTrue,
+		Time: metav1.NewTime(time.Date(2022, 3, 1, 10, 5, 0, 0, time.UTC)),
+	},
+	{
+		Type: capi.ReadyCondition,
+		From: corev1.ConditionFalse,
+		To:   corev1.ConditionTrue,
+		Time: metav1.NewTime(time.Date(2022, 3, 1, 10, 6, 0, 0, time.UTC)),
+	},
+}
+
+func TestRunHistory(t *testing.T) {
+	annotation, err := history.EncodeAnnotation(testHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configMapData, err := json.Marshal(testHistory[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusterWithAnnotation := fmt.Sprintf(`
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: test1
+  namespace: org-test
+  annotations:
+    %s: %s
+`, history.Annotation, annotation)
+	companionConfigMap := fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: machinepool-np1-condition-history
+  namespace: org-test
+  ownerReferences:
+  - apiVersion: cluster.x-k8s.io/v1beta1
+    kind: MachinePool
+    name: np1
+    uid: 5d0c5ee8-7c8e-4bd2-a3a5-8a1b2d6c1d0e
+data:
+  %s: '%s'
+`, history.ConfigMapKey, configMapData)
+
+	testCases := []struct {
+		name             string
+		args             []string
+		stdin            string
+		expectedExitCode int
+		expectedStdout   []string
+	}{
+		{
+			name:             "case 0: history from annotation",
+			args:             []string{"history", "-"},
+			stdin:            clusterWithAnnotation,
+			expectedExitCode: exitOK,
+			expectedStdout: []string{
+				"Cluster org-test/test1",
+				"  2022-03-01T10:00:00Z Ready True -> False WaitingForInfrastructure",
+				"  2022-03-01T10:05:00Z InfrastructureReady False -> True",
+				"  2022-03-01T10:06:00Z Ready False -> True",
+			},
+		},
+		{
+			name:             "case 1: history filtered by condition type",
+			args:             []string{"history", "-type", "InfrastructureReady", "-"},
+			stdin:            clusterWithAnnotation,
+			expectedExitCode: exitOK,
+			expectedStdout: []string{
+				"Cluster org-test/test1",
+				"  2022-03-01T10:05:00Z InfrastructureReady False -> True",
+			},
+		},
+		{
+			name:             "case 2: history from companion ConfigMap is shown for its owner",
+			args:             []string{"history", "-"},
+			stdin:            clusterWithAnnotation + "---\n" + companionConfigMap + "---\n" + validCluster,
+			expectedExitCode: exitOK,
+			expectedStdout: []string{
+				"Cluster org-test/test1",
+				"  2022-03-01T10:00:00Z Ready True -> False WaitingForInfrastructure",
+				"  2022-03-01T10:05:00Z InfrastructureReady False -> True",
+				"  2022-03-01T10:06:00Z Ready False -> True",
+				"",
+				"MachinePool org-test/np1",
+				"  2022-03-01T10:00:00Z Ready True -> False WaitingForInfrastructure",
+			},
+		},
+		{
+			name:             "case 3: invalid history annotation",
+			args:             []string{"history", "-"},
+			stdin:            strings.Replace(clusterWithAnnotation, annotation, "invalid", 1),
+			expectedExitCode: exitFailure,
+		},
+		{
+			name:             "case 4: missing files",
+			args:             []string{"history"},
+			expectedExitCode: exitUsage,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			// act
+			exitCode := run(tc.args, strings.NewReader(tc.stdin), stdout, stderr)
+
+			// assert
+			if exitCode != tc.expectedExitCode {
+				t.Errorf("expected exit code %d, got %d, stderr: %s", tc.expectedExitCode, exitCode, stderr)
+			}
+			var lines []string
+			if stdout.Len() > 0 {
+				lines = strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			}
+			if strings.Join(lines, "\n") != strings.Join(tc.expectedStdout, "\n") {
+				t.Errorf("expected output:\n%s\ngot:\n%s", strings.Join(tc.expectedStdout, "\n"), stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/catalog"
+	"github.com/giantswarm/conditions-handler/pkg/validation"
+)
+
+// conditionTypesFlag is a comma-separated list of condition types, which can
+// be specified multiple times.
+type conditionTypesFlag []capi.ConditionType
+
+func (f *conditionTypesFlag) String() string {
+	var types []string
+	for _, t := range *f {
+		types = append(types, string(t))
+	}
+	return strings.Join(types, ",")
+}
+
+func (f *conditionTypesFlag) Set(value string) error {
+	for _, t := range strings.Split(value, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			*f = append(*f, capi.ConditionType(t))
+		}
+	}
+	return nil
+}
+
+// runLint checks conditions of all objects in the specified files, or in
+// stdin when the file is "-", including reasons of condition types registered
+// in the default catalog. It prints one line per violation and returns
+// exitFailure when any violation is found.
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var extraConditionTypes, negativePolarityConditionTypes conditionTypesFlag
+
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var(&extraConditionTypes, "extra-condition-types", "Comma-separated condition types that are known for all kinds and may have any reason, e.g. types set by custom handlers.")
+	flags.Var(&negativePolarityConditionTypes, "negative-polarity-condition-types", "Comma-separated condition types, in addition to the default ones, that may have a reason when True.")
+	maxClockSkew := flags.Duration("max-clock-skew", 0, "Tolerated difference between last transition times in the future and the current time.")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: conditions-handler lint [flags] file... | -\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	c := catalog.NewDefaultCatalog()
+	for _, conditionType := range extraConditionTypes {
+		err = c.Register(catalog.CustomConditionType(conditionType))
+		if err != nil {
+			fmt.Fprintf(stderr, "%s\n", err)
+			return exitUsage
+		}
+	}
+
+	v, err := validation.NewValidator(validation.Config{
+		Catalog:                        c,
+		ExtraConditionTypes:            extraConditionTypes,
+		NegativePolarityConditionTypes: append(negativePolarityConditionTypes, validation.DefaultNegativePolarityConditionTypes...),
+		MaxClockSkew:                   *maxClockSkew,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return exitUsage
+	}
+
+	exitCode := exitOK
+	for _, file := range flags.Args() {
+		objects, err := readObjects(file, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", file, err)
+			return exitUsage
+		}
+
+		for _, object := range objects {
+			for _, violation := range v.ValidateObject(capiconditions.UnstructuredGetter(object)) {
+				fmt.Fprintf(stdout, "%s: %s %s: %s\n", file, object.GetKind(), objectName(object), violation)
+				exitCode = exitFailure
+			}
+		}
+	}
+
+	return exitCode
+}
+
+// readObjects decodes all YAML or JSON documents in the specified file, and
+// returns the objects in them, including items of lists.
+func readObjects(file string, stdin io.Reader) ([]*unstructured.Unstructured, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var document map[string]interface{}
+		err = decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if len(document) == 0 {
+			continue
+		}
+
+		object := &unstructured.Unstructured{Object: document}
+		if !object.IsList() {
+			objects = append(objects, object)
+			continue
+		}
+
+		err = object.EachListItem(func(item runtime.Object) error {
+			objects = append(objects, item.(*unstructured.Unstructured))
+			return nil
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return objects, nil
+}
+
+func objectName(object *unstructured.Unstructured) string {
+	if object.GetNamespace() == "" {
+		return object.GetName()
+	}
+
+	return object.GetNamespace() + "/" + object.GetName()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validCluster = `
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: test1
+  namespace: org-test
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+    lastTransitionTime: "2022-03-01T10:00:00Z"
+  - type: InfrastructureReady
+    status: "False"
+    severity: Warning
+    reason: WaitingForInfrastructure
+    lastTransitionTime: "2022-03-01T10:00:00Z"
+`
+
+const invalidMachinePoolList = `
+{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "apiVersion": "cluster.x-k8s.io/v1beta1",
+      "kind": "MachinePool",
+      "metadata": {"name": "np1", "namespace": "org-test"},
+      "status": {
+        "conditions": [
+          {"type": "ReplicasReady", "status": "False", "reason": "not ready", "lastTransitionTime": "2022-03-01T10:00:00Z"},
+          {"type": "KubeconfigSecretPresent", "status": "True", "lastTransitionTime": "2022-03-01T10:00:00Z"}
+        ]
+      }
+    }
+  ]
+}
+`
+
+func TestRunLint(t *testing.T) {
+	testCases := []struct {
+		name             string
+		args             []string
+		stdin            string
+		expectedExitCode int
+		expectedStdout   []string
+	}{
+		{
+			name:             "case 0: valid object from stdin",
+			args:             []string{"lint", "-"},
+			stdin:            validCluster,
+			expectedExitCode: exitOK,
+		},
+		{
+			name:             "case 1: invalid objects in a list",
+			args:             []string{"lint", "-"},
+			stdin:            invalidMachinePoolList,
+			expectedExitCode: exitFailure,
+			expectedStdout: []string{
+				"-: MachinePool org-test/np1: ReplicasReady: SeverityRequired: False condition does not have severity",
+				`-: MachinePool org-test/np1: ReplicasReady: ReasonNotCamelCase: reason "not ready" is not CamelCase`,
+				`-: MachinePool org-test/np1: ReplicasReady: UnregisteredReason: reason "not ready" is not registered`,
+				"-: MachinePool org-test/np1: KubeconfigSecretPresent: UnknownType: condition type is not known for MachinePool",
+			},
+		},
+		{
+			name:             "case 2: extra condition type",
+			args:             []string{"lint", "-extra-condition-types", "KubeconfigSecretPresent", "-"},
+			stdin:            invalidMachinePoolList,
+			expectedExitCode: exitFailure,
+			expectedStdout: []string{
+				"-: MachinePool org-test/np1: ReplicasReady: SeverityRequired: False condition does not have severity",
+				`-: MachinePool org-test/np1: ReplicasReady: ReasonNotCamelCase: reason "not ready" is not CamelCase`,
+				`-: MachinePool org-test/np1: ReplicasReady: UnregisteredReason: reason "not ready" is not registered`,
+			},
+		},
+		{
+			name:             "case 3: multiple documents",
+			args:             []string{"lint", "-"},
+			stdin:            validCluster + "---\n" + strings.Replace(validCluster, "severity: Warning", "severity: Critical", 1),
+			expectedExitCode: exitFailure,
+			expectedStdout: []string{
+				`-: Cluster org-test/test1: InfrastructureReady: InvalidSeverity: severity "Critical" is not Error, Warning or Info`,
+				`-: Cluster org-test/test1: InfrastructureReady: UnregisteredReason: reason "WaitingForInfrastructure" is not registered for status False and severity "Critical"`,
+			},
+		},
+		{
+			name:             "case 4: missing files",
+			args:             []string{"lint"},
+			expectedExitCode: exitUsage,
+		},
+		{
+			name:             "case 5: unknown flag",
+			args:             []string{"lint", "-strict", "-"},
+			expectedExitCode: exitUsage,
+		},
+		{
+			name:             "case 6: negative max clock skew",
+			args:             []string{"lint", "-max-clock-skew", "-1m", "-"},
+			expectedExitCode: exitUsage,
+		},
+		{
+			name:             "case 7: unknown command",
+			args:             []string{"fix", "-"},
+			expectedExitCode: exitUsage,
+		},
+		{
+			name:             "case 8: invalid document",
+			args:             []string{"lint", "-"},
+			stdin:            "kind: [Cluster",
+			expectedExitCode: exitUsage,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			// act
+			exitCode := run(tc.args, strings.NewReader(tc.stdin), stdout, stderr)
+
+			// assert
+			if exitCode != tc.expectedExitCode {
+				t.Errorf("expected exit code %d, got %d, stderr: %s", tc.expectedExitCode, exitCode, stderr)
+			}
+			var lines []string
+			if stdout.Len() > 0 {
+				lines = strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			}
+			if strings.Join(lines, "\n") != strings.Join(tc.expectedStdout, "\n") {
+				t.Errorf("expected output:\n%s\ngot:\n%s", strings.Join(tc.expectedStdout, "\n"), stdout)
+			}
+		})
+	}
+}
+
+func TestRunLintFiles(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	validFile := filepath.Join(dir, "cluster.yaml")
+	err := os.WriteFile(validFile, []byte(validCluster), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "machinepools.json")
+	err = os.WriteFile(invalidFile, []byte(invalidMachinePoolList), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := &bytes.Buffer{}
+
+	// act
+	exitCode := run([]string{"lint", validFile, invalidFile}, strings.NewReader(""), stdout, &bytes.Buffer{})
+
+	// assert
+	if exitCode != exitFailure {
+		t.Errorf("expected exit code %d, got %d", exitFailure, exitCode)
+	}
+	if !strings.HasPrefix(stdout.String(), invalidFile+": MachinePool org-test/np1: ") {
+		t.Errorf("expected violations prefixed with file name, got:\n%s", stdout)
+	}
+	if strings.Contains(stdout.String(), validFile) {
+		t.Errorf("expected no violations in %s, got:\n%s", validFile, stdout)
+	}
+}
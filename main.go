@@ -1,3 +1,67 @@
 package main
 
-func main() {}
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	exitOK = iota
+	exitFailure
+	exitUsage
+)
+
+type command struct {
+	usage string
+	run   func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"explain": {
+		usage: "Explain condition reasons registered in the catalog.",
+		run:   runExplain,
+	},
+	"history": {
+		usage: "Show condition transitions recorded in history annotations and ConfigMaps of objects in YAML or JSON files.",
+		run:   runHistory,
+	},
+	"lint": {
+		usage: "Check conditions of objects in YAML or JSON files against Cluster API conventions.",
+		run:   runLint,
+	},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	c, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	return c.run(args[1:], stdin, stdout, stderr)
+}
+
+func printUsage(w io.Writer) {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "Usage: conditions-handler <command> [flags] [args]\n\nCommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
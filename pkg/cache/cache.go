@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	capiexternal "sigs.k8s.io/cluster-api/controllers/external"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Stats contains the number of cache hits and misses.
+type Stats struct {
+	Hits   int
+	Misses int
+}
+
+// StatsFunc is called with cache stats after the cache is not used anymore,
+// e.g. at the end of a composite handler pass.
+type StatsFunc func(ctx context.Context, stats Stats)
+
+// Cache is a request-scoped cache of objects fetched from the k8s API. It is
+// meant to be used during a single reconciliation, so multiple handlers can
+// share the fetched objects without requesting them multiple times. Cached
+// objects are never invalidated, and they are deep copied when returned.
+type Cache struct {
+	mutex   sync.Mutex
+	objects map[string]runtime.Object
+	stats   Stats
+}
+
+func New() *Cache {
+	return &Cache{
+		objects: map[string]runtime.Object{},
+	}
+}
+
+// Stats returns current cache stats.
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.stats
+}
+
+func (c *Cache) get(key string) (runtime.Object, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	object, ok := c.objects[key]
+	if ok {
+		c.stats.Hits++
+		return object.DeepCopyObject(), true
+	}
+
+	c.stats.Misses++
+	return nil, false
+}
+
+func (c *Cache) set(key string, object runtime.Object) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.objects[key] = object.DeepCopyObject()
+}
+
+type contextKey struct{}
+
+// NewContext returns a new context that carries the specified cache.
+func NewContext(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the cache carried by the specified context.
+func FromContext(ctx context.Context) (*Cache, bool) {
+	c, ok := ctx.Value(contextKey{}).(*Cache)
+	return c, ok
+}
+
+// GetExternal works like capiexternal.Get, but the returned object is cached
+// in the cache carried by the context. Without the cache in the context, the
+// object is always fetched from the k8s API.
+func GetExternal(ctx context.Context, c ctrl.Client, ref *corev1.ObjectReference, namespace string) (*unstructured.Unstructured, error) {
+	cache, ok := FromContext(ctx)
+	if !ok || ref == nil {
+		object, err := capiexternal.Get(ctx, c, ref, namespace)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return object, nil
+	}
+
+	key := fmt.Sprintf("external/%s/%s/%s/%s", ref.APIVersion, ref.Kind, namespace, ref.Name)
+	if cached, ok := cache.get(key); ok {
+		return cached.(*unstructured.Unstructured), nil
+	}
+
+	object, err := capiexternal.Get(ctx, c, ref, namespace)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	cache.set(key, object)
+
+	return object, nil
+}
+
+// List works like ctrl.Client List, but the returned list is cached in the
+// cache carried by the context. Without the cache in the context, the list is
+// always fetched from the k8s API.
+func List(ctx context.Context, c ctrl.Client, list ctrl.ObjectList, opts ...ctrl.ListOption) error {
+	cache, ok := FromContext(ctx)
+	if !ok {
+		err := c.List(ctx, list, opts...)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	listOptions := &ctrl.ListOptions{}
+	listOptions.ApplyOptions(opts)
+
+	var labelSelector, fieldSelector string
+	if listOptions.LabelSelector != nil {
+		labelSelector = listOptions.LabelSelector.String()
+	}
+	if listOptions.FieldSelector != nil {
+		fieldSelector = listOptions.FieldSelector.String()
+	}
+
+	key := fmt.Sprintf("list/%T/%s/%s/%s", list, listOptions.Namespace, labelSelector, fieldSelector)
+	if cached, ok := cache.get(key); ok {
+		// Copy cached list into the specified one.
+		reflect.ValueOf(list).Elem().Set(reflect.ValueOf(cached).Elem())
+		return nil
+	}
+
+	err := c.List(ctx, list, opts...)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cache.set(key, list)
+
+	return nil
+}
+
+// Get works like ctrl.Client Get, but the returned object is cached in the
+// cache carried by the context. Without the cache in the context, the object
+// is always fetched from the k8s API.
+func Get(ctx context.Context, c ctrl.Client, objectKey ctrl.ObjectKey, object ctrl.Object) error {
+	cache, ok := FromContext(ctx)
+	if !ok {
+		err := c.Get(ctx, objectKey, object)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	// GVK is included, so that unstructured objects of different kinds are
+	// cached separately.
+	key := fmt.Sprintf("object/%T/%s/%s", object, object.GetObjectKind().GroupVersionKind(), objectKey)
+	if cached, ok := cache.get(key); ok {
+		// Copy cached object into the specified one.
+		reflect.ValueOf(object).Elem().Set(reflect.ValueOf(cached).Elem())
+		return nil
+	}
+
+	err := c.Get(ctx, objectKey, object)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cache.set(key, object)
+
+	return nil
+}
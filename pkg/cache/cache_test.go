@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetExternal(t *testing.T) {
+	testName := "external object is fetched once and returned from the cache afterwards"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		ctx := context.Background()
+		client := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(&capi.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-test", Name: "test1"},
+		}).Build()
+		ref := &corev1.ObjectReference{
+			APIVersion: capi.GroupVersion.String(),
+			Kind:       "Cluster",
+			Name:       "test1",
+		}
+		c := New()
+		ctx = NewContext(ctx, c)
+
+		// act
+		first, err := GetExternal(ctx, client, ref, "org-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		first.SetLabels(map[string]string{"changed": "true"})
+		second, err := GetExternal(ctx, client, ref, "org-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// assert
+		if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+			t.Fatalf("expected 1 hit and 1 miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+		}
+		if second.GetLabels()["changed"] != "" {
+			t.Fatal("expected cached object not to be changed by changing the returned object")
+		}
+	})
+}
+
+func TestList(t *testing.T) {
+	testName := "lists are cached by type, namespace and selectors"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		ctx := context.Background()
+		client := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(
+			&capiexp.MachinePool{ObjectMeta: metav1.ObjectMeta{Namespace: "org-test", Name: "np1", Labels: map[string]string{capi.ClusterLabelName: "test1"}}},
+			&capiexp.MachinePool{ObjectMeta: metav1.ObjectMeta{Namespace: "org-test", Name: "np2"}},
+		).Build()
+		c := New()
+		ctx = NewContext(ctx, c)
+
+		// act
+		all := &capiexp.MachinePoolList{}
+		err := List(ctx, client, all, ctrl.InNamespace("org-test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		labeled := &capiexp.MachinePoolList{}
+		err = List(ctx, client, labeled, ctrl.InNamespace("org-test"), ctrl.MatchingLabels{capi.ClusterLabelName: "test1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cached := &capiexp.MachinePoolList{}
+		err = List(ctx, client, cached, ctrl.InNamespace("org-test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// assert
+		if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 2 {
+			t.Fatalf("expected 1 hit and 2 misses, got %d hits and %d misses", stats.Hits, stats.Misses)
+		}
+		if len(all.Items) != 2 || len(labeled.Items) != 1 || len(cached.Items) != 2 {
+			t.Fatalf("expected 2, 1 and 2 node pools, got %d, %d and %d", len(all.Items), len(labeled.Items), len(cached.Items))
+		}
+	})
+}
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{capi.AddToScheme, capiexp.AddToScheme} {
+		err := addToScheme(scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return scheme
+}
@@ -0,0 +1,408 @@
+package catalog
+
+import (
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/custom"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/degraded"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/frozen"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsupgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/replicasready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/summary"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+var (
+	allKinds = []string{"Cluster", "MachinePool"}
+
+	statusTrue    = []corev1.ConditionStatus{corev1.ConditionTrue}
+	statusFalse   = []corev1.ConditionStatus{corev1.ConditionFalse}
+	statusUnknown = []corev1.ConditionStatus{corev1.ConditionUnknown}
+
+	severityInfo        = []capi.ConditionSeverity{capi.ConditionSeverityInfo}
+	severityWarning     = []capi.ConditionSeverity{capi.ConditionSeverityWarning}
+	severityInfoWarning = []capi.ConditionSeverity{capi.ConditionSeverityInfo, capi.ConditionSeverityWarning}
+	severityAll         = []capi.ConditionSeverity{capi.ConditionSeverityError, capi.ConditionSeverityWarning, capi.ConditionSeverityInfo}
+)
+
+// CustomConditionType returns a condition type set by a custom handler, with
+// reasons that custom handlers set when the reason expression is not
+// specified, and with the specified reasons computed by the reason
+// expression. When no reasons are specified, any reason is valid. For custom
+// handlers with negative polarity, NegativePolarity of the returned condition
+// type must be set as well, so that validation.Validator allows reasons of
+// True conditions.
+func CustomConditionType(conditionType capi.ConditionType, reasons ...Reason) ConditionType {
+	return ConditionType{
+		Type:        conditionType,
+		Kinds:       allKinds,
+		Handlers:    []string{"custom"},
+		Description: "Condition computed by CEL expressions of a custom handler.",
+		Reasons:     append(customReasons(), reasons...),
+		AnyReason:   len(reasons) == 0,
+	}
+}
+
+func customReasons() []Reason {
+	return []Reason{
+		{
+			Name:        custom.ConditionNotMetReason,
+			Statuses:    statusFalse,
+			Severities:  severityAll,
+			Description: "Status expression evaluates to false and the reason expression is not specified or evaluates to an empty string.",
+			Remediation: "Check the condition message and the status expression of the custom handler.",
+		},
+		{
+			Name:        custom.ConditionUnknownReason,
+			Statuses:    statusUnknown,
+			Severities:  severityAll,
+			Description: "Status expression evaluates to \"Unknown\" and the reason expression is not specified or evaluates to an empty string.",
+			Remediation: "Check that objects looked up by the custom handler exist.",
+		},
+		{
+			Name:             custom.EvaluationFailedReason,
+			Statuses:         statusUnknown,
+			Severities:       severityWarning,
+			MessageTemplates: []string{"Condition cannot be evaluated: %s"},
+			Description:      "An expression of the custom handler cannot be evaluated, e.g. because it reads a field that is not set or returns a value of the wrong type.",
+			Remediation:      "Fix the expression from the condition message, e.g. guard optional fields with has().",
+		},
+	}
+}
+
+func builtinConditionTypes() []ConditionType {
+	return []ConditionType{
+		{
+			Type:        capi.ReadyCondition,
+			Kinds:       allKinds,
+			Handlers:    []string{"summary"},
+			Description: "Summary of other conditions. When False or Unknown, it has the reason, severity and message of the most severe summarized condition.",
+			Reasons: []Reason{
+				{
+					Name:             summary.PausedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"Reconciliation is paused, %s is paused with %s"},
+					Description:      "The object or its Cluster is paused, so conditions are not summarized.",
+					Remediation:      "Remove cluster.x-k8s.io/paused annotation or set Cluster.Spec.Paused to false when the pause is not intended.",
+				},
+			},
+			AnyReason: true,
+		},
+		{
+			Type:        capi.InfrastructureReadyCondition,
+			Kinds:       allKinds,
+			Handlers:    []string{"infrastructureReady"},
+			Description: "Mirrors Ready condition of the provider-specific infrastructure object. When that condition is set, its reason is used.",
+			Reasons: []Reason{
+				{
+					Name:             conditions.InfrastructureReferenceNotSetReason,
+					Statuses:         statusFalse,
+					Severities:       severityWarning,
+					MessageTemplates: []string{"%s object '%s/%s' does not have infrastructure reference set"},
+					Description:      "Spec.InfrastructureRef of the object is not set.",
+					Remediation:      "Set Spec.InfrastructureRef to the provider-specific infrastructure object.",
+				},
+				{
+					Name:             conditions.InfrastructureObjectNotFoundReason,
+					Statuses:         statusFalse,
+					Severities:       severityWarning,
+					MessageTemplates: []string{"Corresponding provider-specific infrastructure object '%s/%s' is not found for %s object '%s/%s'"},
+					Description:      "The referenced infrastructure object does not exist, or its kind is not known to the API server.",
+					Remediation:      "Check that the referenced object exists in the referenced namespace and that the provider CRDs are installed.",
+				},
+				{
+					Name:             capi.WaitingForInfrastructureFallbackReason,
+					Statuses:         statusFalse,
+					Severities:       severityWarning,
+					MessageTemplates: []string{"Waiting for infrastructure object '%s/%s' of kind %s to have Ready condition set"},
+					Description:      "The infrastructure object exists, but its Ready condition is not set yet.",
+					Remediation:      "Check that the provider controller is running and reconciles the infrastructure object.",
+				},
+			},
+			AnyReason: true,
+		},
+		{
+			Type:        capi.ControlPlaneReadyCondition,
+			Kinds:       []string{"Cluster"},
+			Handlers:    []string{"controlPlaneReady"},
+			Description: "Mirrors Ready condition of the control plane object. When that condition is set, its reason is used.",
+			Reasons: []Reason{
+				{
+					Name:             conditions.ControlPlaneReferenceNotSetReason,
+					Statuses:         statusFalse,
+					Severities:       severityWarning,
+					MessageTemplates: []string{"Control plane reference is not set for specified %s object '%s/%s'"},
+					Description:      "Cluster.Spec.ControlPlaneRef is not set.",
+					Remediation:      "Set Cluster.Spec.ControlPlaneRef to the control plane object.",
+				},
+				{
+					Name:             conditions.ControlPlaneObjectNotFoundReason,
+					Statuses:         statusFalse,
+					Severities:       severityWarning,
+					MessageTemplates: []string{"Control plane object '%s/%s' of kind %s is not found for specified %s object '%s/%s'"},
+					Description:      "The referenced control plane object does not exist, or its kind is not known to the API server.",
+					Remediation:      "Check that the referenced object exists in the referenced namespace and that the control plane provider CRDs are installed.",
+				},
+				{
+					Name:             capi.WaitingForControlPlaneFallbackReason,
+					Statuses:         statusFalse,
+					Severities:       severityWarning,
+					MessageTemplates: []string{"Waiting for control plane object '%s/%s' of kind %s to have Ready condition set"},
+					Description:      "The control plane object exists, but its Ready condition is not set yet.",
+					Remediation:      "Check that the control plane provider controller is running and reconciles the control plane object.",
+				},
+			},
+			AnyReason: true,
+		},
+		{
+			Type:        conditions.NodePoolsReady,
+			Kinds:       []string{"Cluster"},
+			Handlers:    []string{"nodePoolsReady"},
+			Description: "Aggregates Ready, or another configured condition, of all node pools of the Cluster. When False or Unknown, it has the reason of the most severe node pool condition with a reference to the node pool, e.g. \"WaitingForReplicasReady @ MachinePool/np1\".",
+			Reasons: []Reason{
+				{
+					Name:             conditions.NodePoolsNotFoundReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfoWarning,
+					MessageTemplates: []string{"Node pools are not found for Cluster %s/%s"},
+					Description:      "No node pools of the Cluster are found with the configured discovery strategy.",
+					Remediation:      "Create node pools, or check that they have the cluster name label or Spec.ClusterName set.",
+				},
+			},
+			AnyReason: true,
+		},
+		{
+			Type:             nodepoolsupgrading.NodePoolsUpgrading,
+			Kinds:            []string{"Cluster"},
+			Handlers:         []string{"nodePoolsUpgrading"},
+			NegativePolarity: true,
+			Description:      "True when at least one node pool of the Cluster is being upgraded. The message tells the upgrade progress.",
+			Reasons: []Reason{
+				{
+					Name:             conditions.NodePoolsNotFoundReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"Node pools are not found for Cluster %s/%s"},
+					Description:      "No node pools of the Cluster are found.",
+				},
+				{
+					Name:             nodepoolsupgrading.NodePoolsUpgradingUnknownReason,
+					Statuses:         statusUnknown,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"Upgrading condition is not set for node pools of Cluster %s/%s"},
+					Description:      "None of the node pools has Upgrading condition set.",
+					Remediation:      "Check that the upgrading handler reconciles node pools of the Cluster.",
+				},
+				{
+					Name:             conditions.UpgradeCompletedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"None of the %d node pools is being upgraded"},
+					Description:      "None of the node pools is being upgraded, and at least one of them has completed an upgrade.",
+				},
+				{
+					Name:             conditions.UpgradeNotStartedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"None of the %d node pools is being upgraded"},
+					Description:      "None of the node pools has been upgraded yet.",
+				},
+			},
+		},
+		{
+			Type:             conditions.Creating,
+			Kinds:            allKinds,
+			Handlers:         []string{"creating"},
+			NegativePolarity: true,
+			Description:      "True while the object is being created.",
+			Reasons: []Reason{
+				{
+					Name:             conditions.CreationCompletedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"Creation has been completed in %s"},
+					Description:      "The object has been created and its release version has been deployed.",
+				},
+				{
+					Name:             conditions.ExistingObjectReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"Object was already created"},
+					Description:      "The object was created before Creating condition was introduced, so the creation duration is not known.",
+				},
+			},
+		},
+		{
+			Type:             conditions.Upgrading,
+			Kinds:            allKinds,
+			Handlers:         []string{"upgrading"},
+			NegativePolarity: true,
+			Description:      "True while the object is being upgraded to a new release version. For Clusters, it can stay True until all node pools are upgraded.",
+			Reasons: []Reason{
+				{
+					Name:             conditions.UpgradeCompletedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"Upgrade has been completed in %s", "Upgrade has been completed, but upgrade duration cannot be determined"},
+					Description:      "The desired release version has been deployed.",
+				},
+				{
+					Name:             conditions.UpgradeNotStartedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"Upgrade has not been started"},
+					Description:      "The object has not been upgraded since it was created.",
+				},
+			},
+		},
+		{
+			Type:        capiexp.ReplicasReadyCondition,
+			Kinds:       []string{"MachinePool"},
+			Handlers:    []string{"replicasReady"},
+			Description: "True when MachinePool replicas are ready and have node references set, according to the configured policy.",
+			Reasons: []Reason{
+				{
+					Name:             capiexp.WaitingForReplicasReadyReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfoWarning,
+					MessageTemplates: []string{"%d/%d replicas are ready, %d/%d node references set", "%d/%d replicas are ready, provider IDs are not set"},
+					Description:      "Not all replicas are ready or have node references set. Severity is Info while the MachinePool is scaling within the grace period, or while provider IDs of replicas are not set yet.",
+					Remediation:      "Check nodes of the MachinePool and the provider-specific machine pool object.",
+				},
+				{
+					Name:             replicasready.MinReadyReplicasNotReachedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfoWarning,
+					MessageTemplates: []string{"%s, at least %d required"},
+					Description:      "Fewer replicas are ready than required by the configured minimum percentage or number of ready replicas.",
+					Remediation:      "Check nodes of the MachinePool, or lower the required minimum.",
+				},
+				{
+					Name:             replicasready.AutoscalerMinSizeNotReachedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfoWarning,
+					MessageTemplates: []string{"%s, cluster-autoscaler min size %d is not reached"},
+					Description:      "Fewer replicas are ready than the cluster-autoscaler node group min size.",
+					Remediation:      "Check nodes of the MachinePool and the cluster-autoscaler node group min size annotation.",
+				},
+				{
+					Name:             replicasready.NoReplicasReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"MachinePool does not have any replicas"},
+					Description:      "The MachinePool does not have any replicas and the NotReady zero replicas policy is configured.",
+				},
+			},
+		},
+		{
+			Type:             scaling.Scaling,
+			Kinds:            []string{"MachinePool"},
+			Handlers:         []string{"scaling"},
+			NegativePolarity: true,
+			Description:      "True while the number of MachinePool replicas is being changed, e.g. by cluster-autoscaler.",
+			Reasons: []Reason{
+				{
+					Name:             scaling.ScalingUpReason,
+					Statuses:         statusTrue,
+					MessageTemplates: []string{"Scaling from %d to %d replicas"},
+					Description:      "The desired number of replicas is greater than the current number of replicas.",
+				},
+				{
+					Name:             scaling.ScalingDownReason,
+					Statuses:         statusTrue,
+					MessageTemplates: []string{"Scaling from %d to %d replicas"},
+					Description:      "The desired number of replicas is lower than the current number of replicas.",
+				},
+				{
+					Name:             scaling.DesiredReplicasReadyReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"Desired number of replicas %d is reached"},
+					Description:      "The MachinePool has the desired number of replicas. Readiness of the replicas is reported by ReplicasReady condition.",
+				},
+			},
+		},
+		{
+			Type:             paused.Paused,
+			Kinds:            allKinds,
+			Handlers:         []string{"paused"},
+			NegativePolarity: true,
+			Description:      "True when the object or its Cluster is paused. Other handlers leave conditions of paused objects unchanged. It is removed when the object is not paused anymore.",
+			Reasons: []Reason{
+				{
+					Name:             paused.PausedBySpecReason,
+					Statuses:         statusTrue,
+					MessageTemplates: []string{"%s is paused with %s", "%s is paused with %s by %s since %s"},
+					Description:      "The Cluster is paused with Cluster.Spec.Paused.",
+					Remediation:      "Set Cluster.Spec.Paused to false when the pause is not intended.",
+				},
+				{
+					Name:             paused.PausedByAnnotationReason,
+					Statuses:         statusTrue,
+					MessageTemplates: []string{"%s is paused with %s", "%s is paused with %s by %s since %s"},
+					Description:      "The object or its Cluster is paused with cluster.x-k8s.io/paused annotation.",
+					Remediation:      "Remove cluster.x-k8s.io/paused annotation when the pause is not intended.",
+				},
+			},
+		},
+		{
+			Type:             frozen.Frozen,
+			Kinds:            allKinds,
+			Handlers:         []string{"frozen"},
+			NegativePolarity: true,
+			Description:      "True when conditions of the object are frozen, so handlers leave them unchanged. It is removed when no conditions are frozen.",
+			Reasons: []Reason{
+				{
+					Name:             frozen.SkipAnnotationReason,
+					Statuses:         statusTrue,
+					MessageTemplates: []string{"Conditions frozen with %s annotation: %s"},
+					Description:      "Conditions are listed in the " + internal.SkipAnnotation + " annotation of the object.",
+					Remediation:      "Remove the " + internal.SkipAnnotation + " annotation when the conditions should be updated again.",
+				},
+			},
+		},
+		{
+			Type:             degraded.Degraded,
+			Kinds:            allKinds,
+			Handlers:         []string{"degraded"},
+			NegativePolarity: true,
+			Description:      "True when some input conditions are False with Warning or Error severity, or when a lifecycle condition is True for longer than expected. The message lists the offending conditions.",
+			Reasons: []Reason{
+				{
+					Name:             degraded.DegradedWithErrorReason,
+					Statuses:         statusTrue,
+					MessageTemplates: []string{"%s (%s): %s"},
+					Description:      "At least one of the offending conditions has Error severity.",
+					Remediation:      "Check the offending conditions listed in the message.",
+				},
+				{
+					Name:             degraded.DegradedWithWarningReason,
+					Statuses:         statusTrue,
+					MessageTemplates: []string{"%s (%s): %s", "%s (Warning): True for longer than expected %s"},
+					Description:      "All offending conditions have Warning severity, or a lifecycle condition is True for longer than expected.",
+					Remediation:      "Check the offending conditions listed in the message.",
+				},
+				{
+					Name:             degraded.NotDegradedReason,
+					Statuses:         statusFalse,
+					Severities:       severityInfo,
+					MessageTemplates: []string{"None of the %d conditions is degraded"},
+					Description:      "None of the input conditions is offending.",
+				},
+			},
+		},
+		{
+			Handlers:    []string{"custom"},
+			Kinds:       allKinds,
+			Description: "Condition of the type configured in the custom handler, computed by CEL expressions.",
+			Reasons:     customReasons(),
+			AnyReason:   true,
+		},
+	}
+}
@@ -0,0 +1,214 @@
+// Package catalog contains a registry of condition types that handlers set,
+// with their reasons, severities and messages, and with descriptions and
+// remediation hints, so that conditions can be explained at runtime.
+package catalog
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+// Reason describes a reason with which a condition is set.
+type Reason struct {
+	// Name is the CamelCase reason.
+	Name string
+	// Statuses are condition statuses with which the reason is set.
+	Statuses []corev1.ConditionStatus
+	// Severities are severities with which the reason is set. Empty for
+	// reasons of True conditions.
+	Severities []capi.ConditionSeverity
+	// MessageTemplates are fmt templates of condition messages that are set
+	// with the reason.
+	MessageTemplates []string
+	// Description explains when the reason is set.
+	Description string
+	// Remediation tells what can be done when the reason is set, empty if
+	// nothing has to be done.
+	Remediation string
+}
+
+// Allows checks if the reason is registered with the specified status and
+// severity.
+func (r Reason) Allows(status corev1.ConditionStatus, severity capi.ConditionSeverity) bool {
+	statusAllowed := false
+	for _, s := range r.Statuses {
+		if s == status {
+			statusAllowed = true
+			break
+		}
+	}
+	if !statusAllowed {
+		return false
+	}
+	if len(r.Severities) == 0 {
+		return severity == capi.ConditionSeverityNone
+	}
+	for _, s := range r.Severities {
+		if s == severity {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConditionType describes a condition type that handlers set.
+type ConditionType struct {
+	// Type is the condition type. It is empty for handlers whose condition
+	// type is configured, like custom handlers. Their reasons can be
+	// explained, but conditions are checked only when the configured type is
+	// registered, e.g. with CustomConditionType.
+	Type capi.ConditionType
+	// Kinds are object kinds on which the condition is set.
+	Kinds []string
+	// Handlers are pipeline handler types that set the condition. Empty
+	// means that all handlers set it.
+	Handlers []string
+	// NegativePolarity is set for conditions that are True when something is
+	// in progress or wrong.
+	NegativePolarity bool
+	// Description explains what the condition means.
+	Description string
+	// Reasons are reasons with which handlers set the condition.
+	Reasons []Reason
+	// AnyReason is set when reasons are not known in advance, e.g. when they
+	// are mirrored from provider objects, aggregated from node pools or
+	// computed by expressions. Unregistered reasons are then valid, while
+	// registered ones are still checked.
+	AnyReason bool
+}
+
+// Reason returns the registered reason with the specified name. Source
+// references of aggregated reasons, like "ReplicasNotReady @
+// MachinePool/np1", are ignored.
+func (t ConditionType) Reason(name string) (Reason, bool) {
+	name = TrimSourceRef(name)
+	for _, r := range t.Reasons {
+		if r.Name == name {
+			return r, true
+		}
+	}
+
+	return Reason{}, false
+}
+
+// Explanation is a registered reason of a condition type.
+type Explanation struct {
+	ConditionType ConditionType
+	Reason        Reason
+}
+
+// Catalog contains registered condition types.
+type Catalog struct {
+	conditionTypes []ConditionType
+	index          map[capi.ConditionType]int
+}
+
+// NewCatalog creates an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{
+		index: map[capi.ConditionType]int{},
+	}
+}
+
+// NewDefaultCatalog creates a catalog with all condition types set by
+// handlers implemented in this library.
+func NewDefaultCatalog() *Catalog {
+	c := NewCatalog()
+	for _, conditionType := range builtinConditionTypes() {
+		c.add(conditionType)
+	}
+
+	return c
+}
+
+// Register adds a condition type to the catalog, e.g. a condition type set
+// by a custom handler.
+func (c *Catalog) Register(conditionType ConditionType) error {
+	if conditionType.Type == "" && len(conditionType.Handlers) == 0 {
+		return microerror.Maskf(errors.InvalidConfigError, "%T.Type or %T.Handlers must not be empty", conditionType, conditionType)
+	}
+	if _, ok := c.index[conditionType.Type]; ok && conditionType.Type != "" {
+		return microerror.Maskf(errors.InvalidConfigError, "condition type %q is already registered", conditionType.Type)
+	}
+
+	names := map[string]bool{}
+	for _, r := range conditionType.Reasons {
+		if r.Name == "" {
+			return microerror.Maskf(errors.InvalidConfigError, "%T.Name of condition type %q must not be empty", r, conditionType.Type)
+		}
+		if names[r.Name] {
+			return microerror.Maskf(errors.InvalidConfigError, "reason %q of condition type %q is already registered", r.Name, conditionType.Type)
+		}
+		if len(r.Statuses) == 0 {
+			return microerror.Maskf(errors.InvalidConfigError, "%T.Statuses of reason %q must not be empty", r, r.Name)
+		}
+		names[r.Name] = true
+	}
+
+	c.add(conditionType)
+	return nil
+}
+
+// ConditionTypes returns sorted registered condition types.
+func (c *Catalog) ConditionTypes() []capi.ConditionType {
+	var conditionTypes []capi.ConditionType
+	for conditionType := range c.index {
+		conditionTypes = append(conditionTypes, conditionType)
+	}
+	sort.Slice(conditionTypes, func(i, j int) bool {
+		return conditionTypes[i] < conditionTypes[j]
+	})
+
+	return conditionTypes
+}
+
+// ConditionType returns the registered condition type.
+func (c *Catalog) ConditionType(conditionType capi.ConditionType) (ConditionType, bool) {
+	i, ok := c.index[conditionType]
+	if !ok {
+		return ConditionType{}, false
+	}
+
+	return c.conditionTypes[i], true
+}
+
+// Explain returns all registered condition types that are set with the
+// specified reason, in registration order.
+func (c *Catalog) Explain(reason string) []Explanation {
+	var explanations []Explanation
+	for _, conditionType := range c.conditionTypes {
+		if r, ok := conditionType.Reason(reason); ok {
+			explanations = append(explanations, Explanation{
+				ConditionType: conditionType,
+				Reason:        r,
+			})
+		}
+	}
+
+	return explanations
+}
+
+func (c *Catalog) add(conditionType ConditionType) {
+	c.conditionTypes = append(c.conditionTypes, conditionType)
+	if conditionType.Type != "" {
+		c.index[conditionType.Type] = len(c.conditionTypes) - 1
+	}
+}
+
+// TrimSourceRef removes the source reference that Cluster API appends to
+// reasons of aggregated conditions, e.g. "ReplicasNotReady @
+// MachinePool/np1" becomes "ReplicasNotReady".
+func TrimSourceRef(reason string) string {
+	if i := strings.Index(reason, " @ "); i >= 0 {
+		return reason[:i]
+	}
+
+	return reason
+}
@@ -0,0 +1,368 @@
+package catalog
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/custom"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/degraded"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/frozen"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsupgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/replicasready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/summary"
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/pipeline"
+)
+
+func TestRegister(t *testing.T) {
+	testCases := []struct {
+		name          string
+		conditionType ConditionType
+		errorMatcher  func(error) bool
+	}{
+		{
+			name: "case 0: valid condition type",
+			conditionType: ConditionType{
+				Type:    "KubeconfigSecretPresent",
+				Reasons: []Reason{{Name: "SecretNotFound", Statuses: statusFalse, Severities: severityWarning}},
+			},
+		},
+		{
+			name: "case 1: configured condition type of a handler",
+			conditionType: ConditionType{
+				Handlers: []string{"custom"},
+				Reasons:  []Reason{{Name: "SecretNotFound", Statuses: statusFalse}},
+			},
+		},
+		{
+			name:          "case 2: missing condition type and handlers",
+			conditionType: ConditionType{},
+			errorMatcher:  errors.IsInvalidConfig,
+		},
+		{
+			name:          "case 3: already registered condition type",
+			conditionType: ConditionType{Type: capi.ReadyCondition},
+			errorMatcher:  errors.IsInvalidConfig,
+		},
+		{
+			name: "case 4: reason without name",
+			conditionType: ConditionType{
+				Type:    "KubeconfigSecretPresent",
+				Reasons: []Reason{{Statuses: statusFalse}},
+			},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+		{
+			name: "case 5: duplicate reason",
+			conditionType: ConditionType{
+				Type: "KubeconfigSecretPresent",
+				Reasons: []Reason{
+					{Name: "SecretNotFound", Statuses: statusFalse},
+					{Name: "SecretNotFound", Statuses: statusUnknown},
+				},
+			},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+		{
+			name: "case 6: reason without statuses",
+			conditionType: ConditionType{
+				Type:    "KubeconfigSecretPresent",
+				Reasons: []Reason{{Name: "SecretNotFound"}},
+			},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			c := NewCatalog()
+			err := c.Register(ConditionType{Type: capi.ReadyCondition})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			err = c.Register(tc.conditionType)
+
+			// assert
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	camelCase := regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	handlerTypes := map[string]bool{}
+	for _, handlerType := range pipeline.NewDefaultRegistry().Types() {
+		handlerTypes[handlerType] = true
+	}
+
+	c := NewCatalog()
+	for _, conditionType := range builtinConditionTypes() {
+		err := c.Register(conditionType)
+		if err != nil {
+			t.Fatalf("builtin condition type %q: %#v", conditionType.Type, err)
+		}
+
+		if conditionType.Description == "" {
+			t.Errorf("condition type %q does not have description", conditionType.Type)
+		}
+		for _, handlerType := range conditionType.Handlers {
+			if !handlerTypes[handlerType] {
+				t.Errorf("condition type %q: handler type %q is not registered in pipeline", conditionType.Type, handlerType)
+			}
+		}
+		for _, reason := range conditionType.Reasons {
+			if !camelCase.MatchString(reason.Name) {
+				t.Errorf("condition type %q: reason %q is not CamelCase", conditionType.Type, reason.Name)
+			}
+			if reason.Description == "" {
+				t.Errorf("condition type %q: reason %q does not have description", conditionType.Type, reason.Name)
+			}
+			for _, status := range reason.Statuses {
+				if (status == corev1.ConditionTrue) != (len(reason.Severities) == 0) {
+					t.Errorf("condition type %q: reason %q must have severities only with status False or Unknown", conditionType.Type, reason.Name)
+				}
+			}
+		}
+	}
+}
+
+// TestReasonsAreRegistered checks that reasons set by handlers are
+// registered in the default catalog, so that a new reason cannot be added
+// without its description.
+func TestReasonsAreRegistered(t *testing.T) {
+	reasons := map[capi.ConditionType][]string{
+		capi.ReadyCondition: {
+			summary.PausedReason,
+		},
+		capi.InfrastructureReadyCondition: {
+			conditions.InfrastructureReferenceNotSetReason,
+			conditions.InfrastructureObjectNotFoundReason,
+			capi.WaitingForInfrastructureFallbackReason,
+		},
+		capi.ControlPlaneReadyCondition: {
+			conditions.ControlPlaneReferenceNotSetReason,
+			conditions.ControlPlaneObjectNotFoundReason,
+			capi.WaitingForControlPlaneFallbackReason,
+		},
+		conditions.NodePoolsReady: {
+			conditions.NodePoolsNotFoundReason,
+		},
+		nodepoolsupgrading.NodePoolsUpgrading: {
+			conditions.NodePoolsNotFoundReason,
+			nodepoolsupgrading.NodePoolsUpgradingUnknownReason,
+			conditions.UpgradeCompletedReason,
+			conditions.UpgradeNotStartedReason,
+		},
+		conditions.Creating: {
+			conditions.CreationCompletedReason,
+			conditions.ExistingObjectReason,
+		},
+		conditions.Upgrading: {
+			conditions.UpgradeCompletedReason,
+			conditions.UpgradeNotStartedReason,
+		},
+		capiexp.ReplicasReadyCondition: {
+			capiexp.WaitingForReplicasReadyReason,
+			replicasready.MinReadyReplicasNotReachedReason,
+			replicasready.AutoscalerMinSizeNotReachedReason,
+			replicasready.NoReplicasReason,
+		},
+		scaling.Scaling: {
+			scaling.ScalingUpReason,
+			scaling.ScalingDownReason,
+			scaling.DesiredReplicasReadyReason,
+		},
+		paused.Paused: {
+			paused.PausedBySpecReason,
+			paused.PausedByAnnotationReason,
+		},
+		frozen.Frozen: {
+			frozen.SkipAnnotationReason,
+		},
+		degraded.Degraded: {
+			degraded.DegradedWithErrorReason,
+			degraded.DegradedWithWarningReason,
+			degraded.NotDegradedReason,
+		},
+		"KubeconfigSecretPresent": {
+			custom.ConditionNotMetReason,
+			custom.ConditionUnknownReason,
+			custom.EvaluationFailedReason,
+		},
+	}
+
+	c := NewDefaultCatalog()
+	err := c.Register(CustomConditionType("KubeconfigSecretPresent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for conditionType, names := range reasons {
+		registered, ok := c.ConditionType(conditionType)
+		if !ok {
+			t.Errorf("condition type %q is not registered", conditionType)
+			continue
+		}
+		for _, name := range names {
+			if _, ok := registered.Reason(name); !ok {
+				t.Errorf("reason %q of condition type %q is not registered", name, conditionType)
+			}
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		reason                 string
+		expectedConditionTypes []capi.ConditionType
+	}{
+		{
+			name:                   "case 0: reason of one condition type",
+			reason:                 scaling.ScalingUpReason,
+			expectedConditionTypes: []capi.ConditionType{scaling.Scaling},
+		},
+		{
+			name:                   "case 1: reason of multiple condition types",
+			reason:                 conditions.UpgradeCompletedReason,
+			expectedConditionTypes: []capi.ConditionType{nodepoolsupgrading.NodePoolsUpgrading, conditions.Upgrading},
+		},
+		{
+			name:                   "case 2: aggregated reason",
+			reason:                 "WaitingForReplicasReady @ MachinePool/np1",
+			expectedConditionTypes: []capi.ConditionType{capiexp.ReplicasReadyCondition},
+		},
+		{
+			name:                   "case 3: reason of configured condition type",
+			reason:                 custom.EvaluationFailedReason,
+			expectedConditionTypes: []capi.ConditionType{""},
+		},
+		{
+			name:   "case 4: unknown reason",
+			reason: "Unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			c := NewDefaultCatalog()
+
+			// act
+			explanations := c.Explain(tc.reason)
+
+			// assert
+			var conditionTypes []capi.ConditionType
+			for _, explanation := range explanations {
+				conditionTypes = append(conditionTypes, explanation.ConditionType.Type)
+				if explanation.Reason.Name != TrimSourceRef(tc.reason) {
+					t.Errorf("expected reason %q, got %q", TrimSourceRef(tc.reason), explanation.Reason.Name)
+				}
+			}
+			if !reflect.DeepEqual(conditionTypes, tc.expectedConditionTypes) {
+				t.Errorf("expected condition types %v, got %v", tc.expectedConditionTypes, conditionTypes)
+			}
+		})
+	}
+}
+
+func TestReasonAllows(t *testing.T) {
+	testCases := []struct {
+		name     string
+		reason   Reason
+		status   corev1.ConditionStatus
+		severity capi.ConditionSeverity
+		expected bool
+	}{
+		{
+			name:     "case 0: registered status and severity",
+			reason:   Reason{Statuses: statusFalse, Severities: severityInfoWarning},
+			status:   corev1.ConditionFalse,
+			severity: capi.ConditionSeverityWarning,
+			expected: true,
+		},
+		{
+			name:     "case 1: unregistered severity",
+			reason:   Reason{Statuses: statusFalse, Severities: severityInfoWarning},
+			status:   corev1.ConditionFalse,
+			severity: capi.ConditionSeverityError,
+		},
+		{
+			name:     "case 2: unregistered status",
+			reason:   Reason{Statuses: statusFalse, Severities: severityInfo},
+			status:   corev1.ConditionUnknown,
+			severity: capi.ConditionSeverityInfo,
+		},
+		{
+			name:     "case 3: True condition without severity",
+			reason:   Reason{Statuses: statusTrue},
+			status:   corev1.ConditionTrue,
+			expected: true,
+		},
+		{
+			name:     "case 4: True condition with severity",
+			reason:   Reason{Statuses: statusTrue},
+			status:   corev1.ConditionTrue,
+			severity: capi.ConditionSeverityInfo,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// act
+			allowed := tc.reason.Allows(tc.status, tc.severity)
+
+			// assert
+			if allowed != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, allowed)
+			}
+		})
+	}
+}
+
+func TestCustomConditionType(t *testing.T) {
+	const conditionType capi.ConditionType = "KubeconfigSecretPresent"
+
+	// act
+	anyReason := CustomConditionType(conditionType)
+	registeredReasons := CustomConditionType(conditionType, Reason{Name: "SecretNotFound", Statuses: statusFalse, Severities: severityWarning})
+
+	// assert
+	if !anyReason.AnyReason {
+		t.Errorf("expected any reason to be valid for custom condition type without reasons")
+	}
+	if registeredReasons.AnyReason {
+		t.Errorf("expected only registered reasons to be valid for custom condition type with reasons")
+	}
+	for _, name := range []string{custom.ConditionNotMetReason, "SecretNotFound"} {
+		if _, ok := registeredReasons.Reason(name); !ok {
+			t.Errorf("expected reason %q to be registered", name)
+		}
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"github.com/giantswarm/micrologger"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/cache"
 	"github.com/giantswarm/conditions-handler/pkg/errors"
 	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
 )
 
 type HandlerConfig struct {
@@ -17,13 +19,23 @@ type HandlerConfig struct {
 
 	Name     string
 	Handlers []handler.Interface
+
+	// DisableCache disables request-scoped cache of objects fetched by the
+	// handlers. By default objects like provider-specific infrastructure
+	// objects and node pools are fetched only once in a single pass.
+	DisableCache bool
+	// CacheStatsFunc is called with cache hits and misses at the end of
+	// every pass.
+	CacheStatsFunc cache.StatsFunc
 }
 
 type Handler struct {
-	ctrlClient ctrl.Client
-	logger     micrologger.Logger
-	name       string
-	handlers   []handler.Interface
+	ctrlClient     ctrl.Client
+	logger         micrologger.Logger
+	name           string
+	handlers       []handler.Interface
+	disableCache   bool
+	cacheStatsFunc cache.StatsFunc
 }
 
 func NewHandler(config HandlerConfig) (*Handler, error) {
@@ -41,16 +53,28 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 	}
 
 	h := &Handler{
-		ctrlClient: config.CtrlClient,
-		logger:     config.Logger,
-		name:       config.Name,
-		handlers:   config.Handlers,
+		ctrlClient:     config.CtrlClient,
+		logger:         config.Logger,
+		name:           config.Name,
+		handlers:       config.Handlers,
+		disableCache:   config.DisableCache,
+		cacheStatsFunc: config.CacheStatsFunc,
 	}
 
 	return h, nil
 }
 
 func (h *Handler) EnsureCreated(ctx context.Context, object interface{}) error {
+	ctx, done := h.withCache(ctx)
+	defer done()
+
+	// Condition transitions of handlers that do not save the status are
+	// recorded in history by the next handler that saves it, and are dropped
+	// when saving fails with a conflict. Unless they are recorded by
+	// reconciler.Reconciler after its status update, transitions left pending
+	// by the last handlers are recorded at the end of the pass.
+	ctx, pending, isPendingOwner := internal.NewPendingHistoryContext(ctx)
+
 	var err error
 	for _, handler := range h.handlers {
 		err = handler.EnsureCreated(ctx, object)
@@ -59,10 +83,20 @@ func (h *Handler) EnsureCreated(ctx context.Context, object interface{}) error {
 		}
 	}
 
+	if isPendingOwner {
+		err = pending.Record(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
 	return nil
 }
 
 func (h *Handler) EnsureDeleted(ctx context.Context, object interface{}) error {
+	ctx, done := h.withCache(ctx)
+	defer done()
+
 	var err error
 	for _, handler := range h.handlers {
 		err = handler.EnsureDeleted(ctx, object)
@@ -77,3 +111,33 @@ func (h *Handler) EnsureDeleted(ctx context.Context, object interface{}) error {
 func (h *Handler) Name() string {
 	return h.name
 }
+
+// Handlers returns handlers that this composite handler consists of.
+func (h *Handler) Handlers() []handler.Interface {
+	return h.handlers
+}
+
+// withCache returns a context carrying a new request-scoped cache, and a
+// func that reports the cache stats when the pass is done. When the context
+// already carries a cache, e.g. in nested composite handlers, it is reused.
+func (h *Handler) withCache(ctx context.Context) (context.Context, func()) {
+	if h.disableCache {
+		return ctx, func() {}
+	}
+	if _, ok := cache.FromContext(ctx); ok {
+		return ctx, func() {}
+	}
+
+	c := cache.New()
+	ctx = cache.NewContext(ctx, c)
+
+	done := func() {
+		stats := c.Stats()
+		h.logger.Debugf(ctx, "composite handler %s cache stats: %d hits, %d misses", h.name, stats.Hits, stats.Misses)
+		if h.cacheStatsFunc != nil {
+			h.cacheStatsFunc(ctx, stats)
+		}
+	}
+
+	return ctx, done
+}
@@ -2,15 +2,20 @@ package controlplaneready
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
 	"github.com/giantswarm/conditions-handler/pkg/internal"
 	"github.com/giantswarm/conditions-handler/pkg/key"
+	"github.com/giantswarm/conditions-handler/pkg/watch"
 )
 
 type HandlerConfig struct {
@@ -19,20 +24,32 @@ type HandlerConfig struct {
 
 	Name         string
 	UpdateStatus bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
+
+	// ControlPlaneGVKs are kinds of control plane objects, e.g.
+	// KubeadmControlPlane. They are used only for setting up watches, see
+	// Watches.
+	ControlPlaneGVKs []schema.GroupVersionKind
 }
 
 type Handler struct {
-	ctrlClient      ctrl.Client
-	internalHandler *internal.Handler
-	logger          micrologger.Logger
-	name            string
+	ctrlClient       ctrl.Client
+	internalHandler  *internal.Handler
+	logger           micrologger.Logger
+	name             string
+	controlPlaneGVKs []schema.GroupVersionKind
 }
 
 func NewHandler(config HandlerConfig) (*Handler, error) {
 	h := &Handler{
-		ctrlClient: config.CtrlClient,
-		logger:     config.Logger,
-		name:       config.Name,
+		ctrlClient:       config.CtrlClient,
+		logger:           config.Logger,
+		name:             config.Name,
+		controlPlaneGVKs: config.ControlPlaneGVKs,
 	}
 
 	internalHandlerConfig := internal.HandlerConfig{
@@ -40,6 +57,9 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 		Logger:            config.Logger,
 		UpdateStatus:      config.UpdateStatus,
 		ConditionType:     capi.ControlPlaneReadyCondition,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
 		EnsureCreatedFunc: h.ensureCreated,
 	}
 
@@ -69,6 +89,25 @@ func (h *Handler) Name() string {
 	return h.name
 }
 
+// Watches returns control plane objects as watch sources for Clusters, since
+// ControlPlaneReady is mirrored from their Ready condition. Objects are
+// mapped to Clusters by owner references.
+func (h *Handler) Watches(reconciled schema.GroupVersionKind) []watch.Source {
+	if reconciled.GroupKind() != watch.ClusterGVK.GroupKind() {
+		return nil
+	}
+
+	var sources []watch.Source
+	for _, gvk := range h.controlPlaneGVKs {
+		sources = append(sources, watch.Source{
+			GVK:     gvk,
+			MapFunc: watch.ToOwner(reconciled),
+		})
+	}
+
+	return sources
+}
+
 func (h *Handler) ensureCreated(ctx context.Context, object conditions.Object) error {
 	cluster, err := key.ToClusterPointer(object)
 	if err != nil {
@@ -7,9 +7,9 @@ import (
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
-	capiexternal "sigs.k8s.io/cluster-api/controllers/external"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
 
+	"github.com/giantswarm/conditions-handler/pkg/cache"
 	"github.com/giantswarm/conditions-handler/pkg/errors"
 )
 
@@ -22,6 +22,9 @@ import (
 // If specified control plane object's Ready condition is not set, object
 // ControlPlaneReady will be set with condition False and reason
 // WaitingForControlPlane.
+//
+// Other errors returned while getting the object, like timeouts, are
+// returned, so that the object is reconciled again.
 func (h *Handler) update(ctx context.Context, cluster *capi.Cluster) error {
 	gvk := cluster.GetObjectKind().GroupVersionKind()
 	gvkString := fmt.Sprintf("%s (%s)", gvk.Kind, gvk.GroupVersion().String())
@@ -44,7 +47,7 @@ func (h *Handler) update(ctx context.Context, cluster *capi.Cluster) error {
 	}
 
 	controlPlaneObject, err := h.getControlPlaneObject(ctx, cluster)
-	if errors.IsFailedToRetrieveExternalObject(err) {
+	if errors.IsExternalObjectNotFound(err) {
 		warningMessage :=
 			"Control plane object '%s/%s' of kind %s is not found for specified %s object '%s/%s'"
 
@@ -88,7 +91,7 @@ func (h *Handler) getControlPlaneObject(ctx context.Context, cluster *capi.Clust
 		return nil, nil
 	}
 
-	controlPlaneObject, err := capiexternal.Get(ctx, h.ctrlClient, cluster.Spec.ControlPlaneRef, cluster.Namespace)
+	controlPlaneObject, err := cache.GetExternal(ctx, h.ctrlClient, cluster.Spec.ControlPlaneRef, cluster.Namespace)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
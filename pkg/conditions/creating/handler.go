@@ -2,12 +2,15 @@ package creating
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
 	"github.com/giantswarm/conditions-handler/pkg/internal"
 	"github.com/giantswarm/conditions-handler/pkg/key"
 )
@@ -18,6 +21,11 @@ type HandlerConfig struct {
 
 	Name         string
 	UpdateStatus bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
 }
 
 type Handler struct {
@@ -39,6 +47,9 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 		Logger:            config.Logger,
 		UpdateStatus:      config.UpdateStatus,
 		ConditionType:     conditions.Creating,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
 		EnsureCreatedFunc: h.ensureCreated,
 	}
 
@@ -69,6 +80,10 @@ func (h *Handler) Name() string {
 }
 
 func (h *Handler) ensureCreated(_ context.Context, object conditions.Object) error {
-	update(object)
+	err := update(object, h.internalHandler.Now())
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
 	return nil
 }
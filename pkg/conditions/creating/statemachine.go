@@ -0,0 +1,125 @@
+package creating
+
+import (
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+	"github.com/giantswarm/conditions-handler/pkg/key"
+	"github.com/giantswarm/conditions-handler/pkg/statemachine"
+)
+
+const (
+	// LastDeployedReleaseVersionSet is true when the object has last deployed
+	// release version annotation.
+	LastDeployedReleaseVersionSet statemachine.Fact = "LastDeployedReleaseVersionSet"
+	// FirstNodePoolUpgradeInProgress is true when the Cluster is being
+	// upgraded to its first release with node pools.
+	FirstNodePoolUpgradeInProgress statemachine.Fact = "FirstNodePoolUpgradeInProgress"
+	// DesiredReleaseVersionDeployed is true when the last deployed release
+	// version is equal to the desired release version.
+	DesiredReleaseVersionDeployed statemachine.Fact = "DesiredReleaseVersionDeployed"
+
+	markCreatingTrue                       statemachine.Action = "MarkCreatingTrue"
+	markCreatingFalseForExistingObject     statemachine.Action = "MarkCreatingFalseForExistingObject"
+	markCreatingFalseWithCreationCompleted statemachine.Action = "MarkCreatingFalseWithCreationCompleted"
+)
+
+var actions = map[statemachine.Action]func(object conditions.Object, now time.Time){
+	markCreatingTrue: markCreatingTrueAt,
+	markCreatingFalseForExistingObject: func(object conditions.Object, _ time.Time) {
+		MarkCreatingFalseForExistingObject(object)
+	},
+	markCreatingFalseWithCreationCompleted: markCreatingFalseWithCreationCompletedAt,
+}
+
+// StateMachine returns the state-transition table of Creating condition.
+func StateMachine() statemachine.Table {
+	return statemachine.Table{
+		ConditionType: conditions.Creating,
+		Facts: []statemachine.Fact{
+			LastDeployedReleaseVersionSet,
+			FirstNodePoolUpgradeInProgress,
+			DesiredReleaseVersionDeployed,
+		},
+		Transitions: []statemachine.Transition{
+			{
+				Name:   "existing object",
+				From:   []statemachine.State{statemachine.StateUnknown},
+				When:   statemachine.Facts{LastDeployedReleaseVersionSet: true},
+				To:     statemachine.StateFalse,
+				Action: markCreatingFalseForExistingObject,
+			},
+			{
+				Name:   "existing Cluster upgrading to node pools",
+				From:   []statemachine.State{statemachine.StateUnknown},
+				When:   statemachine.Facts{FirstNodePoolUpgradeInProgress: true},
+				To:     statemachine.StateFalse,
+				Action: markCreatingFalseForExistingObject,
+			},
+			{
+				Name:   "creation started",
+				From:   []statemachine.State{statemachine.StateUnknown},
+				To:     statemachine.StateTrue,
+				Action: markCreatingTrue,
+			},
+			{
+				Name: "creation completed earlier",
+				From: []statemachine.State{statemachine.StateFalse},
+				To:   statemachine.StateFalse,
+			},
+			{
+				Name:   "creation completed",
+				From:   []statemachine.State{statemachine.StateTrue},
+				When:   statemachine.Facts{DesiredReleaseVersionDeployed: true},
+				To:     statemachine.StateFalse,
+				Action: markCreatingFalseWithCreationCompleted,
+			},
+			{
+				Name: "creation in progress",
+				From: []statemachine.State{statemachine.StateTrue},
+				To:   statemachine.StateTrue,
+			},
+		},
+	}
+}
+
+var stateMachine = StateMachine()
+
+// facts returns the facts about the specified object used by Creating
+// state-transition table.
+func facts(object conditions.Object) statemachine.Facts {
+	lastDeployedReleaseVersion, isLastDeployedReleaseVersionSet := object.GetAnnotations()[internal.LastDeployedReleaseVersion]
+
+	return statemachine.Facts{
+		LastDeployedReleaseVersionSet:  isLastDeployedReleaseVersionSet,
+		FirstNodePoolUpgradeInProgress: key.IsFirstNodePoolUpgradeInProgress(object),
+		DesiredReleaseVersionDeployed:  isLastDeployedReleaseVersionSet && lastDeployedReleaseVersion == key.ReleaseVersion(object),
+	}
+}
+
+// execute takes the transition of Creating state-transition table that
+// matches the current Creating condition and the specified facts at the
+// specified time.
+func execute(object conditions.Object, facts statemachine.Facts, now time.Time) error {
+	state := statemachine.StateOf(capiconditions.Get(object, conditions.Creating))
+	transition, err := stateMachine.Next(state, facts)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if transition.Action == "" {
+		return nil
+	}
+
+	action, ok := actions[transition.Action]
+	if !ok {
+		return microerror.Maskf(errors.InvalidConfigError, "action %s is not implemented", transition.Action)
+	}
+	action(object, now)
+
+	return nil
+}
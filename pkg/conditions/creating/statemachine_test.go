@@ -0,0 +1,76 @@
+package creating
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+	"github.com/giantswarm/conditions-handler/pkg/statemachine"
+)
+
+func TestStateMachineIsValid(t *testing.T) {
+	err := StateMachine().Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, action := range StateMachine().Actions() {
+		if _, ok := actions[action]; !ok {
+			t.Errorf("action %s is not implemented", action)
+		}
+	}
+}
+
+// TestStateMachineTransitions executes every transition for every state and
+// every combination of facts, and checks that Creating condition ends up in
+// the target state of the transition.
+func TestStateMachineTransitions(t *testing.T) {
+	table := StateMachine()
+	for _, state := range statemachine.States {
+		for _, facts := range table.AllFacts() {
+			transition, err := table.Next(state, facts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			name := fmt.Sprintf("%s with %v: %s", state, facts, transition.Name)
+			t.Run(name, func(t *testing.T) {
+				// arrange
+				cluster := &capi.Cluster{}
+				setState(cluster, state)
+
+				// act
+				err := execute(cluster, facts, time.Now())
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// assert
+				actual := statemachine.StateOf(capiconditions.Get(cluster, conditions.Creating))
+				if actual != transition.To {
+					t.Errorf("expected state %s, got %s", transition.To, actual)
+				}
+			})
+		}
+	}
+}
+
+func TestStateMachineDiagrams(t *testing.T) {
+	conditionstest.AssertGolden(t, "testdata/statemachine.dot", []byte(StateMachine().DOT()))
+	conditionstest.AssertGolden(t, "testdata/statemachine.mmd", []byte(StateMachine().Mermaid()))
+}
+
+func setState(object conditions.Object, state statemachine.State) {
+	switch state {
+	case statemachine.StateTrue:
+		capiconditions.Set(object, &capi.Condition{Type: conditions.Creating, Status: corev1.ConditionTrue})
+	case statemachine.StateFalse:
+		capiconditions.Set(object, &capi.Condition{Type: conditions.Creating, Status: corev1.ConditionFalse, Severity: capi.ConditionSeverityInfo})
+	}
+}
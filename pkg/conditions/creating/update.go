@@ -4,11 +4,11 @@ import (
 	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
 
 	"github.com/giantswarm/conditions-handler/pkg/internal"
-	"github.com/giantswarm/conditions-handler/pkg/key"
 )
 
 // MarkCreatingTrue sets Creating condition with status True.
@@ -16,11 +16,24 @@ func MarkCreatingTrue(object conditions.Object) {
 	capiconditions.MarkTrue(object, conditions.Creating)
 }
 
+// markCreatingTrueAt sets Creating condition like MarkCreatingTrue, with the
+// creation start at the specified time.
+func markCreatingTrueAt(object conditions.Object, now time.Time) {
+	internal.SetConditionAt(object, capiconditions.TrueCondition(conditions.Creating), now)
+}
+
 // MarkCreatingFalseWithCreationCompleted sets Creating condition with status
 // False, reason CreationCompleted, severity Info and a message informing how
 // long the creation took.
 func MarkCreatingFalseWithCreationCompleted(object conditions.Object) {
-	creationDuration := time.Since(object.GetCreationTimestamp().Time)
+	markCreatingFalseWithCreationCompletedAt(object, time.Now())
+}
+
+// markCreatingFalseWithCreationCompletedAt sets Creating condition like
+// MarkCreatingFalseWithCreationCompleted, with the creation duration
+// measured until the specified time.
+func markCreatingFalseWithCreationCompletedAt(object conditions.Object, now time.Time) {
+	creationDuration := now.Sub(object.GetCreationTimestamp().Time)
 	capiconditions.MarkFalse(
 		object,
 		conditions.Creating,
@@ -42,48 +55,13 @@ func MarkCreatingFalseForExistingObject(object conditions.Object) {
 		"Object was already created")
 }
 
-func update(object conditions.Object) {
-	// Creating condition is not set or it has Unknown status, let's set it for
-	// the first time.
-	if conditions.IsCreatingUnknown(object) {
-		initialize(object)
-		return
-	}
-
-	// Creating condition is False, which means that the cluster or node pool
-	// creation is completed, so we don't have to update it anymore.
-	if conditions.IsCreatingFalse(object) {
-		return
-	}
-
-	// Creating condition has Status set to True, let's check if the creation
-	// has been completed.
-	markCreatingFalseIfCreationCompleted(object)
-}
-
-func initialize(object conditions.Object) {
-	_, isLastDeployedReleaseVersionSet := object.GetAnnotations()[internal.LastDeployedReleaseVersion]
-
-	if isLastDeployedReleaseVersionSet || key.IsFirstNodePoolUpgradeInProgress(object) {
-		MarkCreatingFalseForExistingObject(object)
-	} else {
-		MarkCreatingTrue(object)
-	}
-}
-
-func markCreatingFalseIfCreationCompleted(object conditions.Object) {
-	lastDeployedReleaseVersion, isLastDeployedReleaseVersionSet := object.GetAnnotations()[internal.LastDeployedReleaseVersion]
-	if !isLastDeployedReleaseVersionSet {
-		// Cluster or node pool creation is not completed, since there is no
-		// last deployed release version set.
-		return
+// update sets Creating condition on specified object by executing Creating
+// state-transition table, see StateMachine.
+func update(object conditions.Object, now time.Time) error {
+	err := execute(object, facts(object), now)
+	if err != nil {
+		return microerror.Mask(err)
 	}
 
-	desiredReleaseVersion := key.ReleaseVersion(object)
-
-	if lastDeployedReleaseVersion == desiredReleaseVersion {
-		// Desired version has been reached, cluster or node pool creation has
-		// been completed! :)
-		MarkCreatingFalseWithCreationCompleted(object)
-	}
+	return nil
 }
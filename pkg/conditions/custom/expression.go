@@ -0,0 +1,115 @@
+package custom
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/giantswarm/microerror"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+const (
+	// ObjectVariable is the name of the variable that holds the reconciled
+	// object in expressions.
+	ObjectVariable = "object"
+)
+
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// expression is a compiled CEL expression.
+type expression struct {
+	text    string
+	program cel.Program
+}
+
+// newEnv creates a CEL environment where the reconciled object and all
+// looked up objects are declared as dynamic variables.
+func newEnv(variables []string) (*cel.Env, error) {
+	var declarations []*exprpb.Decl
+	for _, variable := range variables {
+		declarations = append(declarations, decls.NewVar(variable, decls.Dyn))
+	}
+
+	env, err := cel.NewEnv(cel.Declarations(declarations...))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return env, nil
+}
+
+// compile compiles the specified expression and checks that its result type
+// is one of the specified types. Dynamic result type is always accepted, and
+// the result type is checked again when the expression is evaluated.
+func compile(env *cel.Env, field, text string, resultTypes ...*exprpb.Type) (*expression, error) {
+	ast, issues := env.Compile(text)
+	if issues != nil && issues.Err() != nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "invalid %s expression %q: %s", field, text, issues.Err())
+	}
+
+	resultType := ast.ResultType()
+	valid := proto.Equal(resultType, decls.Dyn)
+	for _, t := range resultTypes {
+		valid = valid || proto.Equal(resultType, t)
+	}
+	if !valid {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "invalid %s expression %q: unexpected result type %s", field, text, resultType)
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "invalid %s expression %q: %s", field, text, err)
+	}
+
+	e := &expression{
+		text:    text,
+		program: program,
+	}
+
+	return e, nil
+}
+
+func (e *expression) eval(variables map[string]interface{}) (ref.Val, error) {
+	value, _, err := e.program.Eval(variables)
+	if err != nil {
+		return nil, microerror.Maskf(errors.ExpressionEvaluationFailedError, "expression %q: %s", e.text, err)
+	}
+
+	return value, nil
+}
+
+func (e *expression) evalString(variables map[string]interface{}) (string, error) {
+	value, err := e.eval(variables)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	s, ok := value.(types.String)
+	if !ok {
+		return "", microerror.Maskf(errors.ExpressionEvaluationFailedError, "expression %q: expected string, got %s", e.text, value.Type().TypeName())
+	}
+
+	return string(s), nil
+}
+
+func validateIdentifier(name string) error {
+	if !identifierRegexp.MatchString(name) {
+		return microerror.Maskf(errors.InvalidConfigError, "lookup name %q is not a valid identifier", name)
+	}
+	if name == ObjectVariable {
+		return microerror.Maskf(errors.InvalidConfigError, "lookup name %q is reserved", name)
+	}
+
+	return nil
+}
+
+func sprintValue(value ref.Val) string {
+	return fmt.Sprintf("%v", value.Value())
+}
@@ -0,0 +1,290 @@
+package custom
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/google/cel-go/checker/decls"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/cache"
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+	"github.com/giantswarm/conditions-handler/pkg/key"
+)
+
+// Lookup describes an object that is fetched from the k8s API and made
+// available to expressions as a variable. The object is looked up in the
+// namespace of the reconciled object. When it is not found, the variable is
+// null.
+type Lookup struct {
+	// Name is the variable name, e.g. kubeconfig.
+	Name string
+	// APIVersion and Kind of the looked up object, e.g. v1 and Secret.
+	APIVersion string
+	Kind       string
+	// ObjectName is an expression that evaluates to the name of the looked
+	// up object, e.g. object.metadata.name + "-kubeconfig".
+	ObjectName string
+}
+
+// Expressions are CEL expressions that compute the condition. The
+// reconciled object is available as variable "object", and looked up
+// objects as variables with lookup names. Objects are represented as maps,
+// like in their JSON representation.
+type Expressions struct {
+	// Status evaluates to a bool, or to a string "True", "False" or
+	// "Unknown". It must not be empty.
+	Status string
+	// Reason evaluates to the condition reason. It is used only when the
+	// condition is False or Unknown, or True with negative polarity, see
+	// HandlerConfig.NegativePolarity. When empty, False condition is set
+	// with ConditionNotMet reason and Unknown condition with
+	// ConditionUnknown reason.
+	Reason string
+	// Severity evaluates to "Error", "Warning" or "Info". It is used only
+	// when the condition is False or Unknown. Defaults to Warning.
+	Severity string
+	// Message evaluates to the condition message.
+	Message string
+}
+
+type HandlerConfig struct {
+	CtrlClient ctrl.Client
+	Logger     micrologger.Logger
+
+	Name         string
+	UpdateStatus bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
+
+	// ConditionType is the type of the computed condition, e.g.
+	// KubeconfigSecretPresent.
+	ConditionType capi.ConditionType
+	// NegativePolarity is set for conditions that are True when something is
+	// in progress or wrong, e.g. KubeconfigSecretMissing. Only such
+	// conditions have a reason when True, so they must be registered with
+	// negative polarity in the catalog or in the validator config, see
+	// catalog.CustomConditionType.
+	NegativePolarity bool
+	Lookups          []Lookup
+	Expressions      Expressions
+}
+
+type Handler struct {
+	ctrlClient      ctrl.Client
+	internalHandler *internal.Handler
+	logger          micrologger.Logger
+	name            string
+
+	conditionType    capi.ConditionType
+	negativePolarity bool
+	lookups          []lookup
+	status           *expression
+	reason           *expression
+	severity         *expression
+	message          *expression
+}
+
+type lookup struct {
+	name       string
+	gvk        schema.GroupVersionKind
+	objectName *expression
+}
+
+func NewHandler(config HandlerConfig) (*Handler, error) {
+	if config.ConditionType == "" {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.ConditionType must not be empty", config)
+	}
+	if config.Expressions.Status == "" {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Expressions.Status must not be empty", config)
+	}
+
+	h := &Handler{
+		ctrlClient:       config.CtrlClient,
+		logger:           config.Logger,
+		name:             config.Name,
+		conditionType:    config.ConditionType,
+		negativePolarity: config.NegativePolarity,
+	}
+
+	err := h.compile(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	internalHandlerConfig := internal.HandlerConfig{
+		CtrlClient:        config.CtrlClient,
+		Logger:            config.Logger,
+		UpdateStatus:      config.UpdateStatus,
+		ConditionType:     config.ConditionType,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
+		EnsureCreatedFunc: h.ensureCreated,
+	}
+
+	internalHandler, err := internal.NewHandler(internalHandlerConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	h.internalHandler = internalHandler
+
+	return h, nil
+}
+
+func (h *Handler) EnsureCreated(ctx context.Context, object interface{}) error {
+	obj, err := key.ToObjectWithConditions(object)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return h.internalHandler.EnsureCreated(ctx, obj)
+}
+
+func (h *Handler) EnsureDeleted(_ context.Context, _ interface{}) error {
+	return nil
+}
+
+func (h *Handler) Name() string {
+	return h.name
+}
+
+// compile validates lookups and compiles all expressions.
+func (h *Handler) compile(config HandlerConfig) error {
+	objectEnv, err := newEnv([]string{ObjectVariable})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	variables := []string{ObjectVariable}
+	for _, l := range config.Lookups {
+		err = validateIdentifier(l.Name)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for _, variable := range variables {
+			if variable == l.Name {
+				return microerror.Maskf(errors.InvalidConfigError, "lookup name %q is not unique", l.Name)
+			}
+		}
+		if l.APIVersion == "" || l.Kind == "" {
+			return microerror.Maskf(errors.InvalidConfigError, "lookup %q must have APIVersion and Kind set", l.Name)
+		}
+		gv, err := schema.ParseGroupVersion(l.APIVersion)
+		if err != nil {
+			return microerror.Maskf(errors.InvalidConfigError, "lookup %q has invalid APIVersion %q: %s", l.Name, l.APIVersion, err)
+		}
+		objectName, err := compile(objectEnv, "lookup "+l.Name+" object name", l.ObjectName, decls.String)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		h.lookups = append(h.lookups, lookup{
+			name:       l.Name,
+			gvk:        gv.WithKind(l.Kind),
+			objectName: objectName,
+		})
+		variables = append(variables, l.Name)
+	}
+
+	env, err := newEnv(variables)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	h.status, err = compile(env, "status", config.Expressions.Status, decls.Bool, decls.String)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if config.Expressions.Reason != "" {
+		h.reason, err = compile(env, "reason", config.Expressions.Reason, decls.String)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+	if config.Expressions.Severity != "" {
+		h.severity, err = compile(env, "severity", config.Expressions.Severity, decls.String)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+	if config.Expressions.Message != "" {
+		h.message, err = compile(env, "message", config.Expressions.Message, decls.String)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+func (h *Handler) ensureCreated(ctx context.Context, object conditions.Object) error {
+	variables, err := h.variables(ctx, object)
+	if errors.IsExpressionEvaluationFailed(err) {
+		h.logger.Errorf(ctx, err, "failed to look up objects for condition %s", h.conditionType)
+		markUnknownWithEvaluationFailed(object, h.conditionType, err)
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r, err := h.evaluate(variables)
+	if errors.IsExpressionEvaluationFailed(err) {
+		h.logger.Errorf(ctx, err, "failed to evaluate condition %s", h.conditionType)
+		markUnknownWithEvaluationFailed(object, h.conditionType, err)
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	update(object, h.conditionType, r)
+	return nil
+}
+
+// variables returns the reconciled object and all looked up objects as
+// expression variables.
+func (h *Handler) variables(ctx context.Context, object conditions.Object) (map[string]interface{}, error) {
+	o, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	variables := map[string]interface{}{
+		ObjectVariable: o,
+	}
+
+	for _, l := range h.lookups {
+		objectName, err := l.objectName.evalString(map[string]interface{}{ObjectVariable: o})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(l.gvk)
+		err = cache.Get(ctx, h.ctrlClient, ctrl.ObjectKey{Namespace: object.GetNamespace(), Name: objectName}, u)
+		if apierrors.IsNotFound(microerror.Cause(err)) {
+			variables[l.name] = nil
+			continue
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		variables[l.name] = u.Object
+	}
+
+	return variables, nil
+}
@@ -0,0 +1,129 @@
+package custom
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	"github.com/google/cel-go/common/types"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+const (
+	// ConditionNotMetReason is used when the condition is False and the
+	// reason expression is not set.
+	ConditionNotMetReason = "ConditionNotMet"
+
+	// ConditionUnknownReason is used when the condition is Unknown and the
+	// reason expression is not set.
+	ConditionUnknownReason = "ConditionUnknown"
+
+	// EvaluationFailedReason is used when an expression cannot be
+	// evaluated, e.g. because of a missing field.
+	EvaluationFailedReason = "EvaluationFailed"
+)
+
+// result is the condition computed by expressions.
+type result struct {
+	status   corev1.ConditionStatus
+	reason   string
+	severity capi.ConditionSeverity
+	message  string
+}
+
+func (h *Handler) evaluate(variables map[string]interface{}) (result, error) {
+	var r result
+
+	value, err := h.status.eval(variables)
+	if err != nil {
+		return result{}, microerror.Mask(err)
+	}
+	switch v := value.(type) {
+	case types.Bool:
+		r.status = corev1.ConditionFalse
+		if v {
+			r.status = corev1.ConditionTrue
+		}
+	case types.String:
+		r.status = corev1.ConditionStatus(v)
+		if r.status != corev1.ConditionTrue && r.status != corev1.ConditionFalse && r.status != corev1.ConditionUnknown {
+			return result{}, microerror.Maskf(errors.ExpressionEvaluationFailedError, "status expression %q: expected True, False or Unknown, got %q", h.status.text, string(v))
+		}
+	default:
+		return result{}, microerror.Maskf(errors.ExpressionEvaluationFailedError, "status expression %q: expected bool or string, got %s", h.status.text, sprintValue(value))
+	}
+
+	// True conditions have a reason only with negative polarity, see
+	// HandlerConfig.NegativePolarity.
+	if h.reason != nil && (r.status != corev1.ConditionTrue || h.negativePolarity) {
+		r.reason, err = h.reason.evalString(variables)
+		if err != nil {
+			return result{}, microerror.Mask(err)
+		}
+	}
+
+	r.severity = capi.ConditionSeverityWarning
+	if h.severity != nil && r.status != corev1.ConditionTrue {
+		severity, err := h.severity.evalString(variables)
+		if err != nil {
+			return result{}, microerror.Mask(err)
+		}
+		r.severity = capi.ConditionSeverity(severity)
+		if r.severity != capi.ConditionSeverityError && r.severity != capi.ConditionSeverityWarning && r.severity != capi.ConditionSeverityInfo {
+			return result{}, microerror.Maskf(errors.ExpressionEvaluationFailedError, "severity expression %q: expected Error, Warning or Info, got %q", h.severity.text, severity)
+		}
+	}
+
+	if h.message != nil {
+		r.message, err = h.message.evalString(variables)
+		if err != nil {
+			return result{}, microerror.Mask(err)
+		}
+	}
+
+	return r, nil
+}
+
+func update(object conditions.Object, conditionType capi.ConditionType, r result) {
+	switch r.status {
+	case corev1.ConditionTrue:
+		capiconditions.Set(object, &capi.Condition{
+			Type:    conditionType,
+			Status:  corev1.ConditionTrue,
+			Reason:  r.reason,
+			Message: r.message,
+		})
+	case corev1.ConditionFalse:
+		reason := r.reason
+		if reason == "" {
+			reason = ConditionNotMetReason
+		}
+		capiconditions.MarkFalse(object, conditionType, reason, r.severity, "%s", r.message)
+	default:
+		reason := r.reason
+		if reason == "" {
+			reason = ConditionUnknownReason
+		}
+		capiconditions.Set(object, &capi.Condition{
+			Type:     conditionType,
+			Status:   corev1.ConditionUnknown,
+			Reason:   reason,
+			Severity: r.severity,
+			Message:  r.message,
+		})
+	}
+}
+
+func markUnknownWithEvaluationFailed(object conditions.Object, conditionType capi.ConditionType, err error) {
+	capiconditions.Set(object, &capi.Condition{
+		Type:     conditionType,
+		Status:   corev1.ConditionUnknown,
+		Reason:   EvaluationFailedReason,
+		Severity: capi.ConditionSeverityWarning,
+		Message:  fmt.Sprintf("Condition cannot be evaluated: %s", err.Error()),
+	})
+}
@@ -0,0 +1,243 @@
+package custom
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+const kubeconfigSecretPresent capi.ConditionType = "KubeconfigSecretPresent"
+
+func TestEnsureCreated(t *testing.T) {
+	kubeconfigLookup := Lookup{
+		Name:       "kubeconfig",
+		APIVersion: "v1",
+		Kind:       "Secret",
+		ObjectName: `object.metadata.name + "-kubeconfig"`,
+	}
+
+	testCases := []struct {
+		name              string
+		lookups           []Lookup
+		negativePolarity  bool
+		expressions       Expressions
+		secret            *corev1.Secret
+		expectedCondition *capi.Condition
+	}{
+		{
+			name:    "case 0: condition is True when looked up object exists",
+			lookups: []Lookup{kubeconfigLookup},
+			expressions: Expressions{
+				Status: "kubeconfig != null",
+			},
+			secret: newSecret("test1-kubeconfig"),
+			expectedCondition: &capi.Condition{
+				Type:   kubeconfigSecretPresent,
+				Status: corev1.ConditionTrue,
+			},
+		},
+		{
+			name:    "case 1: condition is False with computed reason, severity and message when looked up object is not found",
+			lookups: []Lookup{kubeconfigLookup},
+			expressions: Expressions{
+				Status:   "kubeconfig != null",
+				Reason:   `"KubeconfigSecretNotFound"`,
+				Severity: `"Info"`,
+				Message:  `"Secret " + object.metadata.name + "-kubeconfig not found"`,
+			},
+			expectedCondition: &capi.Condition{
+				Type:     kubeconfigSecretPresent,
+				Status:   corev1.ConditionFalse,
+				Reason:   "KubeconfigSecretNotFound",
+				Severity: capi.ConditionSeverityInfo,
+				Message:  "Secret test1-kubeconfig not found",
+			},
+		},
+		{
+			name: "case 2: status can be computed as string, default reason and severity are used",
+			expressions: Expressions{
+				Status: `has(object.spec.paused) && object.spec.paused ? "False" : "True"`,
+			},
+			expectedCondition: &capi.Condition{
+				Type:   kubeconfigSecretPresent,
+				Status: corev1.ConditionTrue,
+			},
+		},
+		{
+			name: "case 3: condition is Unknown when expression cannot be evaluated",
+			expressions: Expressions{
+				Status: "object.status.notExisting == 1",
+			},
+			expectedCondition: &capi.Condition{
+				Type:     kubeconfigSecretPresent,
+				Status:   corev1.ConditionUnknown,
+				Reason:   EvaluationFailedReason,
+				Severity: capi.ConditionSeverityWarning,
+				Message:  "Condition cannot be evaluated",
+			},
+		},
+		{
+			name:    "case 4: True condition with positive polarity does not have computed reason",
+			lookups: []Lookup{kubeconfigLookup},
+			expressions: Expressions{
+				Status:  "kubeconfig != null",
+				Reason:  `kubeconfig != null ? "KubeconfigSecretFound" : "KubeconfigSecretNotFound"`,
+				Message: `"Secret " + object.metadata.name + "-kubeconfig found"`,
+			},
+			secret: newSecret("test1-kubeconfig"),
+			expectedCondition: &capi.Condition{
+				Type:    kubeconfigSecretPresent,
+				Status:  corev1.ConditionTrue,
+				Message: "Secret test1-kubeconfig found",
+			},
+		},
+		{
+			name:             "case 5: True condition with negative polarity has computed reason",
+			negativePolarity: true,
+			lookups:          []Lookup{kubeconfigLookup},
+			expressions: Expressions{
+				Status: "kubeconfig == null",
+				Reason: `"KubeconfigSecretNotFound"`,
+			},
+			expectedCondition: &capi.Condition{
+				Type:   kubeconfigSecretPresent,
+				Status: corev1.ConditionTrue,
+				Reason: "KubeconfigSecretNotFound",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			ctx := context.Background()
+			client := internal.NewFakeClient(capi.AddToScheme, corev1.AddToScheme)
+			if tc.secret != nil {
+				err := client.Create(ctx, tc.secret)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			h, err := NewHandler(HandlerConfig{
+				CtrlClient:       client,
+				Logger:           newTestLogger(t),
+				Name:             "test",
+				ConditionType:    kubeconfigSecretPresent,
+				NegativePolarity: tc.negativePolarity,
+				Lookups:          tc.lookups,
+				Expressions:      tc.expressions,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster := &capi.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-test", Name: "test1"}}
+
+			// act
+			err = h.EnsureCreated(ctx, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			condition := capiconditions.Get(cluster, kubeconfigSecretPresent)
+			if tc.expectedCondition.Status == corev1.ConditionUnknown {
+				// Evaluation error details are not compared.
+				if condition != nil && strings.HasPrefix(condition.Message, tc.expectedCondition.Message) {
+					condition.Message = tc.expectedCondition.Message
+				}
+			}
+			if condition == nil || !internal.AreEqualWithIgnoringLastTransitionTime(condition, tc.expectedCondition) {
+				t.Logf(
+					"expected %s, got %s",
+					internal.SprintComparedCondition(tc.expectedCondition),
+					internal.SprintComparedCondition(condition))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestNewHandlerValidation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		lookups     []Lookup
+		expressions Expressions
+	}{
+		{
+			name:        "case 0: status expression is required",
+			expressions: Expressions{},
+		},
+		{
+			name:        "case 1: syntax error",
+			expressions: Expressions{Status: "object.metadata.name =="},
+		},
+		{
+			name:        "case 2: undeclared variable",
+			expressions: Expressions{Status: "kubeconfig != null"},
+		},
+		{
+			name:        "case 3: wrong result type",
+			expressions: Expressions{Status: "true", Message: "1 + 2"},
+		},
+		{
+			name:        "case 4: lookup with reserved name",
+			lookups:     []Lookup{{Name: "object", APIVersion: "v1", Kind: "Secret", ObjectName: `"test"`}},
+			expressions: Expressions{Status: "true"},
+		},
+		{
+			name:        "case 5: lookup without kind",
+			lookups:     []Lookup{{Name: "secret", APIVersion: "v1", ObjectName: `"test"`}},
+			expressions: Expressions{Status: "true"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+
+			// act
+			_, err := NewHandler(HandlerConfig{
+				CtrlClient:    internal.NewFakeClient(capi.AddToScheme),
+				Logger:        newTestLogger(t),
+				Name:          "test",
+				ConditionType: kubeconfigSecretPresent,
+				Lookups:       tc.lookups,
+				Expressions:   tc.expressions,
+			})
+
+			// assert
+			if !errors.IsInvalidConfig(err) {
+				t.Errorf("expected invalid config error, got %#v", err)
+			}
+		})
+	}
+}
+
+func newSecret(name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-test",
+			Name:      name,
+		},
+	}
+}
+
+func newTestLogger(t *testing.T) micrologger.Logger {
+	logger, err := micrologger.New(micrologger.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return logger
+}
@@ -0,0 +1,114 @@
+package degraded
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+	"github.com/giantswarm/conditions-handler/pkg/key"
+)
+
+type HandlerConfig struct {
+	CtrlClient ctrl.Client
+	Logger     micrologger.Logger
+
+	Name         string
+	UpdateStatus bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
+
+	// Inputs are conditions from which Degraded is computed. By default all
+	// object conditions except Ready and Degraded are used.
+	Inputs []capi.ConditionType
+	// LifecycleWindows are expected durations of lifecycle conditions with
+	// negative polarity, like Creating or Upgrading. A lifecycle condition
+	// that is True for longer than its window is considered degraded with
+	// Warning severity. Zero window means that the condition is never
+	// considered degraded. Creating, Upgrading, NodePoolsUpgrading, Scaling,
+	// Paused and Frozen are lifecycle conditions with zero window by
+	// default. The duration is measured from LastTransitionTime, which
+	// lifecycle handlers set with their clock when the lifecycle starts and
+	// keep while it progresses, so all handlers should use the same Now.
+	LifecycleWindows map[capi.ConditionType]time.Duration
+}
+
+type Handler struct {
+	ctrlClient      ctrl.Client
+	internalHandler *internal.Handler
+	logger          micrologger.Logger
+	name            string
+
+	inputs           []capi.ConditionType
+	lifecycleWindows map[capi.ConditionType]time.Duration
+}
+
+func NewHandler(config HandlerConfig) (*Handler, error) {
+	lifecycleWindows := defaultLifecycleWindows()
+	for conditionType, window := range config.LifecycleWindows {
+		if window < 0 {
+			return nil, microerror.Maskf(errors.InvalidConfigError, "%T.LifecycleWindows[%s] must not be negative", config, conditionType)
+		}
+		lifecycleWindows[conditionType] = window
+	}
+
+	h := &Handler{
+		ctrlClient:       config.CtrlClient,
+		logger:           config.Logger,
+		name:             config.Name,
+		inputs:           config.Inputs,
+		lifecycleWindows: lifecycleWindows,
+	}
+
+	internalHandlerConfig := internal.HandlerConfig{
+		CtrlClient:        config.CtrlClient,
+		Logger:            config.Logger,
+		UpdateStatus:      config.UpdateStatus,
+		ConditionType:     Degraded,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
+		EnsureCreatedFunc: h.ensureCreated,
+	}
+
+	internalHandler, err := internal.NewHandler(internalHandlerConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	h.internalHandler = internalHandler
+
+	return h, nil
+}
+
+func (h *Handler) EnsureCreated(ctx context.Context, object interface{}) error {
+	obj, err := key.ToObjectWithConditions(object)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return h.internalHandler.EnsureCreated(ctx, obj)
+}
+
+func (h *Handler) EnsureDeleted(_ context.Context, _ interface{}) error {
+	return nil
+}
+
+func (h *Handler) Name() string {
+	return h.name
+}
+
+func (h *Handler) ensureCreated(_ context.Context, object conditions.Object) error {
+	update(object, h.inputs, h.lifecycleWindows, h.internalHandler.Now())
+	return nil
+}
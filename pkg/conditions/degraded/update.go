@@ -0,0 +1,137 @@
+package degraded
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/frozen"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsupgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
+)
+
+const (
+	// Degraded is a condition with negative polarity. It is set with status
+	// True only when some of the input conditions are False with Warning or
+	// Error severity, or when a lifecycle condition is True for longer than
+	// expected.
+	Degraded capi.ConditionType = "Degraded"
+
+	// DegradedWithErrorReason is used when at least one of the offending
+	// conditions has Error severity.
+	DegradedWithErrorReason = "DegradedWithError"
+
+	// DegradedWithWarningReason is used when all offending conditions have
+	// Warning severity.
+	DegradedWithWarningReason = "DegradedWithWarning"
+
+	// NotDegradedReason is used when none of the input conditions is
+	// offending.
+	NotDegradedReason = "NotDegraded"
+)
+
+func defaultLifecycleWindows() map[capi.ConditionType]time.Duration {
+	return map[capi.ConditionType]time.Duration{
+		conditions.Creating:                   0,
+		conditions.Upgrading:                  0,
+		nodepoolsupgrading.NodePoolsUpgrading: 0,
+		scaling.Scaling:                       0,
+		paused.Paused:                         0,
+		frozen.Frozen:                         0,
+	}
+}
+
+// MarkDegradedTrue sets Degraded condition with status True, with a reason
+// that tells the highest severity of the offending conditions, and with a
+// message that lists them.
+func MarkDegradedTrue(object conditions.Object, offending []string, severity capi.ConditionSeverity) {
+	reason := DegradedWithWarningReason
+	if severity == capi.ConditionSeverityError {
+		reason = DegradedWithErrorReason
+	}
+
+	capiconditions.Set(object, &capi.Condition{
+		Type:    Degraded,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: strings.Join(offending, "; "),
+	})
+}
+
+// MarkDegradedFalse sets Degraded condition with status False, reason
+// NotDegraded and severity Info.
+func MarkDegradedFalse(object conditions.Object, inputCount int) {
+	capiconditions.MarkFalse(
+		object,
+		Degraded,
+		NotDegradedReason,
+		capi.ConditionSeverityInfo,
+		"None of the %d conditions is degraded",
+		inputCount)
+}
+
+func update(object conditions.Object, inputs []capi.ConditionType, lifecycleWindows map[capi.ConditionType]time.Duration, now time.Time) {
+	var inputConditions []capi.Condition
+	if len(inputs) > 0 {
+		for _, conditionType := range inputs {
+			condition := capiconditions.Get(object, conditionType)
+			if condition != nil {
+				inputConditions = append(inputConditions, *condition)
+			}
+		}
+	} else {
+		for _, condition := range object.GetConditions() {
+			if condition.Type != capi.ReadyCondition && condition.Type != Degraded {
+				inputConditions = append(inputConditions, condition)
+			}
+		}
+	}
+
+	var offending []string
+	severity := capi.ConditionSeverityWarning
+	for _, condition := range inputConditions {
+		window, isLifecycle := lifecycleWindows[condition.Type]
+		if isLifecycle {
+			// Duration is not included in the message, so that the message
+			// does not change on every reconciliation.
+			duration := now.Sub(condition.LastTransitionTime.Time)
+			if condition.Status == corev1.ConditionTrue && window > 0 && duration > window {
+				offending = append(offending, fmt.Sprintf(
+					"%s (Warning): True for longer than expected %s",
+					condition.Type,
+					window))
+			}
+			continue
+		}
+
+		if condition.Status != corev1.ConditionFalse {
+			continue
+		}
+		if condition.Severity != capi.ConditionSeverityWarning && condition.Severity != capi.ConditionSeverityError {
+			continue
+		}
+		if condition.Severity == capi.ConditionSeverityError {
+			severity = capi.ConditionSeverityError
+		}
+
+		text := fmt.Sprintf("%s (%s)", condition.Type, condition.Severity)
+		if condition.Message != "" {
+			text += ": " + condition.Message
+		} else if condition.Reason != "" {
+			text += ": " + condition.Reason
+		}
+		offending = append(offending, text)
+	}
+
+	if len(offending) > 0 {
+		MarkDegradedTrue(object, offending, severity)
+	} else {
+		MarkDegradedFalse(object, len(inputConditions))
+	}
+}
@@ -0,0 +1,173 @@
+package degraded
+
+import (
+	"testing"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/upgrading"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestUpdate(t *testing.T) {
+	now := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name              string
+		inputs            []capi.ConditionType
+		lifecycleWindows  map[capi.ConditionType]time.Duration
+		conditions        []capi.Condition
+		expectedCondition *capi.Condition
+	}{
+		{
+			name: "case 0: Info conditions and lifecycle conditions in progress are not degraded",
+			conditions: []capi.Condition{
+				*capiconditions.TrueCondition(conditions.Creating),
+				*capiconditions.FalseCondition(conditions.NodePoolsReady, conditions.NodePoolsNotFoundReason, capi.ConditionSeverityInfo, ""),
+				*capiconditions.TrueCondition(capi.InfrastructureReadyCondition),
+				*capiconditions.FalseCondition(capi.ReadyCondition, "NotReady", capi.ConditionSeverityError, ""),
+			},
+			expectedCondition: &capi.Condition{
+				Type:     Degraded,
+				Status:   corev1.ConditionFalse,
+				Reason:   NotDegradedReason,
+				Severity: capi.ConditionSeverityInfo,
+				Message:  "None of the 3 conditions is degraded",
+			},
+		},
+		{
+			name: "case 1: Warning and Error conditions are listed in the message",
+			conditions: []capi.Condition{
+				*capiconditions.FalseCondition(capi.ControlPlaneReadyCondition, "ControlPlaneNotReady", capi.ConditionSeverityWarning, "Control plane is not ready"),
+				*capiconditions.FalseCondition(capi.InfrastructureReadyCondition, "InfrastructureFailed", capi.ConditionSeverityError, ""),
+			},
+			expectedCondition: &capi.Condition{
+				Type:    Degraded,
+				Status:  corev1.ConditionTrue,
+				Reason:  DegradedWithErrorReason,
+				Message: "ControlPlaneReady (Warning): Control plane is not ready; InfrastructureReady (Error): InfrastructureFailed",
+			},
+		},
+		{
+			name:             "case 2: lifecycle condition that is True for longer than its window is degraded",
+			lifecycleWindows: map[capi.ConditionType]time.Duration{conditions.Upgrading: 2 * time.Hour},
+			conditions: []capi.Condition{
+				{
+					Type:               conditions.Upgrading,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(now.Add(-3 * time.Hour)),
+				},
+			},
+			expectedCondition: &capi.Condition{
+				Type:    Degraded,
+				Status:  corev1.ConditionTrue,
+				Reason:  DegradedWithWarningReason,
+				Message: "Upgrading (Warning): True for longer than expected 2h0m0s",
+			},
+		},
+		{
+			name:             "case 3: lifecycle condition within its window is not degraded",
+			lifecycleWindows: map[capi.ConditionType]time.Duration{conditions.Upgrading: 2 * time.Hour},
+			conditions: []capi.Condition{
+				{
+					Type:               conditions.Upgrading,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(now.Add(-1 * time.Hour)),
+				},
+			},
+			expectedCondition: &capi.Condition{
+				Type:     Degraded,
+				Status:   corev1.ConditionFalse,
+				Reason:   NotDegradedReason,
+				Severity: capi.ConditionSeverityInfo,
+				Message:  "None of the 1 conditions is degraded",
+			},
+		},
+		{
+			name:   "case 4: only configured inputs are checked",
+			inputs: []capi.ConditionType{capi.InfrastructureReadyCondition},
+			conditions: []capi.Condition{
+				*capiconditions.FalseCondition(capi.ControlPlaneReadyCondition, "ControlPlaneNotReady", capi.ConditionSeverityWarning, "Control plane is not ready"),
+				*capiconditions.TrueCondition(capi.InfrastructureReadyCondition),
+			},
+			expectedCondition: &capi.Condition{
+				Type:     Degraded,
+				Status:   corev1.ConditionFalse,
+				Reason:   NotDegradedReason,
+				Severity: capi.ConditionSeverityInfo,
+				Message:  "None of the 1 conditions is degraded",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			cluster := &capi.Cluster{}
+			cluster.SetConditions(tc.conditions)
+			lifecycleWindows := defaultLifecycleWindows()
+			for conditionType, window := range tc.lifecycleWindows {
+				lifecycleWindows[conditionType] = window
+			}
+
+			// act
+			update(cluster, tc.inputs, lifecycleWindows, now)
+
+			// assert
+			condition := capiconditions.Get(cluster, Degraded)
+			if condition == nil || !internal.AreEqualWithIgnoringLastTransitionTime(condition, tc.expectedCondition) {
+				t.Logf(
+					"expected %s, got %s",
+					internal.SprintComparedCondition(tc.expectedCondition),
+					internal.SprintComparedCondition(condition))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestUpdateWithUpgradeProgress(t *testing.T) {
+	testName := "Upgrading condition that progresses is degraded when the upgrade takes longer than expected"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		now := time.Now()
+		cluster := &capi.Cluster{}
+		cluster.SetConditions(capi.Conditions{
+			{
+				Type:               conditions.Upgrading,
+				Status:             corev1.ConditionTrue,
+				Message:            "Control plane upgrade done, 0/3 node pools upgraded",
+				LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Hour)),
+			},
+		})
+		upgrading.MarkUpgradingTrueWithNodePoolsProgress(cluster, 1, 3)
+		lifecycleWindows := defaultLifecycleWindows()
+		lifecycleWindows[conditions.Upgrading] = time.Hour
+
+		// act
+		update(cluster, nil, lifecycleWindows, now)
+
+		// assert
+		expected := &capi.Condition{
+			Type:    Degraded,
+			Status:  corev1.ConditionTrue,
+			Reason:  DegradedWithWarningReason,
+			Message: "Upgrading (Warning): True for longer than expected 1h0m0s",
+		}
+		condition := capiconditions.Get(cluster, Degraded)
+		if condition == nil || !internal.AreEqualWithIgnoringLastTransitionTime(condition, expected) {
+			t.Logf(
+				"expected %s, got %s",
+				internal.SprintComparedCondition(expected),
+				internal.SprintComparedCondition(condition))
+			t.Fail()
+		}
+	})
+}
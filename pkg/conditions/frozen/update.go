@@ -0,0 +1,49 @@
+package frozen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+const (
+	// Frozen is a condition with negative polarity. It is set with status
+	// True when some conditions are frozen with internal.SkipAnnotation, and
+	// removed when the annotation is removed.
+	Frozen capi.ConditionType = "Frozen"
+
+	// SkipAnnotationReason is used when conditions are frozen with
+	// internal.SkipAnnotation.
+	SkipAnnotationReason = "SkipAnnotation"
+)
+
+// MarkFrozenTrue sets Frozen condition with status True and a message
+// listing the specified frozen conditions.
+func MarkFrozenTrue(object conditions.Object, frozenConditions []capi.ConditionType) {
+	conditionTypes := make([]string, 0, len(frozenConditions))
+	for _, conditionType := range frozenConditions {
+		conditionTypes = append(conditionTypes, string(conditionType))
+	}
+
+	capiconditions.Set(object, &capi.Condition{
+		Type:    Frozen,
+		Status:  corev1.ConditionTrue,
+		Reason:  SkipAnnotationReason,
+		Message: fmt.Sprintf("Conditions frozen with %s annotation: %s", internal.SkipAnnotation, strings.Join(conditionTypes, ", ")),
+	})
+}
+
+func update(object conditions.Object) {
+	frozenConditions := internal.FrozenConditions(object)
+	if len(frozenConditions) > 0 {
+		MarkFrozenTrue(object, frozenConditions)
+	} else {
+		capiconditions.Delete(object, Frozen)
+	}
+}
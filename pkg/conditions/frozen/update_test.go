@@ -0,0 +1,78 @@
+package frozen
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestUpdate(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		annotations            map[string]string
+		initialFrozenCondition bool
+		expectedCondition      *capi.Condition
+	}{
+		{
+			name: "case 0: Frozen condition is not set when there is no skip annotation",
+		},
+		{
+			name:        "case 1: Frozen condition lists conditions frozen with skip annotation",
+			annotations: map[string]string{internal.SkipAnnotation: "upgrading, ReplicasReady"},
+			expectedCondition: &capi.Condition{
+				Type:    Frozen,
+				Status:  corev1.ConditionTrue,
+				Reason:  SkipAnnotationReason,
+				Message: "Conditions frozen with conditions.giantswarm.io/skip annotation: upgrading, ReplicasReady",
+			},
+		},
+		{
+			name:                   "case 2: Frozen condition is removed when skip annotation is removed",
+			initialFrozenCondition: true,
+		},
+		{
+			name:                   "case 3: Frozen condition is removed when skip annotation is empty",
+			annotations:            map[string]string{internal.SkipAnnotation: ""},
+			initialFrozenCondition: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "org-test",
+					Name:        "test1",
+					Annotations: tc.annotations,
+				},
+			}
+			if tc.initialFrozenCondition {
+				capiconditions.MarkTrue(cluster, Frozen)
+			}
+
+			// act
+			update(cluster)
+
+			// assert
+			condition := capiconditions.Get(cluster, Frozen)
+			if tc.expectedCondition == nil {
+				if condition != nil {
+					t.Errorf("expected Frozen condition not to be set, got %s", internal.SprintComparedCondition(condition))
+				}
+			} else if condition == nil || !internal.AreEqualWithIgnoringLastTransitionTime(condition, tc.expectedCondition) {
+				t.Logf(
+					"expected Frozen condition %s, got %s",
+					internal.SprintComparedCondition(tc.expectedCondition),
+					internal.SprintComparedCondition(condition))
+				t.Fail()
+			}
+		})
+	}
+}
@@ -2,17 +2,22 @@ package infrastructureready
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
 	"github.com/giantswarm/conditions-handler/pkg/internal"
 	"github.com/giantswarm/conditions-handler/pkg/key"
+	"github.com/giantswarm/conditions-handler/pkg/watch"
 )
 
 type HandlerConfig struct {
@@ -21,20 +26,32 @@ type HandlerConfig struct {
 
 	Name         string
 	UpdateStatus bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
+
+	// InfrastructureGVKs are kinds of provider-specific infrastructure
+	// objects, e.g. AzureCluster or AzureMachinePool. They are used only for
+	// setting up watches, see Watches.
+	InfrastructureGVKs []schema.GroupVersionKind
 }
 
 type Handler struct {
-	ctrlClient      ctrl.Client
-	internalHandler *internal.Handler
-	logger          micrologger.Logger
-	name            string
+	ctrlClient         ctrl.Client
+	internalHandler    *internal.Handler
+	logger             micrologger.Logger
+	name               string
+	infrastructureGVKs []schema.GroupVersionKind
 }
 
 func NewHandler(config HandlerConfig) (*Handler, error) {
 	h := &Handler{
-		ctrlClient: config.CtrlClient,
-		logger:     config.Logger,
-		name:       config.Name,
+		ctrlClient:         config.CtrlClient,
+		logger:             config.Logger,
+		name:               config.Name,
+		infrastructureGVKs: config.InfrastructureGVKs,
 	}
 
 	internalHandlerConfig := internal.HandlerConfig{
@@ -42,6 +59,9 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 		Logger:            config.Logger,
 		UpdateStatus:      config.UpdateStatus,
 		ConditionType:     capi.InfrastructureReadyCondition,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
 		EnsureCreatedFunc: h.ensureCreated,
 	}
 
@@ -71,6 +91,21 @@ func (h *Handler) Name() string {
 	return h.name
 }
 
+// Watches returns provider-specific infrastructure objects as watch sources,
+// since InfrastructureReady is mirrored from their Ready condition. Objects
+// are mapped to the reconciled objects by owner references.
+func (h *Handler) Watches(reconciled schema.GroupVersionKind) []watch.Source {
+	var sources []watch.Source
+	for _, gvk := range h.infrastructureGVKs {
+		sources = append(sources, watch.Source{
+			GVK:     gvk,
+			MapFunc: watch.ToOwner(reconciled),
+		})
+	}
+
+	return sources
+}
+
 func (h *Handler) ensureCreated(ctx context.Context, object conditions.Object) error {
 	obj, err := toObjectWithInfrastructure(object)
 	if err != nil {
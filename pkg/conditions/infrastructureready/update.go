@@ -6,11 +6,10 @@ import (
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
-	capiexternal "sigs.k8s.io/cluster-api/controllers/external"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
 
+	"github.com/giantswarm/conditions-handler/pkg/cache"
 	"github.com/giantswarm/conditions-handler/pkg/errors"
 )
 
@@ -27,6 +26,9 @@ const (
 // If specified infrastructure object's Ready condition is not set, object
 // InfrastructureReady will be set with condition False and reason
 // WaitingForInfrastructure.
+//
+// Other errors returned while getting the object, like timeouts, are
+// returned, so that the object is reconciled again.
 func (h *Handler) update(ctx context.Context, object objectWithInfrastructureRef) error {
 	// We need to remove already deprecated ProviderInfrastructureReady condition
 	// from clusters that are already upgraded to node pools release, as we are
@@ -55,7 +57,7 @@ func (h *Handler) update(ctx context.Context, object objectWithInfrastructureRef
 	}
 
 	infrastructureObject, err := h.getInfrastructureObject(ctx, object)
-	if errors.IsFailedToRetrieveExternalObject(err) || apierrors.IsNotFound(err) {
+	if errors.IsExternalObjectNotFound(err) {
 		warningMessage :=
 			"Corresponding provider-specific infrastructure object '%s/%s' " +
 				"is not found for %s object '%s/%s'"
@@ -119,7 +121,7 @@ func (h *Handler) getInfrastructureObject(ctx context.Context, object objectWith
 		return nil, nil
 	}
 
-	infrastructureObject, err := capiexternal.Get(ctx, h.ctrlClient, object.GetInfrastructureRef(), object.GetNamespace())
+	infrastructureObject, err := cache.GetExternal(ctx, h.ctrlClient, object.GetInfrastructureRef(), object.GetNamespace())
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
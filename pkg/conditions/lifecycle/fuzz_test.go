@@ -0,0 +1,46 @@
+//go:build go1.18
+// +build go1.18
+
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+)
+
+// FuzzLifecycle checks lifecycle invariants over sequences of steps decoded
+// from fuzzer input, one step per byte. Minimized failing inputs are written
+// by the fuzzer to testdata/fuzz/FuzzLifecycle.
+func FuzzLifecycle(f *testing.F) {
+	// Steps are encoded as operation index plus 6 times version index, see
+	// decodeSteps.
+	// New Cluster is created, upgraded and rolled back.
+	f.Add([]byte{5, 1, 6, 1, 0, 1})
+	// Cluster is restored from backup during an upgrade.
+	f.Add([]byte{1, 6, 4, 3, 5, 1})
+	// Last deployed release version is set to unexpected versions.
+	f.Add([]byte{8, 14, 3, 2, 1, 4})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		postCheck := conditionstest.NewPostCheck(t)
+
+		steps := decodeSteps(data)
+		err := run(steps, postCheck)
+		if err != nil {
+			t.Fatalf("%s\nsequence %s", err, sprintSteps(steps))
+		}
+	})
+}
+
+// decodeSteps decodes one step from each byte, where the remainder of the
+// division with the number of operations selects the operation and the
+// quotient selects the version.
+func decodeSteps(data []byte) []step {
+	steps := make([]step, 0, len(data))
+	for _, b := range data {
+		steps = append(steps, newStep(int(b)%len(ops), int(b)/len(ops)%len(versions)))
+	}
+
+	return steps
+}
@@ -0,0 +1,329 @@
+// Package lifecycle contains property-based tests of Creating and Upgrading
+// conditions, which check lifecycle invariants over random sequences of
+// release version label and annotation changes.
+package lifecycle
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/micrologger"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/creating"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/upgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+var (
+	sequences = flag.Int("lifecycle.sequences", 500, "number of random sequences checked by lifecycle property tests")
+	seed      = flag.Int64("lifecycle.seed", 1, "seed of the first random sequence checked by lifecycle property tests")
+)
+
+const (
+	releaseVersionLabel = "release.giantswarm.io/version"
+
+	// opSetDesired changes desired release version label.
+	opSetDesired = "setDesired"
+	// opDeploy sets last deployed release version annotation to the desired
+	// release version, like the operator does after a successful deployment.
+	opDeploy = "deploy"
+	// opDeployVersion sets last deployed release version annotation to the
+	// specified version.
+	opDeployVersion = "deployVersion"
+	// opRemoveLastDeployed removes last deployed release version annotation.
+	opRemoveLastDeployed = "removeLastDeployed"
+	// opRestoreWithoutStatus removes all conditions, like restoring the
+	// object from a backup without its status.
+	opRestoreWithoutStatus = "restoreWithoutStatus"
+	// opReconcile does not change the object.
+	opReconcile = "reconcile"
+)
+
+var (
+	ops      = []string{opSetDesired, opDeploy, opDeployVersion, opRemoveLastDeployed, opRestoreWithoutStatus, opReconcile}
+	versions = []string{"1.0.0", "1.1.0", "2.0.0"}
+)
+
+// step changes the Cluster, after which Creating and Upgrading handlers
+// reconcile it.
+type step struct {
+	Op      string `json:"op"`
+	Version string `json:"version,omitempty"`
+}
+
+func (s step) String() string {
+	if s.Version == "" {
+		return s.Op
+	}
+
+	return fmt.Sprintf("%s(%s)", s.Op, s.Version)
+}
+
+// sequence is a regression sequence stored in testdata/sequences.
+type sequence struct {
+	Description string `json:"description,omitempty"`
+	Steps       []step `json:"steps"`
+}
+
+// TestLifecycleInvariants checks lifecycle invariants over random sequences
+// of steps. Failing sequences are shrunk and written to testdata/sequences,
+// so that they are replayed by TestLifecycleRegressions.
+func TestLifecycleInvariants(t *testing.T) {
+	postCheck := conditionstest.NewPostCheck(t)
+
+	for i := 0; i < *sequences; i++ {
+		sequenceSeed := *seed + int64(i)
+		steps := randomSteps(rand.New(rand.NewSource(sequenceSeed))) // nolint:gosec
+
+		err := run(steps, postCheck)
+		if err == nil {
+			continue
+		}
+
+		shrunk := shrink(steps, postCheck)
+		path := filepath.Join("testdata", "sequences", fmt.Sprintf("failing-seed-%d.yaml", sequenceSeed))
+		writeErr := writeSequence(path, sequence{
+			Description: fmt.Sprintf("Shrunk sequence generated with seed %d: %s", sequenceSeed, run(shrunk, postCheck)),
+			Steps:       shrunk,
+		})
+		if writeErr != nil {
+			t.Error(writeErr)
+		}
+
+		t.Fatalf("seed %d: %s\nshrunk sequence %s written to %s", sequenceSeed, run(shrunk, postCheck), sprintSteps(shrunk), path)
+	}
+}
+
+// TestLifecycleRegressions replays sequences from testdata/sequences.
+func TestLifecycleRegressions(t *testing.T) {
+	postCheck := conditionstest.NewPostCheck(t)
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "sequences", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var s sequence
+			err = yaml.UnmarshalStrict(data, &s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Log(s.Description)
+
+			err = run(s.Steps, postCheck)
+			if err != nil {
+				t.Fatalf("%s\nsequence %s", err, sprintSteps(s.Steps))
+			}
+		})
+	}
+}
+
+func randomSteps(r *rand.Rand) []step {
+	steps := make([]step, 1+r.Intn(30))
+	for i := range steps {
+		steps[i] = newStep(r.Intn(len(ops)), r.Intn(len(versions)))
+	}
+
+	return steps
+}
+
+func newStep(op, version int) step {
+	s := step{Op: ops[op]}
+	if s.Op == opSetDesired || s.Op == opDeployVersion {
+		s.Version = versions[version]
+	}
+
+	return s
+}
+
+// shrink removes steps from the failing sequence, as long as the sequence
+// still fails.
+func shrink(steps []step, postCheck handler.PostCheckFunc) []step {
+	for i := 0; i < len(steps); {
+		candidate := append(append([]step{}, steps[:i]...), steps[i+1:]...)
+		if run(candidate, postCheck) != nil {
+			steps = candidate
+			continue
+		}
+		i++
+	}
+
+	return steps
+}
+
+// run applies the steps to a new Cluster, reconciles Creating and Upgrading
+// conditions, checked with the specified post-check, after every step, and
+// returns an error describing the first violated invariant.
+func run(steps []step, postCheck handler.PostCheckFunc) error {
+	ctx := context.Background()
+
+	logger, err := micrologger.New(micrologger.Config{IOWriter: io.Discard})
+	if err != nil {
+		return err
+	}
+	client := conditionstest.NewFakeClient()
+
+	creatingHandler, err := creating.NewHandler(creating.HandlerConfig{
+		CtrlClient: client,
+		Logger:     logger,
+		Name:       "creating",
+		PostCheck:  postCheck,
+	})
+	if err != nil {
+		return err
+	}
+	upgradingHandler, err := upgrading.NewHandler(upgrading.HandlerConfig{
+		CtrlClient: client,
+		Logger:     logger,
+		Name:       "upgrading",
+		PostCheck:  postCheck,
+	})
+	if err != nil {
+		return err
+	}
+
+	cluster := conditionstest.NewCluster("org-test", "test1").
+		WithReleaseVersion(versions[0]).
+		Build()
+	creationCompleted := false
+
+	for i, s := range steps {
+		apply(cluster, s)
+		if s.Op == opRestoreWithoutStatus {
+			creationCompleted = false
+		}
+
+		before := cluster.DeepCopy()
+		err = creatingHandler.EnsureCreated(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		err = upgradingHandler.EnsureCreated(ctx, cluster)
+		if err != nil {
+			return err
+		}
+
+		err = checkInvariants(before, cluster, creationCompleted)
+		if err != nil {
+			return fmt.Errorf("step %d %s: %s", i, s, err)
+		}
+
+		if conditions.IsCreatingFalse(cluster) {
+			creationCompleted = true
+		}
+	}
+
+	return nil
+}
+
+func apply(cluster *capi.Cluster, s step) {
+	switch s.Op {
+	case opSetDesired:
+		cluster.Labels[releaseVersionLabel] = s.Version
+	case opDeploy:
+		setLastDeployed(cluster, cluster.Labels[releaseVersionLabel])
+	case opDeployVersion:
+		setLastDeployed(cluster, s.Version)
+	case opRemoveLastDeployed:
+		delete(cluster.Annotations, internal.LastDeployedReleaseVersion)
+	case opRestoreWithoutStatus:
+		cluster.Status.Conditions = nil
+	}
+}
+
+func setLastDeployed(cluster *capi.Cluster, version string) {
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[internal.LastDeployedReleaseVersion] = version
+}
+
+// checkInvariants checks lifecycle invariants of the Cluster after one
+// reconciliation.
+func checkInvariants(before, after *capi.Cluster, creationCompleted bool) error {
+	lastDeployed, isLastDeployedSet := after.Annotations[internal.LastDeployedReleaseVersion]
+	deployed := isLastDeployedSet && lastDeployed == after.Labels[releaseVersionLabel]
+
+	// Creating never returns to True once it is False, unless the object is
+	// restored without its status.
+	if creationCompleted && !conditions.IsCreatingFalse(after) {
+		return fmt.Errorf("Creating is %s after it was False", sprintCondition(after, conditions.Creating))
+	}
+
+	// Upgrading is False whenever Creating is True.
+	if conditions.IsCreatingTrue(after) && !conditions.IsUpgradingFalse(after) {
+		return fmt.Errorf("Upgrading is %s while Creating is True", sprintCondition(after, conditions.Upgrading))
+	}
+
+	// Creation completes iff last deployed release version equals desired
+	// release version.
+	if conditions.IsCreatingTrue(before) {
+		completed := hasReason(after, conditions.Creating, conditions.CreationCompletedReason)
+		if completed != deployed {
+			return fmt.Errorf("Creating is %s, while desired release version deployed is %t", sprintCondition(after, conditions.Creating), deployed)
+		}
+	}
+
+	// Upgrade completes iff last deployed release version equals desired
+	// release version.
+	if conditions.IsUpgradingTrue(before) {
+		completed := hasReason(after, conditions.Upgrading, conditions.UpgradeCompletedReason)
+		if completed != deployed {
+			return fmt.Errorf("Upgrading is %s, while desired release version deployed is %t", sprintCondition(after, conditions.Upgrading), deployed)
+		}
+	}
+
+	// Upgrade never starts when desired release version is deployed.
+	if !conditions.IsUpgradingTrue(before) && conditions.IsUpgradingTrue(after) && deployed {
+		return fmt.Errorf("Upgrading is %s, while desired release version is deployed", sprintCondition(after, conditions.Upgrading))
+	}
+
+	return nil
+}
+
+func hasReason(object *capi.Cluster, conditionType capi.ConditionType, reason string) bool {
+	condition := capiconditions.Get(object, conditionType)
+	return condition != nil && condition.Reason == reason
+}
+
+func sprintCondition(object *capi.Cluster, conditionType capi.ConditionType) string {
+	return internal.SprintComparedCondition(capiconditions.Get(object, conditionType))
+}
+
+func sprintSteps(steps []step) string {
+	var s []string
+	for _, step := range steps {
+		s = append(s, step.String())
+	}
+
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+func writeSequence(path string, s sequence) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644) // nolint:gosec
+}
@@ -0,0 +1,181 @@
+package nodepoolsready
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/cache"
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+// Discovery finds node pools that belong to the specified Cluster.
+type Discovery interface {
+	// Discover returns MachinePools of the specified Cluster.
+	Discover(ctx context.Context, cluster *capi.Cluster) ([]capiexp.MachinePool, error)
+}
+
+type DiscoveryConfig struct {
+	CtrlClient ctrl.Client
+}
+
+// LabelDiscovery finds MachinePools that have cluster.x-k8s.io/cluster-name
+// label set to the value of the same Cluster label. The Cluster must have
+// the label set.
+type LabelDiscovery struct {
+	ctrlClient ctrl.Client
+}
+
+func NewLabelDiscovery(config DiscoveryConfig) (*LabelDiscovery, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+
+	d := &LabelDiscovery{
+		ctrlClient: config.CtrlClient,
+	}
+
+	return d, nil
+}
+
+func (d *LabelDiscovery) Discover(ctx context.Context, cluster *capi.Cluster) ([]capiexp.MachinePool, error) {
+	machinePools, err := internal.ListMachinePoolsByMetadata(ctx, d.ctrlClient, cluster.ObjectMeta)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return machinePools.Items, nil
+}
+
+// ClusterNameDiscovery finds MachinePools in the Cluster namespace whose
+// Spec.ClusterName is equal to the Cluster name.
+type ClusterNameDiscovery struct {
+	ctrlClient ctrl.Client
+}
+
+func NewClusterNameDiscovery(config DiscoveryConfig) (*ClusterNameDiscovery, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+
+	d := &ClusterNameDiscovery{
+		ctrlClient: config.CtrlClient,
+	}
+
+	return d, nil
+}
+
+func (d *ClusterNameDiscovery) Discover(ctx context.Context, cluster *capi.Cluster) ([]capiexp.MachinePool, error) {
+	machinePools := &capiexp.MachinePoolList{}
+	err := cache.List(ctx, d.ctrlClient, machinePools, ctrl.InNamespace(cluster.Namespace))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var found []capiexp.MachinePool
+	for _, machinePool := range machinePools.Items {
+		if machinePool.Spec.ClusterName == cluster.Name {
+			found = append(found, machinePool)
+		}
+	}
+
+	return found, nil
+}
+
+// OwnerReferenceDiscovery finds MachinePools in the Cluster namespace that
+// are owned by the Cluster.
+type OwnerReferenceDiscovery struct {
+	ctrlClient ctrl.Client
+}
+
+func NewOwnerReferenceDiscovery(config DiscoveryConfig) (*OwnerReferenceDiscovery, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+
+	d := &OwnerReferenceDiscovery{
+		ctrlClient: config.CtrlClient,
+	}
+
+	return d, nil
+}
+
+func (d *OwnerReferenceDiscovery) Discover(ctx context.Context, cluster *capi.Cluster) ([]capiexp.MachinePool, error) {
+	machinePools := &capiexp.MachinePoolList{}
+	err := cache.List(ctx, d.ctrlClient, machinePools, ctrl.InNamespace(cluster.Namespace))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var found []capiexp.MachinePool
+	for _, machinePool := range machinePools.Items {
+		if isOwnedByCluster(machinePool, cluster) {
+			found = append(found, machinePool)
+		}
+	}
+
+	return found, nil
+}
+
+func isOwnedByCluster(machinePool capiexp.MachinePool, cluster *capi.Cluster) bool {
+	for _, ownerReference := range machinePool.GetOwnerReferences() {
+		if ownerReference.Kind != "Cluster" || ownerReference.Name != cluster.Name {
+			continue
+		}
+		if cluster.UID != "" && ownerReference.UID != cluster.UID {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// CombinedDiscovery finds MachinePools with all specified discoveries and
+// returns every found MachinePool once. Discoveries that cannot be used for
+// the Cluster, e.g. LabelDiscovery for a Cluster without cluster name label,
+// are skipped.
+type CombinedDiscovery struct {
+	discoveries []Discovery
+}
+
+func NewCombinedDiscovery(discoveries ...Discovery) (*CombinedDiscovery, error) {
+	if len(discoveries) == 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "at least one discovery must be specified")
+	}
+
+	d := &CombinedDiscovery{
+		discoveries: discoveries,
+	}
+
+	return d, nil
+}
+
+func (d *CombinedDiscovery) Discover(ctx context.Context, cluster *capi.Cluster) ([]capiexp.MachinePool, error) {
+	var found []capiexp.MachinePool
+	seen := map[string]bool{}
+
+	for _, discovery := range d.discoveries {
+		machinePools, err := discovery.Discover(ctx, cluster)
+		if errors.IsInvalidConfig(err) {
+			continue
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, machinePool := range machinePools {
+			if seen[machinePool.Name] {
+				continue
+			}
+			seen[machinePool.Name] = true
+			found = append(found, machinePool)
+		}
+	}
+
+	return found, nil
+}
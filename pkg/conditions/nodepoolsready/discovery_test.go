@@ -0,0 +1,163 @@
+package nodepoolsready
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestDiscover(t *testing.T) {
+	testCases := []struct {
+		name              string
+		newDiscovery      func(c ctrl.Client) (Discovery, error)
+		clusterLabels     map[string]string
+		expectedNodePools string
+		expectedErr       func(error) bool
+	}{
+		{
+			name: "case 0: LabelDiscovery finds node pools by cluster name label",
+			newDiscovery: func(c ctrl.Client) (Discovery, error) {
+				return NewLabelDiscovery(DiscoveryConfig{CtrlClient: c})
+			},
+			clusterLabels:     map[string]string{capi.ClusterLabelName: "test1"},
+			expectedNodePools: "np1,np4",
+		},
+		{
+			name: "case 1: LabelDiscovery fails for Cluster without cluster name label",
+			newDiscovery: func(c ctrl.Client) (Discovery, error) {
+				return NewLabelDiscovery(DiscoveryConfig{CtrlClient: c})
+			},
+			expectedErr: errors.IsInvalidConfig,
+		},
+		{
+			name: "case 2: ClusterNameDiscovery finds node pools by Spec.ClusterName",
+			newDiscovery: func(c ctrl.Client) (Discovery, error) {
+				return NewClusterNameDiscovery(DiscoveryConfig{CtrlClient: c})
+			},
+			expectedNodePools: "np1,np2",
+		},
+		{
+			name: "case 3: OwnerReferenceDiscovery finds node pools owned by the Cluster",
+			newDiscovery: func(c ctrl.Client) (Discovery, error) {
+				return NewOwnerReferenceDiscovery(DiscoveryConfig{CtrlClient: c})
+			},
+			expectedNodePools: "np3",
+		},
+		{
+			name: "case 4: CombinedDiscovery skips LabelDiscovery for Cluster without cluster name label",
+			newDiscovery: func(c ctrl.Client) (Discovery, error) {
+				labelDiscovery, _ := NewLabelDiscovery(DiscoveryConfig{CtrlClient: c})
+				clusterNameDiscovery, _ := NewClusterNameDiscovery(DiscoveryConfig{CtrlClient: c})
+				ownerReferenceDiscovery, _ := NewOwnerReferenceDiscovery(DiscoveryConfig{CtrlClient: c})
+				return NewCombinedDiscovery(labelDiscovery, clusterNameDiscovery, ownerReferenceDiscovery)
+			},
+			expectedNodePools: "np1,np2,np3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			ctx := context.Background()
+			client := internal.NewFakeClient(capi.AddToScheme, capiexp.AddToScheme)
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-test",
+					Name:      "test1",
+					UID:       types.UID("test1-uid"),
+					Labels:    tc.clusterLabels,
+				},
+			}
+			for _, machinePool := range newDiscoveredMachinePools() {
+				err := client.Create(ctx, machinePool)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			discovery, err := tc.newDiscovery(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			machinePools, err := discovery.Discover(ctx, cluster)
+
+			// assert
+			if tc.expectedErr != nil {
+				if !tc.expectedErr(err) {
+					t.Fatalf("expected matching error, got %#v", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, machinePool := range machinePools {
+				names = append(names, machinePool.Name)
+			}
+			sort.Strings(names)
+
+			if strings.Join(names, ",") != tc.expectedNodePools {
+				t.Fatalf("expected node pools %q, got %q", tc.expectedNodePools, strings.Join(names, ","))
+			}
+		})
+	}
+}
+
+func newDiscoveredMachinePools() []*capiexp.MachinePool {
+	return []*capiexp.MachinePool{
+		{
+			// Found by label and by Spec.ClusterName.
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "org-test",
+				Name:      "np1",
+				Labels:    map[string]string{capi.ClusterLabelName: "test1"},
+			},
+			Spec: capiexp.MachinePoolSpec{ClusterName: "test1"},
+		},
+		{
+			// Found by Spec.ClusterName only.
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "org-test",
+				Name:      "np2",
+			},
+			Spec: capiexp.MachinePoolSpec{ClusterName: "test1"},
+		},
+		{
+			// Found by owner reference only.
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "org-test",
+				Name:      "np3",
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: capi.GroupVersion.String(),
+						Kind:       "Cluster",
+						Name:       "test1",
+						UID:        types.UID("test1-uid"),
+					},
+				},
+			},
+		},
+		{
+			// Found by label, but Spec.ClusterName is inconsistent.
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "org-test",
+				Name:      "np4",
+				Labels:    map[string]string{capi.ClusterLabelName: "test1"},
+			},
+			Spec: capiexp.MachinePoolSpec{ClusterName: "test2"},
+		},
+	}
+}
@@ -2,17 +2,25 @@ package nodepoolsready
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/damping"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
 	"github.com/giantswarm/conditions-handler/pkg/internal"
 	"github.com/giantswarm/conditions-handler/pkg/key"
+	"github.com/giantswarm/conditions-handler/pkg/watch"
 )
 
 type HandlerConfig struct {
@@ -21,6 +29,36 @@ type HandlerConfig struct {
 
 	Name         string
 	UpdateStatus bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
+
+	// Discovery finds Cluster's node pools. Defaults to LabelDiscovery.
+	Discovery Discovery
+
+	// NodePoolConditionType is the MachinePool condition that is aggregated,
+	// e.g. ReplicasReady or InfrastructureReady. Defaults to Ready.
+	NodePoolConditionType capi.ConditionType
+	// DisableStepCounter disables "x of y completed" message of the
+	// aggregated condition, so the message of the first node pool is used.
+	DisableStepCounter bool
+	// MaxMessages is the maximum number of not ready node pools whose
+	// messages are appended to the aggregated condition message. Zero value
+	// disables appending messages.
+	MaxMessages int
+	// NoNodePools defines how the condition is set when the Cluster does not
+	// have any node pools. Defaults to NoNodePoolsInfo.
+	NoNodePools NoNodePoolsPolicy
+	// SkipDeleting excludes node pools that are being deleted.
+	SkipDeleting bool
+	// SkipPaused excludes paused node pools.
+	SkipPaused bool
+
+	// Damping defines flap damping of the condition. By default every
+	// condition change is published immediately.
+	Damping damping.Config
 }
 
 type Handler struct {
@@ -28,13 +66,30 @@ type Handler struct {
 	internalHandler *internal.Handler
 	logger          micrologger.Logger
 	name            string
+	discovery       Discovery
+	options         aggregationOptions
 }
 
 func NewHandler(config HandlerConfig) (*Handler, error) {
+	options, err := newAggregationOptions(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	discovery := config.Discovery
+	if discovery == nil {
+		discovery, err = NewLabelDiscovery(DiscoveryConfig{CtrlClient: config.CtrlClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	h := &Handler{
 		ctrlClient: config.CtrlClient,
 		logger:     config.Logger,
 		name:       config.Name,
+		discovery:  discovery,
+		options:    options,
 	}
 
 	internalHandlerConfig := internal.HandlerConfig{
@@ -42,6 +97,10 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 		Logger:            config.Logger,
 		UpdateStatus:      config.UpdateStatus,
 		ConditionType:     conditions.NodePoolsReady,
+		Damping:           config.Damping,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
 		EnsureCreatedFunc: h.ensureCreated,
 	}
 
@@ -71,6 +130,21 @@ func (h *Handler) Name() string {
 	return h.name
 }
 
+// Watches returns MachinePools as a watch source for Clusters, since
+// NodePoolsReady is computed from MachinePool conditions.
+func (h *Handler) Watches(reconciled schema.GroupVersionKind) []watch.Source {
+	if reconciled.GroupKind() != watch.ClusterGVK.GroupKind() {
+		return nil
+	}
+
+	return []watch.Source{
+		{
+			GVK:     watch.MachinePoolGVK,
+			MapFunc: watch.MachinePoolToCluster,
+		},
+	}
+}
+
 func (h *Handler) ensureCreated(ctx context.Context, object conditions.Object) error {
 	cluster, err := key.ToClusterPointer(object)
 	if err != nil {
@@ -82,13 +156,13 @@ func (h *Handler) ensureCreated(ctx context.Context, object conditions.Object) e
 		return microerror.Mask(err)
 	}
 
-	update(cluster, nodePools)
+	update(cluster, nodePools, h.options)
 	return nil
 }
 
 func (h *Handler) getNodePools(ctx context.Context, cluster *capi.Cluster) ([]capiconditions.Getter, error) {
-	machinePools, err := internal.ListMachinePoolsByMetadata(ctx, h.ctrlClient, cluster.ObjectMeta)
-	if apierrors.IsNotFound(err) || (err != nil && machinePools != nil && len(machinePools.Items) == 0) {
+	machinePools, err := h.discovery.Discover(ctx, cluster)
+	if apierrors.IsNotFound(err) {
 		// not finding any node pools can be a valid scenario
 		return nil, nil
 	} else if err != nil {
@@ -97,10 +171,32 @@ func (h *Handler) getNodePools(ctx context.Context, cluster *capi.Cluster) ([]ca
 
 	// We need a slice of Getter objects for SetAggregate.
 	var machinePoolPointers []capiconditions.Getter
-	for _, machinePool := range machinePools.Items {
+	for _, machinePool := range machinePools {
 		machinePoolObj := machinePool
+		h.warnIfClusterNameIsInconsistent(ctx, &machinePoolObj)
 		machinePoolPointers = append(machinePoolPointers, &machinePoolObj)
 	}
 
 	return machinePoolPointers, nil
 }
+
+// warnIfClusterNameIsInconsistent logs a warning when MachinePool cluster
+// name label and Spec.ClusterName are both set and they are not equal, since
+// different discoveries would then find different node pools.
+func (h *Handler) warnIfClusterNameIsInconsistent(ctx context.Context, machinePool *capiexp.MachinePool) {
+	clusterNameLabel := machinePool.GetLabels()[capi.ClusterLabelName]
+	if clusterNameLabel == "" || machinePool.Spec.ClusterName == "" || clusterNameLabel == machinePool.Spec.ClusterName {
+		return
+	}
+
+	h.logger.LogCtx(
+		ctx,
+		"level", "warning",
+		"message", fmt.Sprintf(
+			"MachinePool %s/%s has label %s=%q that is different from Spec.ClusterName %q",
+			machinePool.Namespace,
+			machinePool.Name,
+			capi.ClusterLabelName,
+			clusterNameLabel,
+			machinePool.Spec.ClusterName))
+}
@@ -0,0 +1,91 @@
+package nodepoolsready
+
+import (
+	"github.com/giantswarm/microerror"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiannotations "sigs.k8s.io/cluster-api/util/annotations"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+// NoNodePoolsPolicy defines how NodePoolsReady is set when the Cluster does
+// not have any node pools.
+type NoNodePoolsPolicy string
+
+const (
+	// NoNodePoolsInfo sets NodePoolsReady with status False, reason
+	// NodePoolObjectsNotFound and severity Info. This is the default.
+	NoNodePoolsInfo NoNodePoolsPolicy = ""
+
+	// NoNodePoolsWarning sets NodePoolsReady with status False, reason
+	// NodePoolObjectsNotFound and severity Warning.
+	NoNodePoolsWarning NoNodePoolsPolicy = "Warning"
+
+	// NoNodePoolsTrue sets NodePoolsReady with status True.
+	NoNodePoolsTrue NoNodePoolsPolicy = "True"
+)
+
+// aggregationOptions define how node pool conditions are aggregated into
+// Cluster NodePoolsReady condition.
+type aggregationOptions struct {
+	conditionType      capi.ConditionType
+	disableStepCounter bool
+	maxMessages        int
+	noNodePools        NoNodePoolsPolicy
+	skipDeleting       bool
+	skipPaused         bool
+}
+
+func newAggregationOptions(config HandlerConfig) (aggregationOptions, error) {
+	if config.MaxMessages < 0 {
+		return aggregationOptions{}, microerror.Maskf(errors.InvalidConfigError, "%T.MaxMessages must not be negative", config)
+	}
+
+	switch config.NoNodePools {
+	case NoNodePoolsInfo, NoNodePoolsWarning, NoNodePoolsTrue:
+	default:
+		return aggregationOptions{}, microerror.Maskf(errors.InvalidConfigError, "%T.NoNodePools has unknown value %q", config, config.NoNodePools)
+	}
+
+	conditionType := config.NodePoolConditionType
+	if conditionType == "" {
+		conditionType = capi.ReadyCondition
+	}
+
+	o := aggregationOptions{
+		conditionType:      conditionType,
+		disableStepCounter: config.DisableStepCounter,
+		maxMessages:        config.MaxMessages,
+		noNodePools:        config.NoNodePools,
+		skipDeleting:       config.SkipDeleting,
+		skipPaused:         config.SkipPaused,
+	}
+
+	return o, nil
+}
+
+// filter returns node pools that should be aggregated.
+func (o aggregationOptions) filter(nodePools []capiconditions.Getter) []capiconditions.Getter {
+	var filtered []capiconditions.Getter
+	for _, nodePool := range nodePools {
+		if o.skipDeleting && nodePool.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if o.skipPaused && capiannotations.HasPausedAnnotation(nodePool) {
+			continue
+		}
+
+		filtered = append(filtered, nodePool)
+	}
+
+	return filtered
+}
+
+// mergeOptions returns options for capiconditions.SetAggregate.
+func (o aggregationOptions) mergeOptions() []capiconditions.MergeOption {
+	return []capiconditions.MergeOption{
+		capiconditions.WithStepCounterIf(!o.disableStepCounter),
+		capiconditions.AddSourceRef(),
+	}
+}
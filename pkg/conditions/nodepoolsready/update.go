@@ -1,32 +1,108 @@
 package nodepoolsready
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
 )
 
-// update sets NodePoolsReady condition on specified cluster
-// by aggregating Ready conditions from specified node pool objects.
+// update sets NodePoolsReady condition on specified cluster by aggregating
+// conditions from specified node pool objects. By default Ready conditions
+// are aggregated, with step counter and source reference.
 //
-// If node pool objects are found, cluster NodePoolsReady is set to with status
-// False and reason NodePoolsNotFoundReason.
-func update(cluster *capi.Cluster, nodePools []capiconditions.Getter) {
+// If node pool objects are not found, cluster NodePoolsReady is set according
+// to the NoNodePools policy, by default with status False, reason
+// NodePoolsNotFoundReason and severity Info.
+func update(cluster *capi.Cluster, nodePools []capiconditions.Getter, options aggregationOptions) {
+	nodePools = options.filter(nodePools)
+
 	if len(nodePools) == 0 {
+		updateWithoutNodePools(cluster, options.noNodePools)
+		return
+	}
+
+	// SetAggregate always aggregates Ready conditions, so other node pool
+	// conditions are exposed as Ready.
+	var getters []capiconditions.Getter
+	for _, nodePool := range nodePools {
+		if options.conditionType == capi.ReadyCondition {
+			getters = append(getters, nodePool)
+		} else {
+			getters = append(getters, internal.NewReadyConditionGetter(nodePool, options.conditionType, nil))
+		}
+	}
+
+	nodePoolsReady := internal.Aggregate(getters, conditions.NodePoolsReady, options.mergeOptions()...)
+	if nodePoolsReady == nil {
+		// None of the node pools has the aggregated condition set.
+		return
+	}
+
+	if options.maxMessages > 0 && nodePoolsReady.Status != corev1.ConditionTrue {
+		nodePoolsReady.Message = appendNodePoolMessages(nodePoolsReady.Message, nodePools, options)
+	}
+
+	capiconditions.Set(cluster, nodePoolsReady)
+}
+
+func updateWithoutNodePools(cluster *capi.Cluster, noNodePools NoNodePoolsPolicy) {
+	switch noNodePools {
+	case NoNodePoolsTrue:
+		capiconditions.Set(cluster, &capi.Condition{
+			Type:    conditions.NodePoolsReady,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf("Node pools are not found for Cluster %s/%s", cluster.Namespace, cluster.Name),
+		})
+	default:
+		severity := capi.ConditionSeverityInfo
+		if noNodePools == NoNodePoolsWarning {
+			severity = capi.ConditionSeverityWarning
+		}
+
 		capiconditions.MarkFalse(
 			cluster,
 			conditions.NodePoolsReady,
 			conditions.NodePoolsNotFoundReason,
-			capi.ConditionSeverityInfo,
+			severity,
 			"Node pools are not found for Cluster %s/%s",
 			cluster.Namespace, cluster.Name)
-		return
+	}
+}
+
+// appendNodePoolMessages appends messages of up to maxMessages node pools
+// whose aggregated condition is not True to the specified message.
+func appendNodePoolMessages(message string, nodePools []capiconditions.Getter, options aggregationOptions) string {
+	var nodePoolMessages []string
+	var notReady int
+	for _, nodePool := range nodePools {
+		condition := capiconditions.Get(nodePool, options.conditionType)
+		if condition == nil || condition.Status == corev1.ConditionTrue {
+			continue
+		}
+
+		notReady++
+		if len(nodePoolMessages) < options.maxMessages {
+			nodePoolMessages = append(nodePoolMessages, fmt.Sprintf("%s: %s", nodePool.GetName(), condition.Message))
+		}
+	}
+
+	if len(nodePoolMessages) == 0 {
+		return message
+	}
+
+	text := strings.Join(nodePoolMessages, "; ")
+	if notReady > len(nodePoolMessages) {
+		text = fmt.Sprintf("%s; and %d more", text, notReady-len(nodePoolMessages))
+	}
+	if message == "" {
+		return text
 	}
 
-	capiconditions.SetAggregate(
-		cluster,
-		conditions.NodePoolsReady,
-		nodePools,
-		capiconditions.WithStepCounter(),
-		capiconditions.AddSourceRef())
+	return fmt.Sprintf("%s; %s", message, text)
 }
@@ -1 +1,182 @@
 package nodepoolsready
+
+import (
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestUpdate(t *testing.T) {
+	testCases := []struct {
+		name              string
+		config            HandlerConfig
+		nodePools         []capiconditions.Getter
+		expectedCondition capi.Condition
+	}{
+		{
+			name: "case 0: Cluster without node pools",
+			expectedCondition: capi.Condition{
+				Type:     conditions.NodePoolsReady,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   conditions.NodePoolsNotFoundReason,
+				Message:  "Node pools are not found for Cluster org-test/test1",
+			},
+		},
+		{
+			name:   "case 1: Cluster without node pools and NoNodePoolsWarning policy",
+			config: HandlerConfig{NoNodePools: NoNodePoolsWarning},
+			expectedCondition: capi.Condition{
+				Type:     conditions.NodePoolsReady,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   conditions.NodePoolsNotFoundReason,
+				Message:  "Node pools are not found for Cluster org-test/test1",
+			},
+		},
+		{
+			name:   "case 2: Cluster without node pools and NoNodePoolsTrue policy",
+			config: HandlerConfig{NoNodePools: NoNodePoolsTrue},
+			expectedCondition: capi.Condition{
+				Type:    conditions.NodePoolsReady,
+				Status:  corev1.ConditionTrue,
+				Message: "Node pools are not found for Cluster org-test/test1",
+			},
+		},
+		{
+			name: "case 3: Cluster with one of two node pools ready",
+			nodePools: []capiconditions.Getter{
+				newMachinePool("np1", capiconditions.TrueCondition(capi.ReadyCondition)),
+				newMachinePool("np2", notReady(capi.ReadyCondition, "Not ready")),
+			},
+			expectedCondition: capi.Condition{
+				Type:     conditions.NodePoolsReady,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   "NotReady @ MachinePool/np2",
+				Message:  "1 of 2 completed",
+			},
+		},
+		{
+			name:   "case 4: ReplicasReady is aggregated without step counter",
+			config: HandlerConfig{NodePoolConditionType: capiexp.ReplicasReadyCondition, DisableStepCounter: true},
+			nodePools: []capiconditions.Getter{
+				newMachinePool("np1", capiconditions.TrueCondition(capi.ReadyCondition), notReady(capiexp.ReplicasReadyCondition, "1/3 replicas are ready")),
+				newMachinePool("np2", notReady(capi.ReadyCondition, "Not ready"), capiconditions.TrueCondition(capiexp.ReplicasReadyCondition)),
+			},
+			expectedCondition: capi.Condition{
+				Type:     conditions.NodePoolsReady,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   "NotReady @ MachinePool/np1",
+				Message:  "1/3 replicas are ready",
+			},
+		},
+		{
+			name:   "case 5: messages of not ready node pools are appended",
+			config: HandlerConfig{MaxMessages: 1},
+			nodePools: []capiconditions.Getter{
+				newMachinePool("np1", notReady(capi.ReadyCondition, "Scaling")),
+				newMachinePool("np2", notReady(capi.ReadyCondition, "Upgrading")),
+				newMachinePool("np3", capiconditions.TrueCondition(capi.ReadyCondition)),
+			},
+			expectedCondition: capi.Condition{
+				Type:     conditions.NodePoolsReady,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   "NotReady @ MachinePool/np1",
+				Message:  "1 of 3 completed; np1: Scaling; and 1 more",
+			},
+		},
+		{
+			name:   "case 6: deleted and paused node pools are skipped",
+			config: HandlerConfig{SkipDeleting: true, SkipPaused: true},
+			nodePools: []capiconditions.Getter{
+				newDeletedMachinePool("np1", notReady(capi.ReadyCondition, "Deleting")),
+				newPausedMachinePool("np2", notReady(capi.ReadyCondition, "Paused")),
+				newMachinePool("np3", capiconditions.TrueCondition(capi.ReadyCondition)),
+			},
+			expectedCondition: capi.Condition{
+				Type:   conditions.NodePoolsReady,
+				Status: corev1.ConditionTrue,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			options, err := newAggregationOptions(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-test",
+					Name:      "test1",
+				},
+			}
+
+			// act
+			update(cluster, tc.nodePools, options)
+
+			// assert
+			nodePoolsReady := capiconditions.Get(cluster, conditions.NodePoolsReady)
+			if nodePoolsReady == nil {
+				t.Fatalf("NodePoolsReady was not set, expected %s", internal.SprintComparedCondition(&tc.expectedCondition))
+			}
+
+			if !internal.AreEqualWithIgnoringLastTransitionTime(nodePoolsReady, &tc.expectedCondition) {
+				t.Logf(
+					"NodePoolsReady was not set correctly, got %s, expected %s",
+					internal.SprintComparedCondition(nodePoolsReady),
+					internal.SprintComparedCondition(&tc.expectedCondition))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func newMachinePool(name string, conditions ...*capi.Condition) *capiexp.MachinePool {
+	machinePool := &capiexp.MachinePool{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: capiexp.GroupVersion.String(),
+			Kind:       "MachinePool",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-test",
+			Name:      name,
+		},
+	}
+
+	for _, condition := range conditions {
+		capiconditions.Set(machinePool, condition)
+	}
+
+	return machinePool
+}
+
+func newDeletedMachinePool(name string, conditions ...*capi.Condition) *capiexp.MachinePool {
+	machinePool := newMachinePool(name, conditions...)
+	deletionTimestamp := metav1.Now()
+	machinePool.SetDeletionTimestamp(&deletionTimestamp)
+	return machinePool
+}
+
+func newPausedMachinePool(name string, conditions ...*capi.Condition) *capiexp.MachinePool {
+	machinePool := newMachinePool(name, conditions...)
+	machinePool.SetAnnotations(map[string]string{capi.PausedAnnotation: ""})
+	return machinePool
+}
+
+func notReady(conditionType capi.ConditionType, message string) *capi.Condition {
+	return capiconditions.FalseCondition(conditionType, "NotReady", capi.ConditionSeverityWarning, message)
+}
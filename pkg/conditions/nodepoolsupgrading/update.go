@@ -0,0 +1,117 @@
+package nodepoolsupgrading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+const (
+	// NodePoolsUpgrading is a Cluster condition that aggregates Upgrading
+	// conditions of all Cluster's node pools. Like Upgrading, it has negative
+	// polarity, so it is True when at least one node pool is being upgraded.
+	NodePoolsUpgrading capi.ConditionType = "NodePoolsUpgrading"
+
+	// NodePoolUpgradingReason is used when the node pool is being upgraded.
+	NodePoolUpgradingReason = "NodePoolUpgrading"
+
+	// NodePoolsUpgradingUnknownReason is used when none of the node pools has
+	// Upgrading condition set.
+	NodePoolsUpgradingUnknownReason = "NodePoolsUpgradingUnknown"
+)
+
+// update sets NodePoolsUpgrading condition on specified cluster by
+// aggregating Upgrading conditions from specified node pool objects.
+//
+// If node pool objects are not found, cluster NodePoolsUpgrading is set with
+// status False and reason NodePoolsNotFoundReason.
+//
+// If at least one node pool is being upgraded, cluster NodePoolsUpgrading is
+// set with status True and a message with the upgrade progress. Otherwise it
+// is set with status False and reason UpgradeCompleted, or UpgradeNotStarted
+// if none of the node pools has been upgraded yet. Upgrade progress does not
+// change LastTransitionTime of True NodePoolsUpgrading, which is set to the
+// specified time when the first node pool upgrade starts.
+func update(cluster *capi.Cluster, nodePools []capiconditions.Getter, now time.Time) {
+	if len(nodePools) == 0 {
+		capiconditions.MarkFalse(
+			cluster,
+			NodePoolsUpgrading,
+			conditions.NodePoolsNotFoundReason,
+			capi.ConditionSeverityInfo,
+			"Node pools are not found for Cluster %s/%s",
+			cluster.Namespace, cluster.Name)
+		return
+	}
+
+	// Upgrading condition has negative polarity, so here it is inverted to
+	// an upgrade completed condition which can be aggregated in the same way
+	// as Ready conditions are aggregated.
+	var upgradeCompletedGetters []capiconditions.Getter
+	for _, nodePool := range nodePools {
+		upgradeCompletedGetters = append(upgradeCompletedGetters, internal.NewReadyConditionGetter(nodePool, conditions.Upgrading, invertUpgrading))
+	}
+
+	upgradeCompleted := internal.Aggregate(
+		upgradeCompletedGetters,
+		NodePoolsUpgrading,
+		capiconditions.WithStepCounter(),
+		capiconditions.AddSourceRef())
+
+	switch {
+	case conditions.IsUnknown(upgradeCompleted):
+		capiconditions.Set(cluster, &capi.Condition{
+			Type:     NodePoolsUpgrading,
+			Status:   corev1.ConditionUnknown,
+			Reason:   NodePoolsUpgradingUnknownReason,
+			Severity: capi.ConditionSeverityInfo,
+			Message:  fmt.Sprintf("Upgrading condition is not set for node pools of Cluster %s/%s", cluster.Namespace, cluster.Name),
+		})
+	case conditions.IsFalse(upgradeCompleted):
+		internal.SetConditionAt(cluster, &capi.Condition{
+			Type:    NodePoolsUpgrading,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf("Node pools upgrade in progress, %s (%s)", upgradeCompleted.Message, upgradeCompleted.Reason),
+		}, now)
+	default:
+		reason := conditions.UpgradeNotStartedReason
+		for _, nodePool := range nodePools {
+			if capiconditions.GetReason(nodePool, conditions.Upgrading) == conditions.UpgradeCompletedReason {
+				reason = conditions.UpgradeCompletedReason
+				break
+			}
+		}
+
+		capiconditions.MarkFalse(
+			cluster,
+			NodePoolsUpgrading,
+			reason,
+			capi.ConditionSeverityInfo,
+			"None of the %d node pools is being upgraded",
+			len(nodePools))
+	}
+}
+
+// invertUpgrading converts node pool Upgrading condition to a condition with
+// positive polarity, where True means that the node pool is not being
+// upgraded.
+func invertUpgrading(upgrading *capi.Condition) *capi.Condition {
+	switch {
+	case conditions.IsTrue(upgrading):
+		return capiconditions.FalseCondition(
+			capi.ReadyCondition,
+			NodePoolUpgradingReason,
+			capi.ConditionSeverityInfo,
+			"Node pool is being upgraded")
+	case conditions.IsFalse(upgrading):
+		return capiconditions.TrueCondition(capi.ReadyCondition)
+	default:
+		return upgrading
+	}
+}
@@ -0,0 +1,141 @@
+package nodepoolsupgrading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestUpdate(t *testing.T) {
+	testCases := []struct {
+		name              string
+		nodePools         []capiconditions.Getter
+		expectedCondition capi.Condition
+	}{
+		{
+			name: "case 0: Cluster without node pools",
+			expectedCondition: capi.Condition{
+				Type:     NodePoolsUpgrading,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   conditions.NodePoolsNotFoundReason,
+				Message:  "Node pools are not found for Cluster org-test/test1",
+			},
+		},
+		{
+			name: "case 1: node pools without Upgrading condition",
+			nodePools: []capiconditions.Getter{
+				newMachinePool("np1", nil),
+				newMachinePool("np2", nil),
+			},
+			expectedCondition: capi.Condition{
+				Type:     NodePoolsUpgrading,
+				Status:   corev1.ConditionUnknown,
+				Reason:   NodePoolsUpgradingUnknownReason,
+				Severity: capi.ConditionSeverityInfo,
+				Message:  "Upgrading condition is not set for node pools of Cluster org-test/test1",
+			},
+		},
+		{
+			name: "case 2: some node pools are being upgraded",
+			nodePools: []capiconditions.Getter{
+				newMachinePool("np1", upgradingFalse(conditions.UpgradeCompletedReason)),
+				newMachinePool("np2", capiconditions.TrueCondition(conditions.Upgrading)),
+				newMachinePool("np3", upgradingFalse(conditions.UpgradeCompletedReason)),
+			},
+			expectedCondition: capi.Condition{
+				Type:    NodePoolsUpgrading,
+				Status:  corev1.ConditionTrue,
+				Message: "Node pools upgrade in progress, 2 of 3 completed (NodePoolUpgrading @ MachinePool/np2)",
+			},
+		},
+		{
+			name: "case 3: all node pools are upgraded",
+			nodePools: []capiconditions.Getter{
+				newMachinePool("np1", upgradingFalse(conditions.UpgradeCompletedReason)),
+				newMachinePool("np2", upgradingFalse(conditions.UpgradeNotStartedReason)),
+			},
+			expectedCondition: capi.Condition{
+				Type:     NodePoolsUpgrading,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   conditions.UpgradeCompletedReason,
+				Message:  "None of the 2 node pools is being upgraded",
+			},
+		},
+		{
+			name: "case 4: none of the node pools has been upgraded",
+			nodePools: []capiconditions.Getter{
+				newMachinePool("np1", upgradingFalse(conditions.UpgradeNotStartedReason)),
+			},
+			expectedCondition: capi.Condition{
+				Type:     NodePoolsUpgrading,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   conditions.UpgradeNotStartedReason,
+				Message:  "None of the 1 node pools is being upgraded",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-test",
+					Name:      "test1",
+				},
+			}
+
+			// act
+			update(cluster, tc.nodePools, time.Now())
+
+			// assert
+			nodePoolsUpgrading := capiconditions.Get(cluster, NodePoolsUpgrading)
+			if nodePoolsUpgrading == nil {
+				t.Fatalf("NodePoolsUpgrading was not set, expected %s", internal.SprintComparedCondition(&tc.expectedCondition))
+			}
+
+			if !internal.AreEqualWithIgnoringLastTransitionTime(nodePoolsUpgrading, &tc.expectedCondition) {
+				t.Logf(
+					"NodePoolsUpgrading was not set correctly, got %s, expected %s",
+					internal.SprintComparedCondition(nodePoolsUpgrading),
+					internal.SprintComparedCondition(&tc.expectedCondition))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func newMachinePool(name string, upgrading *capi.Condition) *capiexp.MachinePool {
+	machinePool := &capiexp.MachinePool{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: capiexp.GroupVersion.String(),
+			Kind:       "MachinePool",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-test",
+			Name:      name,
+		},
+	}
+
+	if upgrading != nil {
+		capiconditions.Set(machinePool, upgrading)
+	}
+
+	return machinePool
+}
+
+func upgradingFalse(reason string) *capi.Condition {
+	return capiconditions.FalseCondition(conditions.Upgrading, reason, capi.ConditionSeverityInfo, "")
+}
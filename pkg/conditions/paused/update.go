@@ -0,0 +1,65 @@
+package paused
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+const (
+	// Paused is a condition with negative polarity. It is set with status
+	// True when the object or its Cluster is paused, and removed when the
+	// object is not paused anymore.
+	Paused capi.ConditionType = "Paused"
+
+	// PausedBySpecReason is used when the Cluster is paused with
+	// Cluster.Spec.Paused.
+	PausedBySpecReason = "ClusterSpecPaused"
+
+	// PausedByAnnotationReason is used when the object or its Cluster is
+	// paused with cluster.x-k8s.io/paused annotation.
+	PausedByAnnotationReason = "PausedAnnotation"
+)
+
+// MarkPausedTrue sets Paused condition with status True, and with a reason
+// and a message informing which object is paused, how, by whom and since
+// when.
+func MarkPausedTrue(object conditions.Object, pauseInfo internal.PauseInfo) {
+	reason := PausedByAnnotationReason
+	if pauseInfo.PausedBy == internal.PausedBySpec {
+		reason = PausedBySpecReason
+	}
+
+	capiconditions.Set(object, &capi.Condition{
+		Type:    Paused,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: pausedMessage(pauseInfo),
+	})
+}
+
+func pausedMessage(pauseInfo internal.PauseInfo) string {
+	message := fmt.Sprintf("%s is paused with %s", pauseInfo.Object, pauseInfo.PausedBy)
+	if pauseInfo.Manager != "" {
+		message += fmt.Sprintf(" by %s", pauseInfo.Manager)
+	}
+	if pauseInfo.Since != nil {
+		message += fmt.Sprintf(" since %s", pauseInfo.Since.UTC().Format(time.RFC3339))
+	}
+
+	return message
+}
+
+func update(object conditions.Object, pauseInfo internal.PauseInfo) {
+	if pauseInfo.Paused {
+		MarkPausedTrue(object, pauseInfo)
+	} else {
+		capiconditions.Delete(object, Paused)
+	}
+}
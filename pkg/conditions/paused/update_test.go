@@ -0,0 +1,189 @@
+package paused
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestUpdate(t *testing.T) {
+	pausedAt := metav1.NewTime(time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC))
+
+	testCases := []struct {
+		name              string
+		cluster           *capi.Cluster
+		object            func(cluster *capi.Cluster) conditions.Object
+		expectedCondition *capi.Condition
+	}{
+		{
+			name:    "case 0: Paused condition is not set for cluster that is not paused",
+			cluster: newCluster(),
+			object:  func(cluster *capi.Cluster) conditions.Object { return cluster },
+		},
+		{
+			name: "case 1: Paused condition is set for cluster with Spec.Paused, with the manager and time from managed fields",
+			cluster: func() *capi.Cluster {
+				c := newCluster()
+				c.Spec.Paused = true
+				c.ManagedFields = []metav1.ManagedFieldsEntry{
+					{
+						Manager:  "kubectl-edit",
+						Time:     &pausedAt,
+						FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:paused":{}}}`)},
+					},
+				}
+				return c
+			}(),
+			object: func(cluster *capi.Cluster) conditions.Object { return cluster },
+			expectedCondition: &capi.Condition{
+				Type:    Paused,
+				Status:  corev1.ConditionTrue,
+				Reason:  PausedBySpecReason,
+				Message: "Cluster org-test/test1 is paused with Spec.Paused by kubectl-edit since 2022-04-01T12:00:00Z",
+			},
+		},
+		{
+			name: "case 2: Paused condition is set for node pool of cluster with paused annotation",
+			cluster: func() *capi.Cluster {
+				c := newCluster()
+				c.Annotations = map[string]string{capi.PausedAnnotation: ""}
+				return c
+			}(),
+			object: func(_ *capi.Cluster) conditions.Object { return newMachinePool() },
+			expectedCondition: &capi.Condition{
+				Type:    Paused,
+				Status:  corev1.ConditionTrue,
+				Reason:  PausedByAnnotationReason,
+				Message: "Cluster org-test/test1 is paused with annotation",
+			},
+		},
+		{
+			name:    "case 3: Paused condition is removed from node pool of cluster that is not paused anymore",
+			cluster: newCluster(),
+			object: func(_ *capi.Cluster) conditions.Object {
+				mp := newMachinePool()
+				capiconditions.MarkTrue(mp, Paused)
+				return mp
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			ctx := context.Background()
+			client := internal.NewFakeClient(capi.AddToScheme, capiexp.AddToScheme)
+			err := client.Create(ctx, tc.cluster.DeepCopy())
+			if err != nil {
+				t.Fatal(err)
+			}
+			object := tc.object(tc.cluster)
+
+			// act
+			pauseInfo, err := internal.GetPauseInfo(ctx, client, object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			update(object, pauseInfo)
+
+			// assert
+			condition := capiconditions.Get(object, Paused)
+			if tc.expectedCondition == nil {
+				if condition != nil {
+					t.Errorf("expected Paused condition not to be set, got %s", internal.SprintComparedCondition(condition))
+				}
+			} else if condition == nil || !internal.AreEqualWithIgnoringLastTransitionTime(condition, tc.expectedCondition) {
+				t.Logf(
+					"expected Paused condition %s, got %s",
+					internal.SprintComparedCondition(tc.expectedCondition),
+					internal.SprintComparedCondition(condition))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestMutatingHandlerSkipsPausedObject(t *testing.T) {
+	testName := "condition of paused object is not changed, unless the handler runs when paused"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		ctx := context.Background()
+		client := internal.NewFakeClient(capi.AddToScheme)
+		logger, err := micrologger.New(micrologger.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cluster := newCluster()
+		cluster.Spec.Paused = true
+		markReady := func(_ context.Context, object conditions.Object) error {
+			capiconditions.MarkTrue(object, capi.ReadyCondition)
+			return nil
+		}
+		mutatingHandler, err := internal.NewHandler(internal.HandlerConfig{
+			CtrlClient:        client,
+			Logger:            logger,
+			ConditionType:     capi.ReadyCondition,
+			EnsureCreatedFunc: markReady,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pausedHandler, err := NewHandler(HandlerConfig{CtrlClient: client, Logger: logger, Name: "test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err = mutatingHandler.EnsureCreated(ctx, cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = pausedHandler.EnsureCreated(ctx, cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// assert
+		if capiconditions.Has(cluster, capi.ReadyCondition) {
+			t.Errorf("expected Ready condition not to be set for paused cluster")
+		}
+		condition := capiconditions.Get(cluster, Paused)
+		if condition == nil || !strings.HasPrefix(condition.Message, "Cluster org-test/test1 is paused") {
+			t.Errorf("expected Paused condition to be set, got %s", internal.SprintComparedCondition(condition))
+		}
+	})
+}
+
+func newCluster() *capi.Cluster {
+	return &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-test",
+			Name:      "test1",
+		},
+	}
+}
+
+func newMachinePool() *capiexp.MachinePool {
+	return &capiexp.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-test",
+			Name:      "np1",
+			Labels: map[string]string{
+				capi.ClusterLabelName: "test1",
+			},
+		},
+	}
+}
@@ -2,6 +2,7 @@ package replicasready
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
@@ -9,6 +10,9 @@ import (
 	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/damping"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
 	"github.com/giantswarm/conditions-handler/pkg/internal"
 	"github.com/giantswarm/conditions-handler/pkg/key"
 )
@@ -19,20 +23,48 @@ type HandlerConfig struct {
 
 	Name         string
 	UpdateStatus bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
+
+	// Policy defines when MachinePool replicas are considered ready.
+	Policy Policy
+
+	// Damping defines flap damping of the condition. By default every
+	// condition change is published immediately.
+	Damping damping.Config
+
+	// ScalingGracePeriod is the period during which not ready replicas are
+	// tolerated while MachinePool Scaling condition is True. During that
+	// period ReplicasReady is set with severity Info instead of Warning.
+	// Scaling condition must be set before ReplicasReady, e.g. by running
+	// scaling handler before this one. Zero value disables the tolerance.
+	ScalingGracePeriod time.Duration
 }
 
 type Handler struct {
-	ctrlClient      ctrl.Client
-	internalHandler *internal.Handler
-	logger          micrologger.Logger
-	name            string
+	ctrlClient         ctrl.Client
+	internalHandler    *internal.Handler
+	logger             micrologger.Logger
+	name               string
+	policy             Policy
+	scalingGracePeriod time.Duration
 }
 
 func NewHandler(config HandlerConfig) (*Handler, error) {
+	err := config.Policy.validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	h := &Handler{
-		ctrlClient: config.CtrlClient,
-		logger:     config.Logger,
-		name:       config.Name,
+		ctrlClient:         config.CtrlClient,
+		logger:             config.Logger,
+		name:               config.Name,
+		policy:             config.Policy,
+		scalingGracePeriod: config.ScalingGracePeriod,
 	}
 
 	internalHandlerConfig := internal.HandlerConfig{
@@ -40,6 +72,10 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 		Logger:            config.Logger,
 		UpdateStatus:      config.UpdateStatus,
 		ConditionType:     capiexp.ReplicasReadyCondition,
+		Damping:           config.Damping,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
 		EnsureCreatedFunc: h.ensureCreated,
 	}
 
@@ -75,6 +111,6 @@ func (h *Handler) ensureCreated(ctx context.Context, object conditions.Object) e
 		return microerror.Mask(err)
 	}
 
-	update(machinePool)
+	update(machinePool, h.policy, h.scalingGracePeriod, h.internalHandler.Now())
 	return nil
 }
@@ -0,0 +1,122 @@
+package replicasready
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/giantswarm/microerror"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+const (
+	// MinReadyReplicasNotReachedReason is used when the number of ready
+	// replicas is below the minimum required by the policy.
+	MinReadyReplicasNotReachedReason = "MinReadyReplicasNotReached"
+
+	// AutoscalerMinSizeNotReachedReason is used when the number of ready
+	// replicas is below the cluster-autoscaler node group min size.
+	AutoscalerMinSizeNotReachedReason = "AutoscalerMinSizeNotReached"
+
+	// NoReplicasReason is used when the MachinePool does not have any
+	// replicas and ZeroReplicasNotReady policy is used.
+	NoReplicasReason = "NoReplicas"
+)
+
+// ZeroReplicasPolicy defines how ReplicasReady is set for MachinePools that
+// do not have any replicas.
+type ZeroReplicasPolicy string
+
+const (
+	// ZeroReplicasIgnore leaves ReplicasReady condition unchanged for
+	// MachinePools without replicas. This is the default.
+	ZeroReplicasIgnore ZeroReplicasPolicy = ""
+
+	// ZeroReplicasReady sets ReplicasReady with status True for MachinePools
+	// without replicas.
+	ZeroReplicasReady ZeroReplicasPolicy = "Ready"
+
+	// ZeroReplicasNotReady sets ReplicasReady with status False, severity
+	// Info and reason NoReplicas for MachinePools without replicas.
+	ZeroReplicasNotReady ZeroReplicasPolicy = "NotReady"
+)
+
+// Policy defines when MachinePool replicas are considered ready. Zero value
+// requires all replicas to be ready and to have node references set.
+type Policy struct {
+	// MinReadyPercentage is the minimum percentage of ready replicas, from 0
+	// to 100. Zero value disables the check.
+	MinReadyPercentage int
+	// MinReadyReplicas is the minimum number of ready replicas. Zero value
+	// disables the check. When both MinReadyPercentage and MinReadyReplicas
+	// are set, the greater of the two is required.
+	MinReadyReplicas int32
+	// AutoscalerAware enables reading cluster-autoscaler node group min size
+	// annotation, so a MachinePool with at least min size ready replicas is
+	// considered ready.
+	AutoscalerAware bool
+	// ZeroReplicas defines how MachinePools without replicas are handled.
+	ZeroReplicas ZeroReplicasPolicy
+}
+
+func (p Policy) validate() error {
+	if p.MinReadyPercentage < 0 || p.MinReadyPercentage > 100 {
+		return microerror.Maskf(errors.InvalidConfigError, "%T.MinReadyPercentage must be between 0 and 100, got %d", p, p.MinReadyPercentage)
+	}
+	if p.MinReadyReplicas < 0 {
+		return microerror.Maskf(errors.InvalidConfigError, "%T.MinReadyReplicas must not be negative, got %d", p, p.MinReadyReplicas)
+	}
+
+	switch p.ZeroReplicas {
+	case ZeroReplicasIgnore, ZeroReplicasReady, ZeroReplicasNotReady:
+	default:
+		return microerror.Maskf(errors.InvalidConfigError, "%T.ZeroReplicas has unknown value %q", p, p.ZeroReplicas)
+	}
+
+	return nil
+}
+
+// hasThreshold checks if the policy defines the minimum number of ready
+// replicas.
+func (p Policy) hasThreshold() bool {
+	return p.MinReadyPercentage > 0 || p.MinReadyReplicas > 0
+}
+
+// minReadyReplicas returns the minimum number of ready replicas required by
+// the policy for the specified number of replicas.
+func (p Policy) minReadyReplicas(replicas int32) int32 {
+	// Rounding up, so that e.g. 50% of 3 replicas requires 2 ready replicas.
+	fromPercentage := int32((int(replicas)*p.MinReadyPercentage + 99) / 100)
+	if fromPercentage > p.MinReadyReplicas {
+		return fromPercentage
+	}
+
+	return p.MinReadyReplicas
+}
+
+// autoscalerMinSize returns cluster-autoscaler node group min size of the
+// specified MachinePool, and false if the annotation is not set or invalid.
+func autoscalerMinSize(machinePool *capiexp.MachinePool) (int32, bool) {
+	annotations := machinePool.GetAnnotations()
+
+	value, ok := annotations[internal.AutoscalerMinSize]
+	if !ok {
+		value, ok = annotations[internal.LegacyAutoscalerMinSize]
+	}
+	if !ok {
+		return 0, false
+	}
+
+	minSize, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || minSize < 0 {
+		return 0, false
+	}
+
+	return int32(minSize), true
+}
+
+func sprintReadyReplicas(readyReplicas, replicas int32) string {
+	return fmt.Sprintf("%d/%d replicas are ready", readyReplicas, replicas)
+}
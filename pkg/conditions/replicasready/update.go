@@ -1,33 +1,162 @@
 package replicasready
 
 import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
 )
 
-func update(machinePool *capiexp.MachinePool) {
-	if len(machinePool.Spec.ProviderIDList) == 0 {
+// update sets ReplicasReady condition on specified MachinePool according to
+// the specified policy.
+//
+// If not all replicas are ready or not all node references are set,
+// ReplicasReady is set with status False and severity Warning, unless the
+// policy allows fewer ready replicas. When the MachinePool is being scaled
+// for less than the specified scaling grace period, severity Info is used
+// instead of Warning.
+//
+// MachinePools without replicas are handled according to the policy's
+// ZeroReplicas setting. MachinePools with replicas whose provider IDs are not
+// set yet get ReplicasReady with status False and severity Info.
+//
+// The scaling grace period is measured until the specified time.
+func update(machinePool *capiexp.MachinePool, policy Policy, scalingGracePeriod time.Duration, now time.Time) {
+	replicas := machinePool.Status.Replicas
+
+	if replicas == 0 && len(machinePool.Spec.ProviderIDList) == 0 {
+		updateWithZeroReplicas(machinePool, policy.ZeroReplicas)
 		return
 	}
 
-	// Check if all found nodes are ready or not, and if all node references
-	// are set.
-	if machinePool.Status.Replicas != machinePool.Status.ReadyReplicas ||
-		len(machinePool.Status.NodeRefs) != int(machinePool.Status.ReadyReplicas) {
+	if len(machinePool.Spec.ProviderIDList) == 0 {
+		// Replicas are being created by the infrastructure provider.
 		capiconditions.MarkFalse(
 			machinePool,
 			capiexp.ReplicasReadyCondition,
 			capiexp.WaitingForReplicasReadyReason,
-			capi.ConditionSeverityWarning,
-			"%d/%d replicas are ready, %d/%d node references set",
+			capi.ConditionSeverityInfo,
+			"%d/%d replicas are ready, provider IDs are not set",
 			machinePool.Status.ReadyReplicas,
-			machinePool.Status.Replicas,
-			len(machinePool.Status.NodeRefs),
-			machinePool.Status.Replicas)
+			replicas)
+		return
+	}
+
+	// Check if all found nodes are ready or not, and if all node references
+	// are set.
+	if replicas == machinePool.Status.ReadyReplicas &&
+		len(machinePool.Status.NodeRefs) == int(machinePool.Status.ReadyReplicas) {
+		// Desired number of replicas is ready and all node references are set.
+		capiconditions.MarkTrue(machinePool, capiexp.ReplicasReadyCondition)
+		return
+	}
+
+	// Ready replicas are counted only if their node references are set.
+	readyReplicas := machinePool.Status.ReadyReplicas
+	if int(readyReplicas) > len(machinePool.Status.NodeRefs) {
+		readyReplicas = int32(len(machinePool.Status.NodeRefs))
+	}
+
+	severity := capi.ConditionSeverityWarning
+	if isScalingWithinGracePeriod(machinePool, scalingGracePeriod, now) {
+		severity = capi.ConditionSeverityInfo
+	}
+
+	if minSize, ok := autoscalerMinSize(machinePool); policy.AutoscalerAware && ok {
+		if readyReplicas >= minSize {
+			markReplicasReadyTrueWithMessage(
+				machinePool,
+				"%s, cluster-autoscaler min size %d is reached",
+				sprintReadyReplicas(readyReplicas, replicas),
+				minSize)
+		} else {
+			capiconditions.MarkFalse(
+				machinePool,
+				capiexp.ReplicasReadyCondition,
+				AutoscalerMinSizeNotReachedReason,
+				severity,
+				"%s, cluster-autoscaler min size %d is not reached",
+				sprintReadyReplicas(readyReplicas, replicas),
+				minSize)
+		}
 		return
 	}
 
-	// Desired number of replicas is ready and all node references are set.
-	capiconditions.MarkTrue(machinePool, capiexp.ReplicasReadyCondition)
+	if policy.hasThreshold() {
+		minReadyReplicas := policy.minReadyReplicas(replicas)
+		if readyReplicas >= minReadyReplicas {
+			markReplicasReadyTrueWithMessage(
+				machinePool,
+				"%s, at least %d required",
+				sprintReadyReplicas(readyReplicas, replicas),
+				minReadyReplicas)
+		} else {
+			capiconditions.MarkFalse(
+				machinePool,
+				capiexp.ReplicasReadyCondition,
+				MinReadyReplicasNotReachedReason,
+				severity,
+				"%s, at least %d required",
+				sprintReadyReplicas(readyReplicas, replicas),
+				minReadyReplicas)
+		}
+		return
+	}
+
+	capiconditions.MarkFalse(
+		machinePool,
+		capiexp.ReplicasReadyCondition,
+		capiexp.WaitingForReplicasReadyReason,
+		severity,
+		"%d/%d replicas are ready, %d/%d node references set",
+		machinePool.Status.ReadyReplicas,
+		replicas,
+		len(machinePool.Status.NodeRefs),
+		replicas)
+}
+
+func updateWithZeroReplicas(machinePool *capiexp.MachinePool, zeroReplicasPolicy ZeroReplicasPolicy) {
+	switch zeroReplicasPolicy {
+	case ZeroReplicasReady:
+		markReplicasReadyTrueWithMessage(machinePool, "MachinePool does not have any replicas")
+	case ZeroReplicasNotReady:
+		capiconditions.MarkFalse(
+			machinePool,
+			capiexp.ReplicasReadyCondition,
+			NoReplicasReason,
+			capi.ConditionSeverityInfo,
+			"MachinePool does not have any replicas")
+	}
+}
+
+// markReplicasReadyTrueWithMessage sets ReplicasReady condition with status
+// True and a message that explains which policy was applied.
+func markReplicasReadyTrueWithMessage(machinePool *capiexp.MachinePool, messageFormat string, messageArgs ...interface{}) {
+	capiconditions.Set(machinePool, &capi.Condition{
+		Type:    capiexp.ReplicasReadyCondition,
+		Status:  corev1.ConditionTrue,
+		Message: fmt.Sprintf(messageFormat, messageArgs...),
+	})
+}
+
+// isScalingWithinGracePeriod checks if MachinePool Scaling condition has been
+// True for less than the specified grace period. LastTransitionTime of True
+// Scaling condition is set by Scaling handler with the handler clock when the
+// scaling starts, and it is not changed while the scaling progresses.
+func isScalingWithinGracePeriod(machinePool *capiexp.MachinePool, scalingGracePeriod time.Duration, now time.Time) bool {
+	if scalingGracePeriod <= 0 {
+		return false
+	}
+
+	scalingCondition := capiconditions.Get(machinePool, scaling.Scaling)
+	if scalingCondition == nil || scalingCondition.Status != corev1.ConditionTrue {
+		return false
+	}
+
+	return now.Sub(scalingCondition.LastTransitionTime.Time) < scalingGracePeriod
 }
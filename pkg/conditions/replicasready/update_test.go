@@ -1,28 +1,44 @@
 package replicasready
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/giantswarm/micrologger"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
 
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
 	"github.com/giantswarm/conditions-handler/pkg/internal"
 )
 
 type updateTestCase struct {
 	name                string
 	machinePoolManifest string
+	annotations         map[string]string
+	policy              Policy
+	scaling             *capi.Condition
+	scalingGracePeriod  time.Duration
 	expectedCondition   *capi.Condition
 }
 
 func TestUpdate(t *testing.T) {
 	testCases := []updateTestCase{
 		{
-			name:                "0: MachinePool with Spec.ProviderIDList not set",
+			name:                "0: MachinePool with replicas and Spec.ProviderIDList not set",
 			machinePoolManifest: "machinepool-desired-gt-observed.yaml",
+			expectedCondition: &capi.Condition{
+				Type:     capiexp.ReplicasReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   capiexp.WaitingForReplicasReadyReason,
+				Message:  "0/1 replicas are ready, provider IDs are not set",
+			},
 		},
 		{
 			name:                "1: MachinePool with Status.Replicas greater than Status.ReadyReplicas (not all replicas are ready)",
@@ -54,6 +70,104 @@ func TestUpdate(t *testing.T) {
 				Status: corev1.ConditionTrue,
 			},
 		},
+		{
+			name:                "4: MachinePool with replicas not ready while scaling within grace period",
+			machinePoolManifest: "machinepool-replicas-not-ready.yaml",
+			scaling:             newScalingCondition(time.Now().Add(-1 * time.Minute)),
+			scalingGracePeriod:  5 * time.Minute,
+			expectedCondition: &capi.Condition{
+				Type:     capiexp.ReplicasReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   capiexp.WaitingForReplicasReadyReason,
+				Message:  "1/3 replicas are ready, 2/3 node references set",
+			},
+		},
+		{
+			name:                "5: MachinePool with replicas not ready while scaling longer than grace period",
+			machinePoolManifest: "machinepool-replicas-not-ready.yaml",
+			scaling:             newScalingCondition(time.Now().Add(-10 * time.Minute)),
+			scalingGracePeriod:  5 * time.Minute,
+			expectedCondition: &capi.Condition{
+				Type:     capiexp.ReplicasReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   capiexp.WaitingForReplicasReadyReason,
+				Message:  "1/3 replicas are ready, 2/3 node references set",
+			},
+		},
+		{
+			name:                "6: MachinePool with ready replicas below minimum ready percentage",
+			machinePoolManifest: "machinepool-replicas-not-ready.yaml",
+			policy:              Policy{MinReadyPercentage: 50},
+			expectedCondition: &capi.Condition{
+				Type:     capiexp.ReplicasReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   MinReadyReplicasNotReachedReason,
+				Message:  "1/3 replicas are ready, at least 2 required",
+			},
+		},
+		{
+			name:                "7: MachinePool with ready replicas above minimum ready replicas",
+			machinePoolManifest: "machinepool-replicas-not-ready.yaml",
+			policy:              Policy{MinReadyReplicas: 1},
+			expectedCondition: &capi.Condition{
+				Type:    capiexp.ReplicasReadyCondition,
+				Status:  corev1.ConditionTrue,
+				Message: "1/3 replicas are ready, at least 1 required",
+			},
+		},
+		{
+			name:                "8: MachinePool with ready replicas at cluster-autoscaler min size",
+			machinePoolManifest: "machinepool-replicas-not-ready.yaml",
+			annotations:         map[string]string{internal.AutoscalerMinSize: "1"},
+			policy:              Policy{AutoscalerAware: true},
+			expectedCondition: &capi.Condition{
+				Type:    capiexp.ReplicasReadyCondition,
+				Status:  corev1.ConditionTrue,
+				Message: "1/3 replicas are ready, cluster-autoscaler min size 1 is reached",
+			},
+		},
+		{
+			name:                "9: MachinePool with ready replicas below legacy cluster-autoscaler min size",
+			machinePoolManifest: "machinepool-replicas-not-ready.yaml",
+			annotations:         map[string]string{internal.LegacyAutoscalerMinSize: "2"},
+			policy:              Policy{AutoscalerAware: true},
+			expectedCondition: &capi.Condition{
+				Type:     capiexp.ReplicasReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   AutoscalerMinSizeNotReachedReason,
+				Message:  "1/3 replicas are ready, cluster-autoscaler min size 2 is not reached",
+			},
+		},
+		{
+			name:                "10: MachinePool without replicas and default policy",
+			machinePoolManifest: "machinepool-zero-replicas.yaml",
+		},
+		{
+			name:                "11: MachinePool without replicas and ZeroReplicasReady policy",
+			machinePoolManifest: "machinepool-zero-replicas.yaml",
+			policy:              Policy{ZeroReplicas: ZeroReplicasReady},
+			expectedCondition: &capi.Condition{
+				Type:    capiexp.ReplicasReadyCondition,
+				Status:  corev1.ConditionTrue,
+				Message: "MachinePool does not have any replicas",
+			},
+		},
+		{
+			name:                "12: MachinePool without replicas and ZeroReplicasNotReady policy",
+			machinePoolManifest: "machinepool-zero-replicas.yaml",
+			policy:              Policy{ZeroReplicas: ZeroReplicasNotReady},
+			expectedCondition: &capi.Condition{
+				Type:     capiexp.ReplicasReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   NoReplicasReason,
+				Message:  "MachinePool does not have any replicas",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -61,9 +175,15 @@ func TestUpdate(t *testing.T) {
 			// arrange
 			t.Log(tc.name)
 			machinePool := loadMachinePool(t, tc)
+			if tc.annotations != nil {
+				machinePool.SetAnnotations(tc.annotations)
+			}
+			if tc.scaling != nil {
+				machinePool.SetConditions(capi.Conditions{*tc.scaling})
+			}
 
 			// act
-			update(&machinePool)
+			update(&machinePool, tc.policy, tc.scalingGracePeriod, time.Now())
 			replicasReady := capiconditions.Get(&machinePool, capiexp.ReplicasReadyCondition)
 
 			if replicasReady == nil && tc.expectedCondition == nil {
@@ -91,6 +211,56 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestScalingGracePeriodWithScalingProgress(t *testing.T) {
+	testName := "grace period is measured from the scaling start, also when the scaling progresses"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		ctx := context.Background()
+		logger, err := micrologger.New(micrologger.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Date(2022, 3, 31, 10, 0, 0, 0, time.UTC)
+		now := start
+		scalingHandler, err := scaling.NewHandler(scaling.HandlerConfig{
+			CtrlClient: internal.NewFakeClient(capi.AddToScheme, capiexp.AddToScheme),
+			Logger:     logger,
+			Name:       "scaling",
+			Now:        func() time.Time { return now },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		machinePool := loadMachinePool(t, updateTestCase{machinePoolManifest: "machinepool-replicas-not-ready.yaml"})
+		desiredReplicas := int32(6)
+		machinePool.Spec.Replicas = &desiredReplicas
+		gracePeriod := 5 * time.Minute
+
+		expectedSeverities := []capi.ConditionSeverity{
+			capi.ConditionSeverityInfo,
+			capi.ConditionSeverityInfo,
+			capi.ConditionSeverityWarning,
+		}
+		for i, expectedSeverity := range expectedSeverities {
+			// act
+			now = start.Add(time.Duration(i) * 3 * time.Minute)
+			machinePool.Status.Replicas = int32(3 + i)
+			err = scalingHandler.EnsureCreated(ctx, &machinePool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			update(&machinePool, Policy{}, gracePeriod, now)
+
+			// assert
+			replicasReady := capiconditions.Get(&machinePool, capiexp.ReplicasReadyCondition)
+			if replicasReady == nil || replicasReady.Severity != expectedSeverity {
+				t.Errorf("expected ReplicasReady with severity %s after %s, got %s", expectedSeverity, now.Sub(start), internal.SprintComparedCondition(replicasReady))
+			}
+		}
+	})
+}
+
 func loadMachinePool(t *testing.T, tc updateTestCase) capiexp.MachinePool {
 	machinePoolCRPath := filepath.Join("testdata", tc.machinePoolManifest)
 	o, err := internal.LoadCR(machinePoolCRPath)
@@ -105,3 +275,12 @@ func loadMachinePool(t *testing.T, tc updateTestCase) capiexp.MachinePool {
 
 	return *machinePool
 }
+
+func newScalingCondition(lastTransitionTime time.Time) *capi.Condition {
+	return &capi.Condition{
+		Type:               scaling.Scaling,
+		Status:             corev1.ConditionTrue,
+		Reason:             scaling.ScalingUpReason,
+		LastTransitionTime: metav1.NewTime(lastTransitionTime),
+	}
+}
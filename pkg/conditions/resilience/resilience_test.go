@@ -0,0 +1,700 @@
+// Package resilience contains tests that inject k8s API faults into every
+// condition handler, and check that conflicts are handled, transient errors
+// are returned and missing objects are reported with documented reasons.
+package resilience
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/micrologger"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/composite"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/controlplaneready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/creating"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/custom"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/degraded"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/infrastructureready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsupgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/replicasready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/summary"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/upgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+	"github.com/giantswarm/conditions-handler/pkg/factory"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
+)
+
+const (
+	namespace   = "org-test"
+	clusterName = "test1"
+)
+
+var (
+	clusterGVK     = capi.GroupVersion.WithKind("Cluster")
+	machinePoolGVK = capiexp.GroupVersion.WithKind("MachinePool")
+	mockGVK        = conditionstest.MockGroupVersion.WithKind("MockProviderCluster")
+	secretGVK      = corev1.SchemeGroupVersion.WithKind("Secret")
+
+	kubeconfigSecretPresent capi.ConditionType = "KubeconfigSecretPresent"
+)
+
+// read is a k8s API read done by a handler.
+type read struct {
+	operation conditionstest.Operation
+	gvk       schema.GroupVersionKind
+}
+
+// handlerCase is a handler reconciling an object, together with all API
+// reads the handler depends on. A transient error in any of the reads must
+// be returned by the handler.
+type handlerCase struct {
+	name       string
+	newHandler func(config handler.Config) (handler.Interface, error)
+	object     func() ctrl.Object
+	reads      []read
+}
+
+func handlerCases() []handlerCase {
+	getCluster := read{operation: conditionstest.OperationGet, gvk: clusterGVK}
+	getMock := read{operation: conditionstest.OperationGet, gvk: mockGVK}
+	listMachinePools := read{operation: conditionstest.OperationList, gvk: machinePoolGVK}
+
+	return []handlerCase{
+		{
+			name: "composite",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				config.Name = "clusterConditionsHandler"
+				return factory.NewClusterConditionsHandler(config)
+			},
+			object: newCluster,
+			reads:  []read{getMock, listMachinePools},
+		},
+		{
+			name: "controlplaneready",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return controlplaneready.NewHandler(controlplaneready.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "controlPlaneReadyHandler", UpdateStatus: true})
+			},
+			object: newCluster,
+			reads:  []read{getMock},
+		},
+		{
+			name: "creating",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return creating.NewHandler(creating.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "creatingHandler", UpdateStatus: true})
+			},
+			object: newMachinePool,
+			reads:  []read{getCluster},
+		},
+		{
+			name: "custom",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return custom.NewHandler(custom.HandlerConfig{
+					CtrlClient:    config.CtrlClient,
+					Logger:        config.Logger,
+					PostCheck:     config.PostCheck,
+					Name:          "kubeconfigSecretPresentHandler",
+					UpdateStatus:  true,
+					ConditionType: kubeconfigSecretPresent,
+					Lookups: []custom.Lookup{
+						{
+							Name:       "kubeconfig",
+							APIVersion: "v1",
+							Kind:       "Secret",
+							ObjectName: `object.metadata.name + "-kubeconfig"`,
+						},
+					},
+					Expressions: custom.Expressions{
+						Status: "kubeconfig != null",
+					},
+				})
+			},
+			object: newMachinePool,
+			reads:  []read{getCluster, {operation: conditionstest.OperationGet, gvk: secretGVK}},
+		},
+		{
+			name: "degraded",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return degraded.NewHandler(degraded.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "degradedHandler", UpdateStatus: true})
+			},
+			object: newMachinePool,
+			reads:  []read{getCluster},
+		},
+		{
+			name: "infrastructureready",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return infrastructureready.NewHandler(infrastructureready.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "infrastructureReadyHandler", UpdateStatus: true})
+			},
+			object: newMachinePool,
+			reads:  []read{getCluster, getMock},
+		},
+		{
+			name: "nodepoolsready",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return nodepoolsready.NewHandler(nodepoolsready.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "nodePoolsReadyHandler", UpdateStatus: true})
+			},
+			object: newCluster,
+			reads:  []read{listMachinePools},
+		},
+		{
+			name: "nodepoolsupgrading",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return nodepoolsupgrading.NewHandler(nodepoolsupgrading.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "nodePoolsUpgradingHandler", UpdateStatus: true})
+			},
+			object: newCluster,
+			reads:  []read{listMachinePools},
+		},
+		{
+			name: "paused",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return paused.NewHandler(paused.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "pausedHandler", UpdateStatus: true})
+			},
+			object: newMachinePool,
+			reads:  []read{getCluster},
+		},
+		{
+			name: "replicasready",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return replicasready.NewHandler(replicasready.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "replicasReadyHandler", UpdateStatus: true})
+			},
+			object: newMachinePool,
+			reads:  []read{getCluster},
+		},
+		{
+			name: "scaling",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return scaling.NewHandler(scaling.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "scalingHandler", UpdateStatus: true})
+			},
+			object: newMachinePool,
+			reads:  []read{getCluster},
+		},
+		{
+			name: "summary",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return summary.NewHandler(summary.HandlerConfig{
+					CtrlClient:           config.CtrlClient,
+					Logger:               config.Logger,
+					PostCheck:            config.PostCheck,
+					Name:                 "readyHandler",
+					UpdateStatus:         true,
+					SummaryConditionType: capi.ReadyCondition,
+					ConditionsToSummarize: []capi.ConditionType{
+						capi.InfrastructureReadyCondition,
+						capiexp.ReplicasReadyCondition,
+					},
+				})
+			},
+			object: newMachinePool,
+			reads:  []read{getCluster},
+		},
+		{
+			name: "upgrading",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return upgrading.NewHandler(upgrading.HandlerConfig{CtrlClient: config.CtrlClient, Logger: config.Logger, PostCheck: config.PostCheck, Name: "upgradingHandler", UpdateStatus: true, CheckNodePools: true})
+			},
+			object: newCluster,
+			reads:  []read{listMachinePools},
+		},
+	}
+}
+
+// TestConflictIsHandled checks that every handler cancels the reconciliation
+// without an error when the status update conflicts with a concurrent
+// update, so that the object is reconciled again with its latest version.
+func TestConflictIsHandled(t *testing.T) {
+	for i, hc := range handlerCases() {
+		t.Run(hc.name, func(t *testing.T) {
+			t.Logf("case %d: %s", i, hc.name)
+
+			// arrange
+			ctx := context.Background()
+			client, object := newFaultClient(t, hc.object, conditionstest.Fault{
+				Operations: []conditionstest.Operation{conditionstest.OperationStatusUpdate},
+				Error:      conditionstest.ErrorConflict,
+			})
+			h := newHandler(t, hc, client)
+
+			// act
+			err := h.EnsureCreated(ctx, object)
+
+			// assert
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+			if client.Injected(conditionstest.OperationStatusUpdate) == 0 {
+				t.Fatalf("expected status update conflict to be injected")
+			}
+		})
+	}
+}
+
+// TestHistoryIsNotDuplicatedOnConflict checks that a transition is recorded
+// in condition history only once, when the status update conflicts and the
+// object is reconciled again.
+func TestHistoryIsNotDuplicatedOnConflict(t *testing.T) {
+	testCases := []struct {
+		name    string
+		storage history.Storage
+		// composite runs the recording handler without status update in a
+		// composite handler, whose last handler saves the status.
+		composite bool
+	}{
+		{
+			name:    "case 0: history annotation",
+			storage: history.StorageAnnotation,
+		},
+		{
+			name:    "case 1: companion ConfigMap",
+			storage: history.StorageConfigMap,
+		},
+		{
+			name:      "case 2: history annotation of handler in composite handler",
+			storage:   history.StorageAnnotation,
+			composite: true,
+		},
+		{
+			name:      "case 3: companion ConfigMap of handler in composite handler",
+			storage:   history.StorageConfigMap,
+			composite: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			ctx := context.Background()
+			hc := handlerCase{
+				name: "upgrading",
+				newHandler: func(config handler.Config) (handler.Interface, error) {
+					upgradingHandler, err := upgrading.NewHandler(upgrading.HandlerConfig{
+						CtrlClient:   config.CtrlClient,
+						Logger:       config.Logger,
+						PostCheck:    config.PostCheck,
+						Name:         "upgradingHandler",
+						UpdateStatus: !tc.composite,
+						History:      history.Config{MaxEntries: 10, Storage: tc.storage},
+					})
+					if err != nil || !tc.composite {
+						return upgradingHandler, err
+					}
+
+					pausedHandler, err := paused.NewHandler(paused.HandlerConfig{
+						CtrlClient:   config.CtrlClient,
+						Logger:       config.Logger,
+						PostCheck:    config.PostCheck,
+						Name:         "pausedHandler",
+						UpdateStatus: true,
+					})
+					if err != nil {
+						return nil, err
+					}
+
+					return composite.NewHandler(composite.HandlerConfig{
+						CtrlClient: config.CtrlClient,
+						Logger:     config.Logger,
+						Name:       "compositeHandler",
+						Handlers:   []handler.Interface{upgradingHandler, pausedHandler},
+					})
+				},
+			}
+			client, object := newFaultClient(t, newCluster, conditionstest.Fault{
+				Operations: []conditionstest.Operation{conditionstest.OperationStatusUpdate},
+				Calls:      []int{1},
+				Error:      conditionstest.ErrorConflict,
+			})
+			h := newHandler(t, hc, client)
+
+			// act
+			err := h.EnsureCreated(ctx, object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := history.Read(ctx, client.Client, get(t, client, object).(*capi.Cluster))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Fatalf("expected no recorded transition after status update conflict, got %v", entries)
+			}
+			for i := 0; i < 2; i++ {
+				err = h.EnsureCreated(ctx, get(t, client, object))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// assert
+			if client.Injected(conditionstest.OperationStatusUpdate) != 1 {
+				t.Fatalf("expected 1 status update conflict, got %d", client.Injected(conditionstest.OperationStatusUpdate))
+			}
+			entries, err = history.Read(ctx, client.Client, get(t, client, object).(*capi.Cluster))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 recorded transition, got %v", entries)
+			}
+		})
+	}
+}
+
+// TestTransientErrorsAreReturned checks that every handler returns errors of
+// all API reads it depends on and of the status update, so that the object
+// is reconciled again with backoff.
+func TestTransientErrorsAreReturned(t *testing.T) {
+	errorTypes := []struct {
+		errorType conditionstest.ErrorType
+		matcher   func(error) bool
+	}{
+		{errorType: conditionstest.ErrorTimeout, matcher: apierrors.IsTimeout},
+		{errorType: conditionstest.ErrorForbidden, matcher: apierrors.IsForbidden},
+	}
+
+	for i, hc := range handlerCases() {
+		reads := append(hc.reads, read{operation: conditionstest.OperationStatusUpdate})
+		for _, r := range reads {
+			for _, et := range errorTypes {
+				name := hc.name + "/" + string(r.operation) + r.gvk.Kind + "/" + string(et.errorType)
+				t.Run(name, func(t *testing.T) {
+					t.Logf("case %d: %s", i, name)
+
+					// arrange
+					ctx := context.Background()
+					client, object := newFaultClient(t, hc.object, r.fault(conditionstest.Fault{Error: et.errorType}))
+					h := newHandler(t, hc, client)
+
+					// act
+					err := h.EnsureCreated(ctx, object)
+
+					// assert
+					if client.Injected(r.operation) == 0 {
+						t.Fatalf("expected %s fault to be injected", r.operation)
+					}
+					if !et.matcher(err) {
+						t.Fatalf("expected %s error, got %#v", et.errorType, err)
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestLatencyExceedingDeadlineIsReturned checks that every handler returns
+// context error when an API read takes longer than the reconciliation
+// deadline.
+func TestLatencyExceedingDeadlineIsReturned(t *testing.T) {
+	for i, hc := range handlerCases() {
+		t.Run(hc.name, func(t *testing.T) {
+			t.Logf("case %d: %s", i, hc.name)
+
+			// arrange
+			client, object := newFaultClient(t, hc.object, hc.reads[0].fault(conditionstest.Fault{Latency: time.Minute}))
+			h := newHandler(t, hc, client)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			// act
+			err := h.EnsureCreated(ctx, object)
+
+			// assert
+			if err == nil || ctx.Err() == nil {
+				t.Fatalf("expected context deadline error, got %#v", err)
+			}
+		})
+	}
+}
+
+// TestNotFoundIsReported checks that objects that are not found are not
+// treated as errors, but reported with documented condition reasons.
+func TestNotFoundIsReported(t *testing.T) {
+	testCases := []struct {
+		name        string
+		handlerName string
+		read        read
+		matcher     types.GomegaMatcher
+	}{
+		{
+			name:        "case 0: infrastructure object not found",
+			handlerName: "infrastructureready",
+			read:        read{operation: conditionstest.OperationGet, gvk: mockGVK},
+			matcher:     conditionstest.HaveCondition(capi.InfrastructureReadyCondition, corev1.ConditionFalse, conditions.InfrastructureObjectNotFoundReason, capi.ConditionSeverityWarning),
+		},
+		{
+			name:        "case 1: control plane object not found",
+			handlerName: "controlplaneready",
+			read:        read{operation: conditionstest.OperationGet, gvk: mockGVK},
+			matcher:     conditionstest.HaveCondition(capi.ControlPlaneReadyCondition, corev1.ConditionFalse, conditions.ControlPlaneObjectNotFoundReason, capi.ConditionSeverityWarning),
+		},
+		{
+			name:        "case 2: node pools not found",
+			handlerName: "nodepoolsready",
+			read:        read{operation: conditionstest.OperationList, gvk: machinePoolGVK},
+			matcher:     conditionstest.HaveCondition(conditions.NodePoolsReady, corev1.ConditionFalse, conditions.NodePoolsNotFoundReason, capi.ConditionSeverityInfo),
+		},
+		{
+			name:        "case 3: Cluster of MachinePool not found",
+			handlerName: "paused",
+			read:        read{operation: conditionstest.OperationGet, gvk: clusterGVK},
+			matcher:     gomega.Not(conditionstest.HaveConditionTrue(paused.Paused)),
+		},
+		{
+			name:        "case 4: looked up object not found",
+			handlerName: "custom",
+			read:        read{operation: conditionstest.OperationGet, gvk: secretGVK},
+			matcher:     conditionstest.HaveCondition(kubeconfigSecretPresent, corev1.ConditionFalse, custom.ConditionNotMetReason, capi.ConditionSeverityWarning),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			ctx := context.Background()
+			hc := findHandlerCase(t, tc.handlerName)
+			client, object := newFaultClient(t, hc.object, tc.read.fault(conditionstest.Fault{Error: conditionstest.ErrorNotFound}))
+			h := newHandler(t, hc, client)
+
+			// act
+			err := h.EnsureCreated(ctx, object)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			if client.Injected(tc.read.operation) == 0 {
+				t.Fatalf("expected %s fault to be injected", tc.read.operation)
+			}
+			current := get(t, client, object)
+			ok, err := tc.matcher.Match(current)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Error(tc.matcher.FailureMessage(current))
+			}
+		})
+	}
+}
+
+// TestStaleReadsConverge checks that handlers reconciling with a stale
+// object compute the condition from it without an error, and that the
+// condition is corrected by the next reconciliation.
+func TestStaleReadsConverge(t *testing.T) {
+	testCases := []struct {
+		name        string
+		handlerName string
+		read        read
+		change      func(ctx context.Context, client ctrl.Client) error
+		stale       types.GomegaMatcher
+		converged   types.GomegaMatcher
+	}{
+		{
+			name:        "case 0: stale infrastructure object",
+			handlerName: "infrastructureready",
+			read:        read{operation: conditionstest.OperationGet, gvk: mockGVK},
+			change: func(ctx context.Context, client ctrl.Client) error {
+				infrastructure := &conditionstest.MockProviderCluster{}
+				err := client.Get(ctx, ctrl.ObjectKey{Namespace: namespace, Name: clusterName}, infrastructure)
+				if err != nil {
+					return err
+				}
+				infrastructure.SetConditions(capi.Conditions{*conditionFalse(capi.ReadyCondition, "Deleting")})
+				return client.Status().Update(ctx, infrastructure)
+			},
+			stale:     conditionstest.HaveConditionTrue(capi.InfrastructureReadyCondition),
+			converged: conditionstest.HaveCondition(capi.InfrastructureReadyCondition, corev1.ConditionFalse, "Deleting", capi.ConditionSeverityWarning),
+		},
+		{
+			name:        "case 1: stale node pools",
+			handlerName: "nodepoolsready",
+			read:        read{operation: conditionstest.OperationList, gvk: machinePoolGVK},
+			change: func(ctx context.Context, client ctrl.Client) error {
+				machinePool := &capiexp.MachinePool{}
+				err := client.Get(ctx, ctrl.ObjectKey{Namespace: namespace, Name: "np1"}, machinePool)
+				if err != nil {
+					return err
+				}
+				machinePool.SetConditions(capi.Conditions{*conditionFalse(capi.ReadyCondition, "ReplicasNotReady")})
+				return client.Status().Update(ctx, machinePool)
+			},
+			stale:     conditionstest.HaveConditionTrue(conditions.NodePoolsReady),
+			converged: conditionstest.HaveCondition(conditions.NodePoolsReady, corev1.ConditionFalse, "ReplicasNotReady @ MachinePool/np1", capi.ConditionSeverityWarning),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			ctx := context.Background()
+			hc := findHandlerCase(t, tc.handlerName)
+			client, object := newFaultClient(t, hc.object, tc.read.fault(conditionstest.Fault{Calls: []int{2}, StaleRead: true}))
+			h := newHandler(t, hc, client)
+
+			err := h.EnsureCreated(ctx, object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tc.change(ctx, client.Client)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			stale := get(t, client, object)
+			err = h.EnsureCreated(ctx, stale)
+			if err != nil {
+				t.Fatal(err)
+			}
+			converged := get(t, client, object)
+			err = h.EnsureCreated(ctx, converged)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			if client.Injected(tc.read.operation) != 1 {
+				t.Fatalf("expected 1 stale read, got %d", client.Injected(tc.read.operation))
+			}
+			for _, m := range []struct {
+				object  ctrl.Object
+				matcher types.GomegaMatcher
+			}{
+				{object: stale, matcher: tc.stale},
+				{object: converged, matcher: tc.converged},
+			} {
+				ok, err := m.matcher.Match(m.object)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ok {
+					t.Error(m.matcher.FailureMessage(m.object))
+				}
+			}
+		})
+	}
+}
+
+func (r read) fault(fault conditionstest.Fault) conditionstest.Fault {
+	fault.Operations = []conditionstest.Operation{r.operation}
+	if !r.gvk.Empty() {
+		fault.GroupVersionKinds = []schema.GroupVersionKind{r.gvk}
+	}
+
+	return fault
+}
+
+// newFaultClient returns a fault client with the reconciled object, its
+// Cluster, infrastructure and control plane objects, and a node pool.
+func newFaultClient(t *testing.T, object func() ctrl.Object, faults ...conditionstest.Fault) (*conditionstest.FaultClient, ctrl.Object) {
+	t.Helper()
+
+	reconciled := object()
+	objects := []ctrl.Object{
+		conditionstest.NewMockProviderCluster(namespace, clusterName).Ready().Build(),
+		conditionstest.NewMockProviderCluster(namespace, clusterName+"-cp").Ready().Build(),
+		newCluster(),
+		newMachinePool(),
+	}
+
+	client, err := conditionstest.NewFaultClient(conditionstest.FaultClientConfig{
+		Client: conditionstest.NewFakeClient(objects...),
+		Faults: faults,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client, get(t, client.Client, reconciled)
+}
+
+func newHandler(t *testing.T, hc handlerCase, client ctrl.Client) handler.Interface {
+	t.Helper()
+
+	logger, err := micrologger.New(micrologger.Config{IOWriter: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := hc.newHandler(handler.Config{
+		CtrlClient: client,
+		Logger:     logger,
+		PostCheck:  conditionstest.NewPostCheck(t, kubeconfigSecretPresent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func findHandlerCase(t *testing.T, name string) handlerCase {
+	t.Helper()
+
+	for _, hc := range handlerCases() {
+		if hc.name == name {
+			return hc
+		}
+	}
+	t.Fatalf("handler case %s not found", name)
+
+	return handlerCase{}
+}
+
+func get(t *testing.T, client ctrl.Client, object ctrl.Object) ctrl.Object {
+	t.Helper()
+
+	current := object.DeepCopyObject().(ctrl.Object)
+	err := client.Get(context.Background(), ctrl.ObjectKeyFromObject(object), current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return current
+}
+
+func newCluster() ctrl.Object {
+	return conditionstest.NewCluster(namespace, clusterName).
+		WithLabel(capi.ClusterLabelName, clusterName).
+		WithReleaseVersion("20.0.0").
+		WithLastDeployedReleaseVersion("20.0.0").
+		WithInfrastructureRef(conditionstest.NewMockProviderCluster(namespace, clusterName).Build()).
+		WithControlPlaneRef(conditionstest.NewMockProviderCluster(namespace, clusterName+"-cp").Build()).
+		Build()
+}
+
+func newMachinePool() ctrl.Object {
+	return conditionstest.NewMachinePool(namespace, "np1").
+		ForCluster(clusterName).
+		WithReleaseVersion("20.0.0").
+		WithLastDeployedReleaseVersion("20.0.0").
+		WithReplicas(2, 2).
+		WithInfrastructureRef(conditionstest.NewMockProviderCluster(namespace, clusterName).Build()).
+		WithCondition(conditionTrue(capi.ReadyCondition)).
+		Build()
+}
+
+func conditionTrue(conditionType capi.ConditionType) *capi.Condition {
+	return &capi.Condition{Type: conditionType, Status: corev1.ConditionTrue}
+}
+
+func conditionFalse(conditionType capi.ConditionType, reason string) *capi.Condition {
+	return &capi.Condition{Type: conditionType, Status: corev1.ConditionFalse, Reason: reason, Severity: capi.ConditionSeverityWarning}
+}
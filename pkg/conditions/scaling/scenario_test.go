@@ -0,0 +1,29 @@
+// Scenario tests are in an external test package, because conditionstest
+// validates conditions against the catalog, which imports this package.
+package scaling_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+)
+
+func TestScenarios(t *testing.T) {
+	conditionstest.RunScenarios(t, conditionstest.ScenarioConfig{
+		Dir: "testdata/scenarios",
+		Handlers: map[string]conditionstest.HandlerFunc{
+			"scaling": func(config handler.Config) (handler.Interface, error) {
+				return scaling.NewHandler(scaling.HandlerConfig{
+					CtrlClient:   config.CtrlClient,
+					Logger:       config.Logger,
+					Name:         "scalingHandler",
+					UpdateStatus: true,
+					PostCheck:    config.PostCheck,
+					Now:          config.Now,
+				})
+			},
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package scaling
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+const (
+	// Scaling is a MachinePool condition that is True while the number of
+	// replicas is being changed, e.g. by cluster-autoscaler. Like Upgrading,
+	// it has negative polarity.
+	Scaling capi.ConditionType = "Scaling"
+
+	// ScalingUpReason is used when the number of replicas is increased.
+	ScalingUpReason = "ScalingUp"
+
+	// ScalingDownReason is used when the number of replicas is decreased.
+	ScalingDownReason = "ScalingDown"
+
+	// DesiredReplicasReadyReason is used when the MachinePool has the desired
+	// number of replicas and it is not being scaled. Readiness of the replicas
+	// is reported by ReplicasReady condition.
+	DesiredReplicasReadyReason = "DesiredReplicasReady"
+)
+
+// update sets Scaling condition on specified MachinePool by comparing the
+// desired number of replicas with the observed number of replicas.
+//
+// If the desired number of replicas differs from the observed number of
+// replicas, MachinePool Scaling is set with status True and reason ScalingUp
+// or ScalingDown. Otherwise it is set with status False and reason
+// DesiredReplicasReady, also when some replicas are not ready, since not
+// ready replicas are not scaling.
+//
+// LastTransitionTime is set to the specified time when the status changes,
+// and it is kept while the scaling progresses, so it tells when the scaling
+// started.
+func update(machinePool *capiexp.MachinePool, now time.Time) {
+	desiredReplicas := desiredReplicas(machinePool)
+	currentReplicas := machinePool.Status.Replicas
+
+	if currentReplicas == desiredReplicas {
+		internal.SetConditionAt(
+			machinePool,
+			capiconditions.FalseCondition(
+				Scaling,
+				DesiredReplicasReadyReason,
+				capi.ConditionSeverityInfo,
+				"Desired number of replicas %d is reached",
+				desiredReplicas),
+			now)
+		return
+	}
+
+	reason := ScalingUpReason
+	if currentReplicas > desiredReplicas {
+		reason = ScalingDownReason
+	}
+
+	internal.SetConditionAt(machinePool, &capi.Condition{
+		Type:    Scaling,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: fmt.Sprintf("Scaling from %d to %d replicas", currentReplicas, desiredReplicas),
+	}, now)
+}
+
+// desiredReplicas returns the desired number of replicas, defaulting to 1
+// like MachinePool webhook does.
+func desiredReplicas(machinePool *capiexp.MachinePool) int32 {
+	if machinePool.Spec.Replicas == nil {
+		return 1
+	}
+
+	return *machinePool.Spec.Replicas
+}
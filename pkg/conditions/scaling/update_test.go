@@ -0,0 +1,146 @@
+package scaling
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestUpdate(t *testing.T) {
+	testCases := []struct {
+		name              string
+		desiredReplicas   int32
+		replicas          int32
+		readyReplicas     int32
+		expectedCondition capi.Condition
+	}{
+		{
+			name:            "case 0: desired number of replicas is reached",
+			desiredReplicas: 3,
+			replicas:        3,
+			readyReplicas:   3,
+			expectedCondition: capi.Condition{
+				Type:     Scaling,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   DesiredReplicasReadyReason,
+				Message:  "Desired number of replicas 3 is reached",
+			},
+		},
+		{
+			name:            "case 1: desired number of replicas is greater than observed",
+			desiredReplicas: 5,
+			replicas:        3,
+			readyReplicas:   3,
+			expectedCondition: capi.Condition{
+				Type:    Scaling,
+				Status:  corev1.ConditionTrue,
+				Reason:  ScalingUpReason,
+				Message: "Scaling from 3 to 5 replicas",
+			},
+		},
+		{
+			name:            "case 2: desired number of replicas is less than observed",
+			desiredReplicas: 2,
+			replicas:        4,
+			readyReplicas:   4,
+			expectedCondition: capi.Condition{
+				Type:    Scaling,
+				Status:  corev1.ConditionTrue,
+				Reason:  ScalingDownReason,
+				Message: "Scaling from 4 to 2 replicas",
+			},
+		},
+		{
+			name:            "case 3: desired number of replicas is observed, but not all are ready, which is not scaling",
+			desiredReplicas: 5,
+			replicas:        5,
+			readyReplicas:   3,
+			expectedCondition: capi.Condition{
+				Type:     Scaling,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   DesiredReplicasReadyReason,
+				Message:  "Desired number of replicas 5 is reached",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			desiredReplicas := tc.desiredReplicas
+			machinePool := &capiexp.MachinePool{
+				Spec: capiexp.MachinePoolSpec{
+					Replicas: &desiredReplicas,
+				},
+				Status: capiexp.MachinePoolStatus{
+					Replicas:      tc.replicas,
+					ReadyReplicas: tc.readyReplicas,
+				},
+			}
+
+			// act
+			update(machinePool, time.Now())
+
+			// assert
+			scaling := capiconditions.Get(machinePool, Scaling)
+			if scaling == nil {
+				t.Fatalf("Scaling was not set, expected %s", internal.SprintComparedCondition(&tc.expectedCondition))
+			}
+
+			if !internal.AreEqualWithIgnoringLastTransitionTime(scaling, &tc.expectedCondition) {
+				t.Logf(
+					"expected %s, got %s",
+					internal.SprintComparedCondition(&tc.expectedCondition),
+					internal.SprintComparedCondition(scaling))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestUpdateWithScalingProgress(t *testing.T) {
+	testName := "LastTransitionTime of True Scaling condition tells when the scaling started while the scaling progresses"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		start := time.Date(2022, 3, 31, 10, 0, 0, 0, time.UTC)
+		desiredReplicas := int32(5)
+		machinePool := &capiexp.MachinePool{
+			Spec: capiexp.MachinePoolSpec{
+				Replicas: &desiredReplicas,
+			},
+			Status: capiexp.MachinePoolStatus{
+				Replicas: 1,
+			},
+		}
+		update(machinePool, start)
+
+		// act
+		machinePool.Status.Replicas = 3
+		update(machinePool, start.Add(5*time.Minute))
+
+		// assert
+		expected := &capi.Condition{
+			Type:    Scaling,
+			Status:  corev1.ConditionTrue,
+			Reason:  ScalingUpReason,
+			Message: "Scaling from 3 to 5 replicas",
+		}
+		scaling := capiconditions.Get(machinePool, Scaling)
+		if !internal.AreEqualWithIgnoringLastTransitionTime(scaling, expected) {
+			t.Errorf("expected %s, got %s", internal.SprintComparedCondition(expected), internal.SprintComparedCondition(scaling))
+		}
+		if !scaling.LastTransitionTime.Time.Equal(start) {
+			t.Errorf("expected LastTransitionTime %s, got %s", start, scaling.LastTransitionTime.Time)
+		}
+	})
+}
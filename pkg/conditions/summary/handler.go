@@ -2,6 +2,7 @@ package summary
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
@@ -9,6 +10,8 @@ import (
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
 	"github.com/giantswarm/conditions-handler/pkg/internal"
 	"github.com/giantswarm/conditions-handler/pkg/key"
 )
@@ -22,6 +25,22 @@ type HandlerConfig struct {
 	IgnoreOptions         []conditions.CheckOption
 	Name                  string
 	UpdateStatus          bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
+
+	// Strategy defines how the reason and the message of the summary
+	// condition are selected. Defaults to StrategyDefault.
+	Strategy Strategy
+	// InputOptions customize how summarized conditions are taken into
+	// account, e.g. some of them can be optional.
+	InputOptions map[capi.ConditionType]InputOptions
+	// MessageTemplate is a text/template for the summary condition message,
+	// executed with MessageData. By default the message of the selected
+	// summarized condition is used.
+	MessageTemplate string
 }
 
 type Handler struct {
@@ -29,17 +48,25 @@ type Handler struct {
 	internalHandler *internal.Handler
 	logger          micrologger.Logger
 
+	summaryConditionType  capi.ConditionType
 	conditionsToSummarize []capi.ConditionType
 	ignoreOptions         []conditions.CheckOption
+	options               summaryOptions
 	name                  string
 }
 
 func NewHandler(config HandlerConfig) (*Handler, error) {
+	options, err := newSummaryOptions(config)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	h := &Handler{
 		ctrlClient:            config.CtrlClient,
 		logger:                config.Logger,
 		conditionsToSummarize: config.ConditionsToSummarize,
 		ignoreOptions:         config.IgnoreOptions,
+		options:               options,
 		name:                  config.Name,
 	}
 
@@ -49,12 +76,17 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 	} else {
 		summaryConditionType = capi.ReadyCondition
 	}
+	h.summaryConditionType = summaryConditionType
 
 	internalHandlerConfig := internal.HandlerConfig{
 		CtrlClient:        config.CtrlClient,
 		Logger:            config.Logger,
 		UpdateStatus:      config.UpdateStatus,
 		ConditionType:     summaryConditionType,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
+		RunWhenPaused:     true,
 		EnsureCreatedFunc: h.ensureCreated,
 	}
 
@@ -84,7 +116,28 @@ func (h *Handler) Name() string {
 	return h.name
 }
 
-func (h *Handler) ensureCreated(_ context.Context, object conditions.Object) error {
+func (h *Handler) ensureCreated(ctx context.Context, object conditions.Object) error {
+	// Summarized conditions are not updated while the object is paused, so
+	// instead of summarizing stale conditions, the summary reports that the
+	// object is paused.
+	pauseInfo, err := internal.GetPauseInfo(ctx, h.ctrlClient, object)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if pauseInfo.Paused {
+		MarkSummaryFalseWithPaused(object, h.summaryConditionType, pauseInfo)
+		return nil
+	}
+
+	if !h.options.isDefault() {
+		err = updateWithStrategy(object, h.summaryConditionType, h.options)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
 	update(object, h.conditionsToSummarize, h.ignoreOptions...)
 	return nil
 }
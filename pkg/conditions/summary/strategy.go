@@ -0,0 +1,293 @@
+package summary
+
+import (
+	"bytes"
+	"sort"
+	"text/template"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+// Strategy defines how the reason and the message of the summary condition
+// are selected from the summarized conditions.
+type Strategy string
+
+const (
+	// StrategyDefault selects the reason and the message from the summarized
+	// conditions with the highest merge priority, i.e. False with Error
+	// severity, then False with Warning severity, then False with Info
+	// severity. Ties are resolved by the order of ConditionsToSummarize and
+	// the reason is suffixed with the source object, e.g.
+	// "ReplicasNotReady @ MachinePool/np1", like in Cluster API SetSummary.
+	// This is the default.
+	StrategyDefault Strategy = ""
+
+	// StrategyPriority selects the reason and the message from the first
+	// failing summarized condition in the order of ConditionsToSummarize, so
+	// the order defines the importance of the summarized conditions. False
+	// conditions are considered before Unknown ones. Status and severity of
+	// the summary condition are still defined by the merge priority.
+	StrategyPriority Strategy = "Priority"
+)
+
+// InputOptions customize how a summarized condition is taken into account.
+type InputOptions struct {
+	// Optional inputs only downgrade the summary condition, i.e. when an
+	// optional input is False, it is summarized with Info severity.
+	Optional bool
+	// MaxSeverity caps the severity of the input when it is False, e.g.
+	// NodePoolsReady with Warning MaxSeverity is never summarized with Error
+	// severity.
+	MaxSeverity capi.ConditionSeverity
+}
+
+// MessageData is the data available in summary message templates.
+type MessageData struct {
+	// Status, Severity, Reason and Message of the summary condition, where
+	// Message is the message selected by the strategy.
+	Status   corev1.ConditionStatus
+	Severity capi.ConditionSeverity
+	Reason   string
+	Message  string
+	// Failing are summarized conditions that are not True, in the order of
+	// ConditionsToSummarize, with input options applied.
+	Failing []capi.Condition
+	// Total is the number of summarized conditions, and Ready is the number
+	// of summarized conditions that are True.
+	Total int
+	Ready int
+}
+
+// summaryOptions are validated summary handler options.
+type summaryOptions struct {
+	conditionTypes  []capi.ConditionType
+	ignoreOptions   []conditions.CheckOption
+	strategy        Strategy
+	inputOptions    map[capi.ConditionType]InputOptions
+	messageTemplate *template.Template
+}
+
+func newSummaryOptions(config HandlerConfig) (summaryOptions, error) {
+	if config.Strategy != StrategyDefault && config.Strategy != StrategyPriority {
+		return summaryOptions{}, microerror.Maskf(errors.InvalidConfigError, "%T.Strategy must be %q or %q, got %q", config, StrategyDefault, StrategyPriority, config.Strategy)
+	}
+
+	for conditionType, inputOptions := range config.InputOptions {
+		switch inputOptions.MaxSeverity {
+		case capi.ConditionSeverityNone, capi.ConditionSeverityError, capi.ConditionSeverityWarning, capi.ConditionSeverityInfo:
+		default:
+			return summaryOptions{}, microerror.Maskf(errors.InvalidConfigError, "%T.InputOptions[%s].MaxSeverity must be Error, Warning or Info, got %q", config, conditionType, inputOptions.MaxSeverity)
+		}
+	}
+
+	var messageTemplate *template.Template
+	if config.MessageTemplate != "" {
+		var err error
+		messageTemplate, err = template.New("message").Option("missingkey=error").Parse(config.MessageTemplate)
+		if err != nil {
+			return summaryOptions{}, microerror.Maskf(errors.InvalidConfigError, "%T.MessageTemplate is invalid: %s", config, err)
+		}
+	}
+
+	o := summaryOptions{
+		conditionTypes:  config.ConditionsToSummarize,
+		ignoreOptions:   config.IgnoreOptions,
+		strategy:        config.Strategy,
+		inputOptions:    config.InputOptions,
+		messageTemplate: messageTemplate,
+	}
+
+	return o, nil
+}
+
+// isDefault checks if the summary is computed with Cluster API SetSummary.
+func (o summaryOptions) isDefault() bool {
+	return o.strategy == StrategyDefault && len(o.inputOptions) == 0 && o.messageTemplate == nil
+}
+
+// inputs returns summarized conditions in the order of conditionTypes, with
+// missing and ignored conditions left out and input options applied.
+func (o summaryOptions) inputs(object conditions.Object) []capi.Condition {
+	var inputs []capi.Condition
+	for _, conditionType := range o.conditionTypes {
+		condition := capiconditions.Get(object, conditionType)
+		if condition == nil || isIgnored(condition, o.ignoreOptions) {
+			continue
+		}
+
+		if condition.Status == corev1.ConditionFalse {
+			inputOptions := o.inputOptions[conditionType]
+			if inputOptions.Optional {
+				condition.Severity = capSeverity(condition.Severity, capi.ConditionSeverityInfo)
+			}
+			if inputOptions.MaxSeverity != capi.ConditionSeverityNone {
+				condition.Severity = capSeverity(condition.Severity, inputOptions.MaxSeverity)
+			}
+		}
+
+		inputs = append(inputs, *condition)
+	}
+
+	return inputs
+}
+
+// summarize computes the summary condition from the summarized conditions.
+// It returns nil when there are no conditions to summarize.
+func (o summaryOptions) summarize(object conditions.Object, summaryConditionType capi.ConditionType, inputs []capi.Condition) (*capi.Condition, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	top := inputs[0]
+	var failing []capi.Condition
+	ready := 0
+	for _, input := range inputs {
+		if mergePriority(input) < mergePriority(top) ||
+			(mergePriority(input) == mergePriority(top) && input.Type < top.Type) {
+			top = input
+		}
+		if input.Status == corev1.ConditionTrue {
+			ready++
+		} else {
+			failing = append(failing, input)
+		}
+	}
+
+	var summary *capi.Condition
+	if o.strategy == StrategyPriority {
+		summary = &capi.Condition{
+			Type:   summaryConditionType,
+			Status: top.Status,
+		}
+		if top.Status != corev1.ConditionTrue {
+			selected := firstFailing(failing)
+			summary.Severity = top.Severity
+			summary.Reason = selected.Reason
+			summary.Message = selected.Message
+		}
+	} else {
+		summary = defaultSummary(object, summaryConditionType, inputs)
+	}
+
+	if o.messageTemplate != nil {
+		data := MessageData{
+			Status:   summary.Status,
+			Severity: summary.Severity,
+			Reason:   summary.Reason,
+			Message:  summary.Message,
+			Failing:  failing,
+			Total:    len(inputs),
+			Ready:    ready,
+		}
+
+		var message bytes.Buffer
+		err := o.messageTemplate.Execute(&message, data)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		summary.Message = message.String()
+	}
+
+	return summary, nil
+}
+
+// summaryInputs is the summarized object with its conditions replaced by the
+// summary inputs, so that Cluster API can summarize inputs with applied input
+// options without changing the object.
+type summaryInputs struct {
+	conditions.Object
+	inputs capi.Conditions
+}
+
+func (s *summaryInputs) GetConditions() capi.Conditions {
+	return s.inputs
+}
+
+func (s *summaryInputs) SetConditions(inputs capi.Conditions) {
+	s.inputs = inputs
+}
+
+// defaultSummary computes the summary condition of the inputs with Cluster
+// API SetSummary, like the summary handler without strategy options does.
+func defaultSummary(object conditions.Object, summaryConditionType capi.ConditionType, inputs []capi.Condition) *capi.Condition {
+	var conditionTypes []capi.ConditionType
+	for _, input := range inputs {
+		conditionTypes = append(conditionTypes, input.Type)
+	}
+
+	s := &summaryInputs{
+		Object: object,
+		inputs: append(capi.Conditions{}, inputs...),
+	}
+	capiconditions.SetSummary(
+		s,
+		capiconditions.WithConditions(conditionTypes...),
+		capiconditions.AddSourceRef())
+
+	summary := capiconditions.Get(s, capi.ReadyCondition)
+	summary.Type = summaryConditionType
+	summary.LastTransitionTime = metav1.Time{}
+
+	return summary
+}
+
+// firstFailing returns the first False condition, or the first Unknown
+// condition when none is False.
+func firstFailing(failing []capi.Condition) capi.Condition {
+	sorted := append([]capi.Condition{}, failing...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Status == corev1.ConditionFalse && sorted[j].Status != corev1.ConditionFalse
+	})
+
+	return sorted[0]
+}
+
+// mergePriority returns the same priority as Cluster API uses when merging
+// conditions, where lower value means higher priority.
+func mergePriority(condition capi.Condition) int {
+	switch condition.Status {
+	case corev1.ConditionFalse:
+		return severityRank(condition.Severity)
+	case corev1.ConditionTrue:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func severityRank(severity capi.ConditionSeverity) int {
+	switch severity {
+	case capi.ConditionSeverityError:
+		return 0
+	case capi.ConditionSeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// capSeverity returns severity that is not more severe than maxSeverity.
+func capSeverity(severity, maxSeverity capi.ConditionSeverity) capi.ConditionSeverity {
+	if severityRank(severity) < severityRank(maxSeverity) {
+		return maxSeverity
+	}
+
+	return severity
+}
+
+func isIgnored(condition *capi.Condition, ignoreOptions []conditions.CheckOption) bool {
+	for _, ignoreOption := range ignoreOptions {
+		if ignoreOption(condition) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,10 +2,33 @@ package summary
 
 import (
 	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+const (
+	// PausedReason is used when the summary condition is not computed,
+	// because the object or its Cluster is paused.
+	PausedReason = "Paused"
 )
 
+// MarkSummaryFalseWithPaused sets the summary condition with status False,
+// reason Paused, severity Info and a message informing which object is
+// paused.
+func MarkSummaryFalseWithPaused(object conditions.Object, summaryConditionType capi.ConditionType, pauseInfo internal.PauseInfo) {
+	capiconditions.MarkFalse(
+		object,
+		summaryConditionType,
+		PausedReason,
+		capi.ConditionSeverityInfo,
+		"Reconciliation is paused, %s is paused with %s",
+		pauseInfo.Object,
+		pauseInfo.PausedBy)
+}
+
 func update(object conditions.Object, conditionTypesToSummarize []capi.ConditionType, ignoreOptions ...conditions.CheckOption) {
 	var conditionsToSummarizeOption capiconditions.MergeOption
 
@@ -35,3 +58,18 @@ func update(object conditions.Object, conditionTypesToSummarize []capi.Condition
 		conditionsToSummarizeOption,
 		capiconditions.AddSourceRef())
 }
+
+// updateWithStrategy sets the summary condition computed with the
+// configured strategy, input options and message template.
+func updateWithStrategy(object conditions.Object, summaryConditionType capi.ConditionType, options summaryOptions) error {
+	summary, err := options.summarize(object, summaryConditionType, options.inputs(object))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if summary == nil {
+		return nil
+	}
+
+	capiconditions.Set(object, summary)
+	return nil
+}
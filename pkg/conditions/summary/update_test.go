@@ -0,0 +1,298 @@
+package summary
+
+import (
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestUpdateWithStrategy(t *testing.T) {
+	conditionsToSummarize := []capi.ConditionType{
+		conditions.NodePoolsReady,
+		capi.ControlPlaneReadyCondition,
+		capi.InfrastructureReadyCondition,
+	}
+
+	testCases := []struct {
+		name              string
+		config            HandlerConfig
+		inputs            []*capi.Condition
+		expectedCondition *capi.Condition
+	}{
+		{
+			name:   "case 0: priority strategy selects message from the first failing condition",
+			config: HandlerConfig{Strategy: StrategyPriority},
+			inputs: []*capi.Condition{
+				capiconditions.FalseCondition(conditions.NodePoolsReady, "NodePoolsNotReady", capi.ConditionSeverityInfo, "Node pools are not ready"),
+				capiconditions.TrueCondition(capi.ControlPlaneReadyCondition),
+				capiconditions.FalseCondition(capi.InfrastructureReadyCondition, "InfrastructureNotReady", capi.ConditionSeverityWarning, "Infrastructure is not ready"),
+			},
+			expectedCondition: &capi.Condition{
+				Type:     capi.ReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   "NodePoolsNotReady",
+				Message:  "Node pools are not ready",
+			},
+		},
+		{
+			name: "case 1: input severity is capped with MaxSeverity, ties are resolved by the order of summarized conditions",
+			config: HandlerConfig{
+				InputOptions: map[capi.ConditionType]InputOptions{
+					conditions.NodePoolsReady: {MaxSeverity: capi.ConditionSeverityWarning},
+				},
+			},
+			inputs: []*capi.Condition{
+				capiconditions.FalseCondition(conditions.NodePoolsReady, "NodePoolsFailed", capi.ConditionSeverityError, "Node pools failed"),
+				capiconditions.FalseCondition(capi.ControlPlaneReadyCondition, "ControlPlaneNotReady", capi.ConditionSeverityWarning, "Control plane is not ready"),
+				capiconditions.TrueCondition(capi.InfrastructureReadyCondition),
+			},
+			expectedCondition: &capi.Condition{
+				Type:     capi.ReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   "NodePoolsFailed @ Cluster/test1",
+				Message:  "Node pools failed",
+			},
+		},
+		{
+			name: "case 2: failed optional input only downgrades the summary",
+			config: HandlerConfig{
+				InputOptions: map[capi.ConditionType]InputOptions{
+					conditions.NodePoolsReady: {Optional: true},
+				},
+			},
+			inputs: []*capi.Condition{
+				capiconditions.FalseCondition(conditions.NodePoolsReady, "NodePoolsFailed", capi.ConditionSeverityError, "Node pools failed"),
+				capiconditions.TrueCondition(capi.ControlPlaneReadyCondition),
+				capiconditions.TrueCondition(capi.InfrastructureReadyCondition),
+			},
+			expectedCondition: &capi.Condition{
+				Type:     capi.ReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   "NodePoolsFailed @ Cluster/test1",
+				Message:  "Node pools failed",
+			},
+		},
+		{
+			name: "case 3: message is rendered with message template",
+			config: HandlerConfig{
+				MessageTemplate: "{{ .Ready }}/{{ .Total }} ready{{ range .Failing }}, {{ .Type }}: {{ .Message }}{{ end }}",
+			},
+			inputs: []*capi.Condition{
+				capiconditions.FalseCondition(conditions.NodePoolsReady, "NodePoolsNotReady", capi.ConditionSeverityWarning, "Node pools are not ready"),
+				capiconditions.UnknownCondition(capi.ControlPlaneReadyCondition, "ControlPlaneUnknown", "Control plane is unknown"),
+				capiconditions.TrueCondition(capi.InfrastructureReadyCondition),
+			},
+			expectedCondition: &capi.Condition{
+				Type:     capi.ReadyCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityWarning,
+				Reason:   "NodePoolsNotReady @ Cluster/test1",
+				Message:  "1/3 ready, NodePoolsReady: Node pools are not ready, ControlPlaneReady: Control plane is unknown",
+			},
+		},
+		{
+			name: "case 4: summary is True when all inputs are True, ignored inputs are left out",
+			config: HandlerConfig{
+				Strategy: StrategyPriority,
+				IgnoreOptions: []conditions.CheckOption{
+					func(condition *capi.Condition) bool {
+						return condition.Type == conditions.NodePoolsReady
+					},
+				},
+			},
+			inputs: []*capi.Condition{
+				capiconditions.FalseCondition(conditions.NodePoolsReady, "NodePoolsNotReady", capi.ConditionSeverityWarning, "Node pools are not ready"),
+				capiconditions.TrueCondition(capi.ControlPlaneReadyCondition),
+				capiconditions.TrueCondition(capi.InfrastructureReadyCondition),
+			},
+			expectedCondition: &capi.Condition{
+				Type:   capi.ReadyCondition,
+				Status: corev1.ConditionTrue,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			cluster := newCluster(tc.inputs)
+			tc.config.ConditionsToSummarize = conditionsToSummarize
+			options, err := newSummaryOptions(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			err = updateWithStrategy(cluster, capi.ReadyCondition, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			condition := capiconditions.Get(cluster, capi.ReadyCondition)
+			if condition == nil || !internal.AreEqualWithIgnoringLastTransitionTime(condition, tc.expectedCondition) {
+				t.Logf(
+					"expected %s, got %s",
+					internal.SprintComparedCondition(tc.expectedCondition),
+					internal.SprintComparedCondition(condition))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestDefaultStrategyIsCompatibleWithSetSummary(t *testing.T) {
+	conditionsToSummarize := []capi.ConditionType{
+		conditions.NodePoolsReady,
+		capi.InfrastructureReadyCondition,
+		capi.ControlPlaneReadyCondition,
+	}
+
+	testCases := []struct {
+		name   string
+		inputs []*capi.Condition
+	}{
+		{
+			name: "case 0: all inputs are True",
+			inputs: []*capi.Condition{
+				capiconditions.TrueCondition(conditions.NodePoolsReady),
+				capiconditions.TrueCondition(capi.ControlPlaneReadyCondition),
+				capiconditions.TrueCondition(capi.InfrastructureReadyCondition),
+			},
+		},
+		{
+			name: "case 1: inputs with the same severity are tied",
+			inputs: []*capi.Condition{
+				capiconditions.FalseCondition(conditions.NodePoolsReady, "NodePoolsNotReady", capi.ConditionSeverityWarning, "Node pools are not ready"),
+				capiconditions.FalseCondition(capi.ControlPlaneReadyCondition, "ControlPlaneNotReady", capi.ConditionSeverityWarning, "Control plane is not ready"),
+				capiconditions.FalseCondition(capi.InfrastructureReadyCondition, "InfrastructureNotReady", capi.ConditionSeverityWarning, "Infrastructure is not ready"),
+			},
+		},
+		{
+			name: "case 2: input with the highest severity is selected",
+			inputs: []*capi.Condition{
+				capiconditions.FalseCondition(conditions.NodePoolsReady, "NodePoolsNotReady", capi.ConditionSeverityInfo, "Node pools are not ready"),
+				capiconditions.FalseCondition(capi.ControlPlaneReadyCondition, "ControlPlaneFailed", capi.ConditionSeverityError, "Control plane failed"),
+				capiconditions.TrueCondition(capi.InfrastructureReadyCondition),
+			},
+		},
+		{
+			name: "case 3: Unknown inputs are tied",
+			inputs: []*capi.Condition{
+				capiconditions.UnknownCondition(capi.ControlPlaneReadyCondition, "ControlPlaneUnknown", "Control plane is unknown"),
+				capiconditions.UnknownCondition(capi.InfrastructureReadyCondition, "InfrastructureUnknown", "Infrastructure is unknown"),
+				capiconditions.TrueCondition(conditions.NodePoolsReady),
+			},
+		},
+		{
+			name: "case 4: aggregated input reason already has a source ref",
+			inputs: []*capi.Condition{
+				capiconditions.FalseCondition(conditions.NodePoolsReady, "ReplicasNotReady @ MachinePool/np1", capi.ConditionSeverityWarning, "Replicas are not ready"),
+				capiconditions.TrueCondition(capi.ControlPlaneReadyCondition),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			expected := newCluster(tc.inputs)
+			update(expected, conditionsToSummarize)
+			expectedCondition := capiconditions.Get(expected, capi.ReadyCondition)
+
+			// Input options and message template that do not change the
+			// summary, but make the handler summarize with the strategy.
+			options, err := newSummaryOptions(HandlerConfig{
+				ConditionsToSummarize: conditionsToSummarize,
+				InputOptions: map[capi.ConditionType]InputOptions{
+					conditions.NodePoolsReady: {MaxSeverity: capi.ConditionSeverityError},
+				},
+				MessageTemplate: "{{ .Message }}",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster := newCluster(tc.inputs)
+
+			// act
+			err = updateWithStrategy(cluster, capi.ReadyCondition, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			condition := capiconditions.Get(cluster, capi.ReadyCondition)
+			if condition == nil || !internal.AreEqualWithIgnoringLastTransitionTime(condition, expectedCondition) {
+				t.Logf(
+					"expected %s, got %s",
+					internal.SprintComparedCondition(expectedCondition),
+					internal.SprintComparedCondition(condition))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestNewSummaryOptions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config HandlerConfig
+	}{
+		{
+			name:   "case 0: unknown strategy",
+			config: HandlerConfig{Strategy: "Unknown"},
+		},
+		{
+			name:   "case 1: invalid max severity",
+			config: HandlerConfig{InputOptions: map[capi.ConditionType]InputOptions{conditions.NodePoolsReady: {MaxSeverity: "Fatal"}}},
+		},
+		{
+			name:   "case 2: invalid message template",
+			config: HandlerConfig{MessageTemplate: "{{ .Ready "},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+
+			// act
+			_, err := newSummaryOptions(tc.config)
+
+			// assert
+			if !errors.IsInvalidConfig(err) {
+				t.Errorf("expected invalid config error, got %#v", err)
+			}
+		})
+	}
+}
+
+func newCluster(inputs []*capi.Condition) *capi.Cluster {
+	cluster := &capi.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Cluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-test",
+			Name:      "test1",
+		},
+	}
+	for _, input := range inputs {
+		capiconditions.Set(cluster, input)
+	}
+
+	return cluster
+}
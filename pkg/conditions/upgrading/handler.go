@@ -2,14 +2,20 @@ package upgrading
 
 import (
 	"context"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
 	"github.com/giantswarm/conditions-handler/pkg/internal"
 	"github.com/giantswarm/conditions-handler/pkg/key"
+	"github.com/giantswarm/conditions-handler/pkg/watch"
 )
 
 type HandlerConfig struct {
@@ -18,6 +24,17 @@ type HandlerConfig struct {
 
 	Name         string
 	UpdateStatus bool
+
+	// Optional, see history.Config and handler.Config.
+	History   history.Config
+	PostCheck handler.PostCheckFunc
+	Now       func() time.Time
+
+	// CheckNodePools enables checking Upgrading conditions of Cluster's
+	// MachinePools, so that Cluster Upgrading condition stays True until all
+	// node pools are upgraded to the desired release version. It has effect
+	// only when reconciling Cluster objects.
+	CheckNodePools bool
 }
 
 type Handler struct {
@@ -25,13 +42,15 @@ type Handler struct {
 	internalHandler *internal.Handler
 	logger          micrologger.Logger
 	name            string
+	checkNodePools  bool
 }
 
 func NewHandler(config HandlerConfig) (*Handler, error) {
 	h := &Handler{
-		ctrlClient: config.CtrlClient,
-		logger:     config.Logger,
-		name:       config.Name,
+		ctrlClient:     config.CtrlClient,
+		logger:         config.Logger,
+		name:           config.Name,
+		checkNodePools: config.CheckNodePools,
 	}
 
 	internalHandlerConfig := internal.HandlerConfig{
@@ -39,6 +58,9 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 		Logger:            config.Logger,
 		UpdateStatus:      config.UpdateStatus,
 		ConditionType:     conditions.Upgrading,
+		History:           config.History,
+		PostCheck:         config.PostCheck,
+		Now:               config.Now,
 		EnsureCreatedFunc: h.ensureCreated,
 	}
 
@@ -68,7 +90,52 @@ func (h *Handler) Name() string {
 	return h.name
 }
 
-func (h *Handler) ensureCreated(_ context.Context, object conditions.Object) error {
-	update(object)
+// Watches returns MachinePools as a watch source for Clusters when node pools
+// are checked, since Cluster Upgrading then depends on MachinePool conditions.
+func (h *Handler) Watches(reconciled schema.GroupVersionKind) []watch.Source {
+	if !h.checkNodePools || reconciled.GroupKind() != watch.ClusterGVK.GroupKind() {
+		return nil
+	}
+
+	return []watch.Source{
+		{
+			GVK:     watch.MachinePoolGVK,
+			MapFunc: watch.MachinePoolToCluster,
+		},
+	}
+}
+
+func (h *Handler) ensureCreated(ctx context.Context, object conditions.Object) error {
+	nodePools, err := h.getNodePoolsUpgradeProgress(ctx, object)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = update(object, nodePools, h.internalHandler.Now())
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
 	return nil
 }
+
+// getNodePoolsUpgradeProgress returns upgrade progress of Cluster's node
+// pools, or nil if node pools should not be checked for the specified object.
+func (h *Handler) getNodePoolsUpgradeProgress(ctx context.Context, object conditions.Object) (*nodePoolsUpgradeProgress, error) {
+	if !h.checkNodePools {
+		return nil, nil
+	}
+
+	cluster, ok := object.(*capi.Cluster)
+	if !ok {
+		return nil, nil
+	}
+
+	machinePools, err := internal.ListMachinePoolsByClusterID(ctx, h.ctrlClient, cluster.Namespace, cluster.Name)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	progress := getNodePoolsUpgradeProgress(key.ReleaseVersion(cluster), machinePools.Items)
+	return &progress, nil
+}
@@ -0,0 +1,189 @@
+package upgrading
+
+import (
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+	"github.com/giantswarm/conditions-handler/pkg/key"
+	"github.com/giantswarm/conditions-handler/pkg/statemachine"
+)
+
+const (
+	// CreatingTrue is true when the object has Creating condition with status
+	// True.
+	CreatingTrue statemachine.Fact = "CreatingTrue"
+	// FirstNodePoolUpgradeInProgress is true when the Cluster is being
+	// upgraded to its first release with node pools.
+	FirstNodePoolUpgradeInProgress statemachine.Fact = "FirstNodePoolUpgradeInProgress"
+	// LastDeployedReleaseVersionSet is true when the object has last deployed
+	// release version annotation.
+	LastDeployedReleaseVersionSet statemachine.Fact = "LastDeployedReleaseVersionSet"
+	// DesiredReleaseVersionDeployed is true when the last deployed release
+	// version is equal to the desired release version.
+	DesiredReleaseVersionDeployed statemachine.Fact = "DesiredReleaseVersionDeployed"
+	// NodePoolsUpgraded is true when all node pools of the Cluster have been
+	// upgraded, or when node pools upgrade progress is not tracked.
+	NodePoolsUpgraded statemachine.Fact = "NodePoolsUpgraded"
+
+	markUpgradingTrue                       statemachine.Action = "MarkUpgradingTrue"
+	markUpgradingTrueWithNodePoolsProgress  statemachine.Action = "MarkUpgradingTrueWithNodePoolsProgress"
+	markUpgradingFalseWithUpgradeCompleted  statemachine.Action = "MarkUpgradingFalseWithUpgradeCompleted"
+	markUpgradingFalseWithUpgradeNotStarted statemachine.Action = "MarkUpgradingFalseWithUpgradeNotStarted"
+)
+
+var actions = map[statemachine.Action]func(object conditions.Object, nodePools *nodePoolsUpgradeProgress, now time.Time){
+	markUpgradingTrue: func(object conditions.Object, _ *nodePoolsUpgradeProgress, now time.Time) {
+		markUpgradingTrueAt(object, now)
+	},
+	markUpgradingTrueWithNodePoolsProgress: func(object conditions.Object, nodePools *nodePoolsUpgradeProgress, now time.Time) {
+		markUpgradingTrueWithNodePoolsProgressAt(object, nodePools.upgraded, nodePools.total, now)
+	},
+	markUpgradingFalseWithUpgradeCompleted: func(object conditions.Object, _ *nodePoolsUpgradeProgress, now time.Time) {
+		markUpgradingFalseWithUpgradeCompletedAt(object, now)
+	},
+	markUpgradingFalseWithUpgradeNotStarted: func(object conditions.Object, _ *nodePoolsUpgradeProgress, _ time.Time) {
+		MarkUpgradingFalseWithUpgradeNotStarted(object)
+	},
+}
+
+// StateMachine returns the state-transition table of Upgrading condition.
+func StateMachine() statemachine.Table {
+	return statemachine.Table{
+		ConditionType: conditions.Upgrading,
+		Facts: []statemachine.Fact{
+			CreatingTrue,
+			FirstNodePoolUpgradeInProgress,
+			LastDeployedReleaseVersionSet,
+			DesiredReleaseVersionDeployed,
+			NodePoolsUpgraded,
+		},
+		Transitions: []statemachine.Transition{
+			{
+				// New cluster or node pool is just being created, no upgrade
+				// yet.
+				Name:   "object is being created",
+				When:   statemachine.Facts{CreatingTrue: true},
+				To:     statemachine.StateFalse,
+				Action: markUpgradingFalseWithUpgradeNotStarted,
+			},
+			{
+				// Cluster-only check, first cluster upgrade to node pools
+				// release.
+				Name: "first node pools upgrade in progress",
+				From: []statemachine.State{statemachine.StateTrue},
+				When: statemachine.Facts{FirstNodePoolUpgradeInProgress: true},
+				To:   statemachine.StateTrue,
+			},
+			{
+				Name:   "first node pools upgrade started",
+				From:   []statemachine.State{statemachine.StateUnknown, statemachine.StateFalse},
+				When:   statemachine.Facts{FirstNodePoolUpgradeInProgress: true},
+				To:     statemachine.StateTrue,
+				Action: markUpgradingTrue,
+			},
+			{
+				// Creation has not completed, so no upgrades yet. This is
+				// usually covered by the first transition, but the object
+				// maybe does not have Creating condition set.
+				Name:   "creation not completed",
+				When:   statemachine.Facts{LastDeployedReleaseVersionSet: false},
+				To:     statemachine.StateFalse,
+				Action: markUpgradingFalseWithUpgradeNotStarted,
+			},
+			{
+				// Object is still being created, or it is restored from
+				// backup.
+				Name:   "restored with desired release deployed",
+				From:   []statemachine.State{statemachine.StateUnknown},
+				When:   statemachine.Facts{DesiredReleaseVersionDeployed: true},
+				To:     statemachine.StateFalse,
+				Action: markUpgradingFalseWithUpgradeNotStarted,
+			},
+			{
+				Name:   "restored with desired release not deployed",
+				From:   []statemachine.State{statemachine.StateUnknown},
+				To:     statemachine.StateTrue,
+				Action: markUpgradingTrue,
+			},
+			{
+				// Desired release version is deployed, but not all node pools
+				// have been upgraded yet.
+				Name:   "node pools upgrade in progress",
+				From:   []statemachine.State{statemachine.StateTrue},
+				When:   statemachine.Facts{DesiredReleaseVersionDeployed: true, NodePoolsUpgraded: false},
+				To:     statemachine.StateTrue,
+				Action: markUpgradingTrueWithNodePoolsProgress,
+			},
+			{
+				Name:   "upgrade completed",
+				From:   []statemachine.State{statemachine.StateTrue},
+				When:   statemachine.Facts{DesiredReleaseVersionDeployed: true},
+				To:     statemachine.StateFalse,
+				Action: markUpgradingFalseWithUpgradeCompleted,
+			},
+			{
+				Name: "upgrade in progress",
+				From: []statemachine.State{statemachine.StateTrue},
+				To:   statemachine.StateTrue,
+			},
+			{
+				// Desired release version is different than the release to
+				// which the object was previously upgraded or with which it
+				// was created, which also covers version rollbacks.
+				Name:   "upgrade started",
+				From:   []statemachine.State{statemachine.StateFalse},
+				When:   statemachine.Facts{DesiredReleaseVersionDeployed: false},
+				To:     statemachine.StateTrue,
+				Action: markUpgradingTrue,
+			},
+			{
+				Name: "not upgrading",
+				From: []statemachine.State{statemachine.StateFalse},
+				To:   statemachine.StateFalse,
+			},
+		},
+	}
+}
+
+var stateMachine = StateMachine()
+
+// facts returns the facts about the specified object used by Upgrading
+// state-transition table.
+func facts(object conditions.Object, nodePools *nodePoolsUpgradeProgress) statemachine.Facts {
+	lastDeployedReleaseVersion, isLastDeployedReleaseVersionSet := object.GetAnnotations()[internal.LastDeployedReleaseVersion]
+
+	return statemachine.Facts{
+		CreatingTrue:                   conditions.IsCreatingTrue(object),
+		FirstNodePoolUpgradeInProgress: key.IsFirstNodePoolUpgradeInProgress(object),
+		LastDeployedReleaseVersionSet:  isLastDeployedReleaseVersionSet,
+		DesiredReleaseVersionDeployed:  isLastDeployedReleaseVersionSet && lastDeployedReleaseVersion == key.ReleaseVersion(object),
+		NodePoolsUpgraded:              nodePools.isCompleted(),
+	}
+}
+
+// execute takes the transition of Upgrading state-transition table that
+// matches the current Upgrading condition and the specified facts at the
+// specified time.
+func execute(object conditions.Object, facts statemachine.Facts, nodePools *nodePoolsUpgradeProgress, now time.Time) error {
+	state := statemachine.StateOf(capiconditions.Get(object, conditions.Upgrading))
+	transition, err := stateMachine.Next(state, facts)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if transition.Action == "" {
+		return nil
+	}
+
+	action, ok := actions[transition.Action]
+	if !ok {
+		return microerror.Maskf(errors.InvalidConfigError, "action %s is not implemented", transition.Action)
+	}
+	action(object, nodePools, now)
+
+	return nil
+}
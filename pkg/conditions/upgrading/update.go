@@ -5,11 +5,13 @@ import (
 	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
 
 	"github.com/giantswarm/conditions-handler/pkg/internal"
-	"github.com/giantswarm/conditions-handler/pkg/key"
 )
 
 // MarkUpgradingTrue sets Upgrading condition with status True.
@@ -17,14 +19,46 @@ func MarkUpgradingTrue(object conditions.Object) {
 	capiconditions.MarkTrue(object, conditions.Upgrading)
 }
 
+// markUpgradingTrueAt sets Upgrading condition like MarkUpgradingTrue, with
+// the upgrade start at the specified time.
+func markUpgradingTrueAt(object conditions.Object, now time.Time) {
+	internal.SetConditionAt(object, capiconditions.TrueCondition(conditions.Upgrading), now)
+}
+
+// MarkUpgradingTrueWithNodePoolsProgress sets Upgrading condition with status
+// True and a message informing how many node pools have been upgraded after
+// the control plane upgrade has been completed. LastTransitionTime of True
+// Upgrading condition is kept, so it still tells when the upgrade started.
+func MarkUpgradingTrueWithNodePoolsProgress(object conditions.Object, upgradedNodePools, totalNodePools int) {
+	markUpgradingTrueWithNodePoolsProgressAt(object, upgradedNodePools, totalNodePools, time.Now())
+}
+
+// markUpgradingTrueWithNodePoolsProgressAt sets Upgrading condition like
+// MarkUpgradingTrueWithNodePoolsProgress, with the upgrade start at the
+// specified time when Upgrading condition is not True yet.
+func markUpgradingTrueWithNodePoolsProgressAt(object conditions.Object, upgradedNodePools, totalNodePools int, now time.Time) {
+	internal.SetConditionAt(object, &capi.Condition{
+		Type:    conditions.Upgrading,
+		Status:  corev1.ConditionTrue,
+		Message: fmt.Sprintf("Control plane upgrade done, %d/%d node pools upgraded", upgradedNodePools, totalNodePools),
+	}, now)
+}
+
 // MarkUpgradingFalseWithUpgradeCompleted sets Upgrading condition with status
 // False, reason UpgradeCompleted, severity Info and a message informing how
 // long the upgrade took.
 func MarkUpgradingFalseWithUpgradeCompleted(object conditions.Object) {
+	markUpgradingFalseWithUpgradeCompletedAt(object, time.Now())
+}
+
+// markUpgradingFalseWithUpgradeCompletedAt sets Upgrading condition like
+// MarkUpgradingFalseWithUpgradeCompleted, with the upgrade duration measured
+// until the specified time.
+func markUpgradingFalseWithUpgradeCompletedAt(object conditions.Object, now time.Time) {
 	var upgradeTimeMessage string
 	currentUpgradingCondition := capiconditions.Get(object, conditions.Upgrading)
 	if currentUpgradingCondition != nil {
-		upgradeDuration := time.Since(currentUpgradingCondition.LastTransitionTime.Time)
+		upgradeDuration := now.Sub(currentUpgradingCondition.LastTransitionTime.Time)
 		upgradeTimeMessage = fmt.Sprintf(" in %s", upgradeDuration)
 	} else {
 		upgradeTimeMessage = ", but upgrade duration cannot be determined"
@@ -51,61 +85,45 @@ func MarkUpgradingFalseWithUpgradeNotStarted(object conditions.Object) {
 		"Upgrade has not been started")
 }
 
-func update(object conditions.Object) {
-	// Case 1: new cluster or node pool is just being created, no upgrade yet.
-	if conditions.IsCreatingTrue(object) {
-		MarkUpgradingFalseWithUpgradeNotStarted(object)
-		return
-	}
+// nodePoolsUpgradeProgress holds the number of Cluster's node pools and how
+// many of them are already upgraded to the Cluster's desired release version.
+type nodePoolsUpgradeProgress struct {
+	upgraded int
+	total    int
+}
 
-	// Case 2: Cluster-only check, first cluster upgrade to node pools release,
-	// a bit of an edgecase, albeit an important one :)
-	if key.IsFirstNodePoolUpgradeInProgress(object) {
-		if !conditions.IsUpgradingTrue(object) {
-			MarkUpgradingTrue(object)
-		}
-		return
-	}
+func (p *nodePoolsUpgradeProgress) isCompleted() bool {
+	return p == nil || p.upgraded >= p.total
+}
 
-	// Let's check what was the last release version that we successfully deployed.
-	lastDeployedReleaseVersion, isLastDeployedReleaseVersionSet := object.GetAnnotations()[internal.LastDeployedReleaseVersion]
-	if !isLastDeployedReleaseVersionSet {
-		// Case 3: Last deployed release version annotation is not set at all,
-		// which means that cluster or node pool creation has not completed, so
-		// no upgrades yet.
-		// This case should be already processed by Creating condition handler,
-		// and we would have Case 1 from above, but here we check just in case,
-		// since the reconciled object maybe does not have Creating condition
-		// set.
-		MarkUpgradingFalseWithUpgradeNotStarted(object)
-		return
+// getNodePoolsUpgradeProgress counts node pools that have reached the desired
+// release version, i.e. node pools that have the desired release version
+// last deployed and that are not being upgraded anymore.
+func getNodePoolsUpgradeProgress(desiredReleaseVersion string, machinePools []capiexp.MachinePool) nodePoolsUpgradeProgress {
+	progress := nodePoolsUpgradeProgress{
+		total: len(machinePools),
 	}
 
-	// Let's now check if desired release version is deployed.
-	desiredReleaseVersion := key.ReleaseVersion(object)
-	desiredReleaseVersionIsDeployed := lastDeployedReleaseVersion == desiredReleaseVersion
+	for i := range machinePools {
+		machinePool := &machinePools[i]
+		lastDeployedReleaseVersion := machinePool.GetAnnotations()[internal.LastDeployedReleaseVersion]
+		if lastDeployedReleaseVersion == desiredReleaseVersion && !conditions.IsUpgradingTrue(machinePool) {
+			progress.upgraded++
+		}
+	}
 
-	currentUpgrading, isSet := conditions.GetUpgrading(object)
+	return progress
+}
 
-	if !isSet || conditions.IsUnknown(&currentUpgrading) {
-		// Case 4: Cluster or node pool is still being created, or it's restored
-		// from backup, this case should be very rare and almost never happen.
-		if desiredReleaseVersionIsDeployed {
-			MarkUpgradingFalseWithUpgradeNotStarted(object)
-		} else {
-			MarkUpgradingTrue(object)
-		}
-	} else if conditions.IsTrue(&currentUpgrading) && desiredReleaseVersionIsDeployed {
-		// Case 5: Cluster or node pool was being upgraded.
-		// Also, last deployed release version for this object is equal to the
-		// desired release version, so we can conclude that the upgrade has been
-		// completed.
-		MarkUpgradingFalseWithUpgradeCompleted(object)
-	} else if conditions.IsFalse(&currentUpgrading) && !desiredReleaseVersionIsDeployed {
-		// Case 6: Cluster or node pool was not being upgraded.
-		// Also, desired release for this cluster is different than the release
-		// to which it was previously upgraded or with which was created, so we
-		// can conclude that the cluster is in the Upgrading state.
-		MarkUpgradingTrue(object)
+// update sets Upgrading condition on specified object by executing Upgrading
+// state-transition table, see StateMachine. When node pools upgrade progress
+// is specified, Upgrading condition stays True after the desired release
+// version has been deployed until all node pools are upgraded as well.
+func update(object conditions.Object, nodePools *nodePoolsUpgradeProgress, now time.Time) error {
+	err := execute(object, facts(object, nodePools), nodePools, now)
+	if err != nil {
+		return microerror.Mask(err)
 	}
+
+	return nil
 }
@@ -2,9 +2,13 @@ package upgrading
 
 import (
 	"testing"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
 
 	"github.com/giantswarm/conditions-handler/pkg/internal"
@@ -72,3 +76,171 @@ func TestMarkUpgradingFalseWithUpgradeNotStarted(t *testing.T) {
 		}
 	})
 }
+
+func TestUpdateWithNodePoolsProgress(t *testing.T) {
+	testCases := []struct {
+		name              string
+		nodePools         *nodePoolsUpgradeProgress
+		expectedCondition *capi.Condition
+	}{
+		{
+			name: "case 0: node pools are not checked, upgrade is completed",
+			expectedCondition: &capi.Condition{
+				Type:     conditions.Upgrading,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   conditions.UpgradeCompletedReason,
+			},
+		},
+		{
+			name:      "case 1: some node pools are not upgraded, upgrade is in progress",
+			nodePools: &nodePoolsUpgradeProgress{upgraded: 2, total: 5},
+			expectedCondition: &capi.Condition{
+				Type:    conditions.Upgrading,
+				Status:  corev1.ConditionTrue,
+				Message: "Control plane upgrade done, 2/5 node pools upgraded",
+			},
+		},
+		{
+			name:      "case 2: all node pools are upgraded, upgrade is completed",
+			nodePools: &nodePoolsUpgradeProgress{upgraded: 5, total: 5},
+			expectedCondition: &capi.Condition{
+				Type:     conditions.Upgrading,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   conditions.UpgradeCompletedReason,
+			},
+		},
+		{
+			name:      "case 3: cluster without node pools, upgrade is completed",
+			nodePools: &nodePoolsUpgradeProgress{},
+			expectedCondition: &capi.Condition{
+				Type:     conditions.Upgrading,
+				Status:   corev1.ConditionFalse,
+				Severity: capi.ConditionSeverityInfo,
+				Reason:   conditions.UpgradeCompletedReason,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			cluster := newUpgradedCluster("15.0.0")
+			MarkUpgradingTrue(cluster)
+
+			// act
+			err := update(cluster, tc.nodePools, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			upgrading := capiconditions.Get(cluster, conditions.Upgrading)
+			if upgrading == nil {
+				t.Fatalf("Upgrading condition not set, expected %s", internal.SprintComparedCondition(tc.expectedCondition))
+			}
+
+			// Upgrade duration in the message cannot be known in advance.
+			if upgrading.Reason == conditions.UpgradeCompletedReason {
+				upgrading.Message = ""
+			}
+
+			if !internal.AreEqualWithIgnoringLastTransitionTime(upgrading, tc.expectedCondition) {
+				t.Logf(
+					"expected %s, got %s",
+					internal.SprintComparedCondition(tc.expectedCondition),
+					internal.SprintComparedCondition(upgrading))
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestUpgradeDurationWithNodePoolsProgress(t *testing.T) {
+	testName := "upgrade duration covers the whole upgrade when node pools are upgraded one by one"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		start := time.Date(2022, 3, 31, 10, 0, 0, 0, time.UTC)
+		cluster := newUpgradedCluster("14.2.0")
+		MarkUpgradingFalseWithUpgradeNotStarted(cluster)
+		err := update(cluster, &nodePoolsUpgradeProgress{total: 3}, start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cluster.Annotations[internal.LastDeployedReleaseVersion] = "15.0.0"
+
+		// act
+		for upgraded := 1; upgraded <= 3; upgraded++ {
+			now := start.Add(time.Duration(upgraded) * 10 * time.Minute)
+			err = update(cluster, &nodePoolsUpgradeProgress{upgraded: upgraded, total: 3}, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// assert
+		expected := &capi.Condition{
+			Type:     conditions.Upgrading,
+			Status:   corev1.ConditionFalse,
+			Severity: capi.ConditionSeverityInfo,
+			Reason:   conditions.UpgradeCompletedReason,
+			Message:  "Upgrade has been completed in 30m0s",
+		}
+		upgrading := capiconditions.Get(cluster, conditions.Upgrading)
+		if upgrading == nil || !internal.AreEqualWithIgnoringLastTransitionTime(upgrading, expected) {
+			t.Logf(
+				"expected %s, got %s",
+				internal.SprintComparedCondition(expected),
+				internal.SprintComparedCondition(upgrading))
+			t.Fail()
+		}
+	})
+}
+
+func TestGetNodePoolsUpgradeProgress(t *testing.T) {
+	testName := "node pools with desired last deployed version and without Upgrading=True are counted as upgraded"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		upgraded := newUpgradedMachinePool("15.0.0")
+		notDeployed := newUpgradedMachinePool("14.2.0")
+		stillUpgrading := newUpgradedMachinePool("15.0.0")
+		MarkUpgradingTrue(stillUpgrading)
+		machinePools := []capiexp.MachinePool{*upgraded, *notDeployed, *stillUpgrading}
+
+		// act
+		progress := getNodePoolsUpgradeProgress("15.0.0", machinePools)
+
+		// assert
+		if progress.upgraded != 1 || progress.total != 3 {
+			t.Logf("expected 1/3 node pools upgraded, got %d/%d", progress.upgraded, progress.total)
+			t.Fail()
+		}
+	})
+}
+
+func newUpgradedCluster(lastDeployedReleaseVersion string) *capi.Cluster {
+	return &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"release.giantswarm.io/version": "15.0.0",
+			},
+			Annotations: map[string]string{
+				internal.LastDeployedReleaseVersion: lastDeployedReleaseVersion,
+			},
+		},
+	}
+}
+
+func newUpgradedMachinePool(lastDeployedReleaseVersion string) *capiexp.MachinePool {
+	return &capiexp.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				internal.LastDeployedReleaseVersion: lastDeployedReleaseVersion,
+			},
+		},
+	}
+}
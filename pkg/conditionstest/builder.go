@@ -0,0 +1,266 @@
+package conditionstest
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+const (
+	releaseVersionLabel = "release.giantswarm.io/version"
+)
+
+// ClusterBuilder builds Cluster objects.
+type ClusterBuilder struct {
+	cluster *capi.Cluster
+}
+
+// NewCluster returns a builder of a Cluster with the specified namespace and
+// name.
+func NewCluster(namespace, name string) *ClusterBuilder {
+	return &ClusterBuilder{
+		cluster: &capi.Cluster{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: capi.GroupVersion.String(),
+				Kind:       "Cluster",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+		},
+	}
+}
+
+// WithLabel sets a label.
+func (b *ClusterBuilder) WithLabel(key, value string) *ClusterBuilder {
+	setLabel(&b.cluster.ObjectMeta, key, value)
+	return b
+}
+
+// WithAnnotation sets an annotation.
+func (b *ClusterBuilder) WithAnnotation(key, value string) *ClusterBuilder {
+	setAnnotation(&b.cluster.ObjectMeta, key, value)
+	return b
+}
+
+// WithReleaseVersion sets desired release version label.
+func (b *ClusterBuilder) WithReleaseVersion(version string) *ClusterBuilder {
+	return b.WithLabel(releaseVersionLabel, version)
+}
+
+// WithLastDeployedReleaseVersion sets last deployed release version
+// annotation.
+func (b *ClusterBuilder) WithLastDeployedReleaseVersion(version string) *ClusterBuilder {
+	return b.WithAnnotation(internal.LastDeployedReleaseVersion, version)
+}
+
+// WithCreationTimestamp sets creation timestamp.
+func (b *ClusterBuilder) WithCreationTimestamp(t time.Time) *ClusterBuilder {
+	b.cluster.CreationTimestamp = metav1.NewTime(t)
+	return b
+}
+
+// WithInfrastructureRef sets infrastructure reference to the specified mock
+// provider object.
+func (b *ClusterBuilder) WithInfrastructureRef(object *MockProviderCluster) *ClusterBuilder {
+	b.cluster.Spec.InfrastructureRef = objectReference(object)
+	return b
+}
+
+// WithControlPlaneRef sets control plane reference to the specified mock
+// provider object.
+func (b *ClusterBuilder) WithControlPlaneRef(object *MockProviderCluster) *ClusterBuilder {
+	b.cluster.Spec.ControlPlaneRef = objectReference(object)
+	return b
+}
+
+// WithCondition sets a condition.
+func (b *ClusterBuilder) WithCondition(condition *capi.Condition) *ClusterBuilder {
+	capiconditions.Set(b.cluster, condition)
+	return b
+}
+
+// Paused sets Spec.Paused.
+func (b *ClusterBuilder) Paused() *ClusterBuilder {
+	b.cluster.Spec.Paused = true
+	return b
+}
+
+// Build returns a copy of the built Cluster.
+func (b *ClusterBuilder) Build() *capi.Cluster {
+	return b.cluster.DeepCopy()
+}
+
+// MachinePoolBuilder builds MachinePool objects.
+type MachinePoolBuilder struct {
+	machinePool *capiexp.MachinePool
+}
+
+// NewMachinePool returns a builder of a MachinePool with the specified
+// namespace and name.
+func NewMachinePool(namespace, name string) *MachinePoolBuilder {
+	return &MachinePoolBuilder{
+		machinePool: &capiexp.MachinePool{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: capiexp.GroupVersion.String(),
+				Kind:       "MachinePool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+		},
+	}
+}
+
+// ForCluster sets cluster name label, Spec.ClusterName and the cluster name
+// of the machine template.
+func (b *MachinePoolBuilder) ForCluster(clusterName string) *MachinePoolBuilder {
+	setLabel(&b.machinePool.ObjectMeta, capi.ClusterLabelName, clusterName)
+	b.machinePool.Spec.ClusterName = clusterName
+	b.machinePool.Spec.Template.Spec.ClusterName = clusterName
+	return b
+}
+
+// WithLabel sets a label.
+func (b *MachinePoolBuilder) WithLabel(key, value string) *MachinePoolBuilder {
+	setLabel(&b.machinePool.ObjectMeta, key, value)
+	return b
+}
+
+// WithAnnotation sets an annotation.
+func (b *MachinePoolBuilder) WithAnnotation(key, value string) *MachinePoolBuilder {
+	setAnnotation(&b.machinePool.ObjectMeta, key, value)
+	return b
+}
+
+// WithReleaseVersion sets desired release version label.
+func (b *MachinePoolBuilder) WithReleaseVersion(version string) *MachinePoolBuilder {
+	return b.WithLabel(releaseVersionLabel, version)
+}
+
+// WithLastDeployedReleaseVersion sets last deployed release version
+// annotation.
+func (b *MachinePoolBuilder) WithLastDeployedReleaseVersion(version string) *MachinePoolBuilder {
+	return b.WithAnnotation(internal.LastDeployedReleaseVersion, version)
+}
+
+// WithReplicas sets desired replicas, and observed and ready replicas with
+// provider IDs and node references.
+func (b *MachinePoolBuilder) WithReplicas(desired, ready int32) *MachinePoolBuilder {
+	b.machinePool.Spec.Replicas = &desired
+	b.machinePool.Spec.ProviderIDList = nil
+	b.machinePool.Status.NodeRefs = nil
+	for i := int32(0); i < desired; i++ {
+		b.machinePool.Spec.ProviderIDList = append(b.machinePool.Spec.ProviderIDList, fmt.Sprintf("mock:///%s-%d", b.machinePool.Name, i))
+	}
+	for i := int32(0); i < ready; i++ {
+		b.machinePool.Status.NodeRefs = append(b.machinePool.Status.NodeRefs, corev1.ObjectReference{
+			Kind: "Node",
+			Name: fmt.Sprintf("%s-%d", b.machinePool.Name, i),
+		})
+	}
+	b.machinePool.Status.Replicas = desired
+	b.machinePool.Status.ReadyReplicas = ready
+	return b
+}
+
+// WithInfrastructureRef sets infrastructure reference to the specified mock
+// provider object.
+func (b *MachinePoolBuilder) WithInfrastructureRef(object *MockProviderCluster) *MachinePoolBuilder {
+	b.machinePool.Spec.Template.Spec.InfrastructureRef = *objectReference(object)
+	return b
+}
+
+// WithCondition sets a condition.
+func (b *MachinePoolBuilder) WithCondition(condition *capi.Condition) *MachinePoolBuilder {
+	capiconditions.Set(b.machinePool, condition)
+	return b
+}
+
+// Build returns a copy of the built MachinePool.
+func (b *MachinePoolBuilder) Build() *capiexp.MachinePool {
+	return b.machinePool.DeepCopy()
+}
+
+// MockProviderClusterBuilder builds mock provider objects, which can be used
+// as infrastructure or control plane objects.
+type MockProviderClusterBuilder struct {
+	object *MockProviderCluster
+}
+
+// NewMockProviderCluster returns a builder of a mock provider object with the
+// specified namespace and name.
+func NewMockProviderCluster(namespace, name string) *MockProviderClusterBuilder {
+	return &MockProviderClusterBuilder{
+		object: &MockProviderCluster{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: MockGroupVersion.String(),
+				Kind:       "MockProviderCluster",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+		},
+	}
+}
+
+// Ready sets Ready condition with status True.
+func (b *MockProviderClusterBuilder) Ready() *MockProviderClusterBuilder {
+	return b.WithCondition(capiconditions.TrueCondition(capi.ReadyCondition))
+}
+
+// NotReady sets Ready condition with status False.
+func (b *MockProviderClusterBuilder) NotReady(reason string, severity capi.ConditionSeverity, message string) *MockProviderClusterBuilder {
+	return b.WithCondition(capiconditions.FalseCondition(capi.ReadyCondition, reason, severity, "%s", message))
+}
+
+// ReadyUnknown sets Ready condition with status Unknown.
+func (b *MockProviderClusterBuilder) ReadyUnknown(reason, message string) *MockProviderClusterBuilder {
+	return b.WithCondition(capiconditions.UnknownCondition(capi.ReadyCondition, reason, "%s", message))
+}
+
+// WithCondition sets a condition.
+func (b *MockProviderClusterBuilder) WithCondition(condition *capi.Condition) *MockProviderClusterBuilder {
+	capiconditions.Set(b.object, condition)
+	return b
+}
+
+// Build returns a copy of the built mock provider object.
+func (b *MockProviderClusterBuilder) Build() *MockProviderCluster {
+	object := &MockProviderCluster{}
+	b.object.DeepCopyInto(object)
+	return object
+}
+
+func objectReference(object *MockProviderCluster) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: object.APIVersion,
+		Kind:       object.Kind,
+		Namespace:  object.Namespace,
+		Name:       object.Name,
+	}
+}
+
+func setLabel(meta *metav1.ObjectMeta, key, value string) {
+	if meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+	meta.Labels[key] = value
+}
+
+func setAnnotation(meta *metav1.ObjectMeta, key, value string) {
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[key] = value
+}
@@ -0,0 +1,164 @@
+package conditionstest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega/types"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/infrastructureready"
+)
+
+func TestInfrastructureReadyWithBuilders(t *testing.T) {
+	testCases := []struct {
+		name           string
+		infrastructure *MockProviderCluster
+		matcher        types.GomegaMatcher
+	}{
+		{
+			name:           "case 0: infrastructure object is ready",
+			infrastructure: NewMockProviderCluster("org-test", "test1").Ready().Build(),
+			matcher:        HaveConditionTrue(capi.InfrastructureReadyCondition),
+		},
+		{
+			name:           "case 1: infrastructure object is not ready",
+			infrastructure: NewMockProviderCluster("org-test", "test1").NotReady("Something", capi.ConditionSeverityWarning, "Infrastructure is not ready").Build(),
+			matcher:        HaveCondition(capi.InfrastructureReadyCondition, corev1.ConditionFalse, "Something", capi.ConditionSeverityWarning),
+		},
+		{
+			name:           "case 2: infrastructure object without Ready condition",
+			infrastructure: NewMockProviderCluster("org-test", "test1").Build(),
+			matcher:        HaveCondition(capi.InfrastructureReadyCondition, corev1.ConditionFalse, capi.WaitingForInfrastructureFallbackReason, ""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			ctx := context.Background()
+			cluster := NewCluster("org-test", "test1").
+				WithReleaseVersion("20.0.0").
+				WithInfrastructureRef(tc.infrastructure).
+				Build()
+			client := NewFakeClient(cluster, tc.infrastructure)
+
+			handler, err := infrastructureready.NewHandler(infrastructureready.HandlerConfig{
+				CtrlClient: client,
+				Logger:     newLogger(t),
+				Name:       "testInfrastructureReadyHandler",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			err = handler.EnsureCreated(ctx, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			ok, err := tc.matcher.Match(cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Error(tc.matcher.FailureMessage(cluster))
+			}
+		})
+	}
+}
+
+func TestHaveCondition(t *testing.T) {
+	cluster := NewCluster("org-test", "test1").
+		WithCondition(capiconditions.FalseCondition(capi.ReadyCondition, "Something", capi.ConditionSeverityWarning, "Not ready")).
+		Build()
+
+	testCases := []struct {
+		name     string
+		actual   interface{}
+		matcher  types.GomegaMatcher
+		expected bool
+		err      bool
+	}{
+		{
+			name:     "case 0: status, reason and severity match",
+			actual:   cluster,
+			matcher:  HaveCondition(capi.ReadyCondition, corev1.ConditionFalse, "Something", capi.ConditionSeverityWarning),
+			expected: true,
+		},
+		{
+			name:     "case 1: empty reason and severity match any",
+			actual:   cluster,
+			matcher:  HaveCondition(capi.ReadyCondition, corev1.ConditionFalse, "", ""),
+			expected: true,
+		},
+		{
+			name:     "case 2: reason does not match",
+			actual:   cluster,
+			matcher:  HaveCondition(capi.ReadyCondition, corev1.ConditionFalse, "Other", ""),
+			expected: false,
+		},
+		{
+			name:     "case 3: condition is not set",
+			actual:   cluster,
+			matcher:  HaveConditionTrue(capi.InfrastructureReadyCondition),
+			expected: false,
+		},
+		{
+			name:    "case 4: actual object is not a conditions getter",
+			actual:  "test1",
+			matcher: HaveConditionTrue(capi.ReadyCondition),
+			err:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			ok, err := tc.matcher.Match(tc.actual)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.expected {
+				t.Errorf("expected match %t, got %t: %s", tc.expected, ok, tc.matcher.FailureMessage(tc.actual))
+			}
+		})
+	}
+}
+
+func TestMachinePoolBuilder(t *testing.T) {
+	machinePool := NewMachinePool("org-test", "np1").
+		ForCluster("test1").
+		WithReplicas(3, 2).
+		Build()
+
+	if machinePool.Labels[capi.ClusterLabelName] != "test1" || machinePool.Spec.ClusterName != "test1" {
+		t.Errorf("expected MachinePool to belong to Cluster test1, got label %q and cluster name %q", machinePool.Labels[capi.ClusterLabelName], machinePool.Spec.ClusterName)
+	}
+	if *machinePool.Spec.Replicas != 3 || len(machinePool.Spec.ProviderIDList) != 3 {
+		t.Errorf("expected 3 desired replicas, got %d with %d provider IDs", *machinePool.Spec.Replicas, len(machinePool.Spec.ProviderIDList))
+	}
+	if machinePool.Status.ReadyReplicas != 2 || len(machinePool.Status.NodeRefs) != 2 {
+		t.Errorf("expected 2 ready replicas, got %d with %d node refs", machinePool.Status.ReadyReplicas, len(machinePool.Status.NodeRefs))
+	}
+
+	client := NewFakeClient(machinePool)
+	err := client.Get(context.Background(), ctrl.ObjectKeyFromObject(machinePool), machinePool)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
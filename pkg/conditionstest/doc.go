@@ -0,0 +1,6 @@
+// Package conditionstest provides helpers for testing condition handlers:
+// a scheme and fake client with Cluster API and mock provider types,
+// builders of Cluster, MachinePool and mock provider objects in any state,
+// gomega matchers for conditions, and golden test scenarios run by
+// RunScenarios, whose golden files are updated with the -update test flag.
+package conditionstest
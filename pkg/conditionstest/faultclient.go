@@ -0,0 +1,415 @@
+package conditionstest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+// Operation is a client operation into which faults can be injected.
+type Operation string
+
+const (
+	OperationGet          Operation = "Get"
+	OperationList         Operation = "List"
+	OperationCreate       Operation = "Create"
+	OperationUpdate       Operation = "Update"
+	OperationPatch        Operation = "Patch"
+	OperationDelete       Operation = "Delete"
+	OperationDeleteAllOf  Operation = "DeleteAllOf"
+	OperationStatusUpdate Operation = "StatusUpdate"
+	OperationStatusPatch  Operation = "StatusPatch"
+)
+
+// ErrorType is a k8s API error returned by an injected fault.
+type ErrorType string
+
+const (
+	ErrorConflict  ErrorType = "Conflict"
+	ErrorNotFound  ErrorType = "NotFound"
+	ErrorTimeout   ErrorType = "Timeout"
+	ErrorForbidden ErrorType = "Forbidden"
+)
+
+// Fault describes a failure injected into matching client calls. A call
+// matches when its operation and the kind of its object match, and when it is
+// one of the specified calls among all matching calls.
+type Fault struct {
+	// Operations are the operations the fault is injected into. Empty
+	// matches all operations.
+	Operations []Operation
+	// GroupVersionKinds are the kinds of objects the fault is injected for.
+	// For List operations the kind of list items is matched. Empty matches
+	// all kinds.
+	GroupVersionKinds []schema.GroupVersionKind
+	// Calls are 1-based numbers of matching calls the fault is injected
+	// into, e.g. 2 injects the fault only into the second matching call.
+	// Empty injects the fault into all matching calls.
+	Calls []int
+
+	// Error is the type of k8s API error returned instead of executing the
+	// call.
+	Error ErrorType
+	// Err is an arbitrary error returned instead of executing the call. It
+	// must not be set together with Error.
+	Err error
+	// Latency delays the call. When the context is done before, context
+	// error is returned.
+	Latency time.Duration
+	// StaleRead makes Get and List calls return the object or the list that
+	// was returned by the first successful read, instead of the current one.
+	StaleRead bool
+}
+
+type FaultClientConfig struct {
+	Client ctrl.Client
+	Faults []Fault
+}
+
+// FaultClient is a ctrl.Client decorator that injects configured faults into
+// calls of the wrapped client. It is meant for testing how handlers behave
+// when the k8s API fails, is slow or returns outdated objects.
+type FaultClient struct {
+	ctrl.Client
+
+	mutex     sync.Mutex
+	faults    []Fault
+	matched   []int
+	injected  map[Operation]int
+	snapshots map[string]runtime.Object
+}
+
+func NewFaultClient(config FaultClientConfig) (*FaultClient, error) {
+	if config.Client == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Client must not be empty", config)
+	}
+	for i, fault := range config.Faults {
+		err := fault.validate()
+		if err != nil {
+			return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Faults[%d] %s", config, i, err)
+		}
+	}
+
+	c := &FaultClient{
+		Client:    config.Client,
+		faults:    config.Faults,
+		matched:   make([]int, len(config.Faults)),
+		injected:  map[Operation]int{},
+		snapshots: map[string]runtime.Object{},
+	}
+
+	return c, nil
+}
+
+// Injected returns the number of calls of the specified operation into which
+// a fault was injected.
+func (c *FaultClient) Injected(operation Operation) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.injected[operation]
+}
+
+func (c *FaultClient) Get(ctx context.Context, key ctrl.ObjectKey, obj ctrl.Object) error {
+	gvk := c.gvkForObject(obj)
+	snapshotKey := fmt.Sprintf("object/%T/%s/%s", obj, gvk, key)
+
+	stale, err := c.inject(ctx, OperationGet, gvk, key.Name)
+	if err != nil {
+		return err
+	}
+	if stale && c.restoreSnapshot(snapshotKey, obj) {
+		return nil
+	}
+
+	err = c.Client.Get(ctx, key, obj)
+	if err != nil {
+		return err
+	}
+	c.saveSnapshot(snapshotKey, obj)
+
+	return nil
+}
+
+func (c *FaultClient) List(ctx context.Context, list ctrl.ObjectList, opts ...ctrl.ListOption) error {
+	gvk := c.gvkForObject(list)
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	listOptions := &ctrl.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	var labelSelector, fieldSelector string
+	if listOptions.LabelSelector != nil {
+		labelSelector = listOptions.LabelSelector.String()
+	}
+	if listOptions.FieldSelector != nil {
+		fieldSelector = listOptions.FieldSelector.String()
+	}
+	snapshotKey := fmt.Sprintf("list/%T/%s/%s/%s/%s", list, gvk, listOptions.Namespace, labelSelector, fieldSelector)
+
+	stale, err := c.inject(ctx, OperationList, gvk, "")
+	if err != nil {
+		return err
+	}
+	if stale && c.restoreSnapshot(snapshotKey, list) {
+		return nil
+	}
+
+	err = c.Client.List(ctx, list, opts...)
+	if err != nil {
+		return err
+	}
+	c.saveSnapshot(snapshotKey, list)
+
+	return nil
+}
+
+func (c *FaultClient) Create(ctx context.Context, obj ctrl.Object, opts ...ctrl.CreateOption) error {
+	_, err := c.inject(ctx, OperationCreate, c.gvkForObject(obj), obj.GetName())
+	if err != nil {
+		return err
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *FaultClient) Update(ctx context.Context, obj ctrl.Object, opts ...ctrl.UpdateOption) error {
+	_, err := c.inject(ctx, OperationUpdate, c.gvkForObject(obj), obj.GetName())
+	if err != nil {
+		return err
+	}
+
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *FaultClient) Patch(ctx context.Context, obj ctrl.Object, patch ctrl.Patch, opts ...ctrl.PatchOption) error {
+	_, err := c.inject(ctx, OperationPatch, c.gvkForObject(obj), obj.GetName())
+	if err != nil {
+		return err
+	}
+
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *FaultClient) Delete(ctx context.Context, obj ctrl.Object, opts ...ctrl.DeleteOption) error {
+	_, err := c.inject(ctx, OperationDelete, c.gvkForObject(obj), obj.GetName())
+	if err != nil {
+		return err
+	}
+
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *FaultClient) DeleteAllOf(ctx context.Context, obj ctrl.Object, opts ...ctrl.DeleteAllOfOption) error {
+	_, err := c.inject(ctx, OperationDeleteAllOf, c.gvkForObject(obj), "")
+	if err != nil {
+		return err
+	}
+
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *FaultClient) Status() ctrl.StatusWriter {
+	return &faultStatusWriter{
+		client: c,
+		writer: c.Client.Status(),
+	}
+}
+
+type faultStatusWriter struct {
+	client *FaultClient
+	writer ctrl.StatusWriter
+}
+
+func (w *faultStatusWriter) Update(ctx context.Context, obj ctrl.Object, opts ...ctrl.UpdateOption) error {
+	_, err := w.client.inject(ctx, OperationStatusUpdate, w.client.gvkForObject(obj), obj.GetName())
+	if err != nil {
+		return err
+	}
+
+	return w.writer.Update(ctx, obj, opts...)
+}
+
+func (w *faultStatusWriter) Patch(ctx context.Context, obj ctrl.Object, patch ctrl.Patch, opts ...ctrl.PatchOption) error {
+	_, err := w.client.inject(ctx, OperationStatusPatch, w.client.gvkForObject(obj), obj.GetName())
+	if err != nil {
+		return err
+	}
+
+	return w.writer.Patch(ctx, obj, patch, opts...)
+}
+
+// inject applies all faults matching the call. It returns an error when the
+// call must fail, and whether a stale read was requested.
+func (c *FaultClient) inject(ctx context.Context, operation Operation, gvk schema.GroupVersionKind, name string) (bool, error) {
+	var latency time.Duration
+	var stale bool
+	var err error
+
+	c.mutex.Lock()
+	injected := false
+	for i, fault := range c.faults {
+		if !fault.matches(operation, gvk) {
+			continue
+		}
+		c.matched[i]++
+		if !fault.matchesCall(c.matched[i]) {
+			continue
+		}
+
+		injected = true
+		latency += fault.Latency
+		stale = stale || fault.StaleRead
+		if err == nil {
+			err = fault.error(gvk, name)
+		}
+	}
+	if injected {
+		c.injected[operation]++
+	}
+	c.mutex.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return stale, err
+}
+
+func (c *FaultClient) gvkForObject(obj runtime.Object) schema.GroupVersionKind {
+	gvk, err := apiutil.GVKForObject(obj, c.Client.Scheme())
+	if err != nil {
+		return obj.GetObjectKind().GroupVersionKind()
+	}
+
+	return gvk
+}
+
+func (c *FaultClient) saveSnapshot(key string, obj runtime.Object) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.snapshots[key]; !ok {
+		c.snapshots[key] = obj.DeepCopyObject()
+	}
+}
+
+func (c *FaultClient) restoreSnapshot(key string, obj runtime.Object) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	snapshot, ok := c.snapshots[key]
+	if !ok {
+		return false
+	}
+
+	// Copy the snapshot into the specified object.
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(snapshot.DeepCopyObject()).Elem())
+	return true
+}
+
+func (f Fault) validate() error {
+	switch f.Error {
+	case "", ErrorConflict, ErrorNotFound, ErrorTimeout, ErrorForbidden:
+	default:
+		return fmt.Errorf("has unknown error type %q", f.Error)
+	}
+	if f.Error != "" && f.Err != nil {
+		return fmt.Errorf("must not have both Error and Err set")
+	}
+	if f.Error == "" && f.Err == nil && f.Latency == 0 && !f.StaleRead {
+		return fmt.Errorf("must have Error, Err, Latency or StaleRead set")
+	}
+	if f.StaleRead {
+		for _, operation := range f.Operations {
+			if operation != OperationGet && operation != OperationList {
+				return fmt.Errorf("with StaleRead must not have %s operation", operation)
+			}
+		}
+	}
+	for _, call := range f.Calls {
+		if call < 1 {
+			return fmt.Errorf("must have calls greater than 0, got %d", call)
+		}
+	}
+
+	return nil
+}
+
+func (f Fault) matches(operation Operation, gvk schema.GroupVersionKind) bool {
+	operationMatches := len(f.Operations) == 0
+	for _, o := range f.Operations {
+		if o == operation {
+			operationMatches = true
+			break
+		}
+	}
+	if !operationMatches {
+		return false
+	}
+
+	if len(f.GroupVersionKinds) == 0 {
+		return true
+	}
+	for _, g := range f.GroupVersionKinds {
+		if g == gvk {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Fault) matchesCall(call int) bool {
+	if len(f.Calls) == 0 {
+		return true
+	}
+	for _, c := range f.Calls {
+		if c == call {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Fault) error(gvk schema.GroupVersionKind, name string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+
+	resource, _ := meta.UnsafeGuessKindToResource(gvk)
+	groupResource := resource.GroupResource()
+
+	switch f.Error {
+	case ErrorConflict:
+		return apierrors.NewConflict(groupResource, name, fmt.Errorf("injected conflict"))
+	case ErrorNotFound:
+		return apierrors.NewNotFound(groupResource, name)
+	case ErrorTimeout:
+		return apierrors.NewTimeoutError("injected timeout", 1)
+	case ErrorForbidden:
+		return apierrors.NewForbidden(groupResource, name, fmt.Errorf("injected fault"))
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+package conditionstest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+func TestFaultClientErrors(t *testing.T) {
+	injectedErr := fmt.Errorf("injected")
+
+	testCases := []struct {
+		name   string
+		faults []Fault
+		// expectedErrors are checked for three consecutive Gets of the
+		// Cluster, nil means that the Get succeeds.
+		expectedErrors []func(error) bool
+	}{
+		{
+			name:           "case 0: no faults",
+			expectedErrors: []func(error) bool{nil, nil, nil},
+		},
+		{
+			name: "case 1: conflict in all calls",
+			faults: []Fault{
+				{Operations: []Operation{OperationGet}, Error: ErrorConflict},
+			},
+			expectedErrors: []func(error) bool{apierrors.IsConflict, apierrors.IsConflict, apierrors.IsConflict},
+		},
+		{
+			name: "case 2: not found in the second call",
+			faults: []Fault{
+				{Operations: []Operation{OperationGet}, Calls: []int{2}, Error: ErrorNotFound},
+			},
+			expectedErrors: []func(error) bool{nil, apierrors.IsNotFound, nil},
+		},
+		{
+			name: "case 3: timeout and forbidden in different calls",
+			faults: []Fault{
+				{Calls: []int{1}, Error: ErrorTimeout},
+				{Calls: []int{3}, Error: ErrorForbidden},
+			},
+			expectedErrors: []func(error) bool{apierrors.IsTimeout, nil, apierrors.IsForbidden},
+		},
+		{
+			name: "case 4: custom error",
+			faults: []Fault{
+				{Calls: []int{1, 2}, Err: injectedErr},
+			},
+			expectedErrors: []func(error) bool{
+				func(err error) bool { return err == injectedErr },
+				func(err error) bool { return err == injectedErr },
+				nil,
+			},
+		},
+		{
+			name: "case 5: fault for other kind",
+			faults: []Fault{
+				{GroupVersionKinds: []schema.GroupVersionKind{capiexp.GroupVersion.WithKind("MachinePool")}, Error: ErrorTimeout},
+			},
+			expectedErrors: []func(error) bool{nil, nil, nil},
+		},
+		{
+			name: "case 6: fault for other operation",
+			faults: []Fault{
+				{Operations: []Operation{OperationList, OperationStatusUpdate}, Error: ErrorTimeout},
+			},
+			expectedErrors: []func(error) bool{nil, nil, nil},
+		},
+		{
+			name: "case 7: fault for Cluster kind",
+			faults: []Fault{
+				{GroupVersionKinds: []schema.GroupVersionKind{capi.GroupVersion.WithKind("Cluster")}, Calls: []int{2}, Error: ErrorTimeout},
+			},
+			expectedErrors: []func(error) bool{nil, apierrors.IsTimeout, nil},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			ctx := context.Background()
+			cluster := NewCluster("org-test", "test1").Build()
+			client, err := NewFaultClient(FaultClientConfig{
+				Client: NewFakeClient(cluster),
+				Faults: tc.faults,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, expected := range tc.expectedErrors {
+				// act
+				err = client.Get(ctx, ctrl.ObjectKeyFromObject(cluster), &capi.Cluster{})
+
+				// assert
+				if expected == nil && err != nil {
+					t.Errorf("call %d: expected no error, got %#v", i+1, err)
+				} else if expected != nil && !expected(err) {
+					t.Errorf("call %d: expected injected error, got %#v", i+1, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFaultClientStatusUpdate(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	cluster := NewCluster("org-test", "test1").Build()
+	client, err := NewFaultClient(FaultClientConfig{
+		Client: NewFakeClient(cluster),
+		Faults: []Fault{
+			{Operations: []Operation{OperationStatusUpdate}, Calls: []int{1}, Error: ErrorConflict},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err = client.Status().Update(ctx, cluster)
+	if !apierrors.IsConflict(err) {
+		t.Fatalf("expected conflict, got %#v", err)
+	}
+	err = client.Status().Update(ctx, cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if client.Injected(OperationStatusUpdate) != 1 {
+		t.Errorf("expected 1 injected status update fault, got %d", client.Injected(OperationStatusUpdate))
+	}
+	if client.Injected(OperationGet) != 0 {
+		t.Errorf("expected 0 injected get faults, got %d", client.Injected(OperationGet))
+	}
+}
+
+func TestFaultClientLatency(t *testing.T) {
+	// arrange
+	cluster := NewCluster("org-test", "test1").Build()
+	client, err := NewFaultClient(FaultClientConfig{
+		Client: NewFakeClient(cluster),
+		Faults: []Fault{
+			{Operations: []Operation{OperationGet}, Latency: time.Minute},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// act
+	err = client.Get(ctx, ctrl.ObjectKeyFromObject(cluster), &capi.Cluster{})
+
+	// assert
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected %#v, got %#v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestFaultClientStaleRead(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	machinePool := NewMachinePool("org-test", "np1").ForCluster("test1").WithReplicas(3, 1).Build()
+	fakeClient := NewFakeClient(machinePool)
+	client, err := NewFaultClient(FaultClientConfig{
+		Client: fakeClient,
+		Faults: []Fault{
+			{Operations: []Operation{OperationGet}, Calls: []int{2}, StaleRead: true},
+			{Operations: []Operation{OperationList}, Calls: []int{2}, StaleRead: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the first version, which is returned by stale reads.
+	err = client.Get(ctx, ctrl.ObjectKeyFromObject(machinePool), &capiexp.MachinePool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.List(ctx, &capiexp.MachinePoolList{}, ctrl.InNamespace("org-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated := machinePool.DeepCopy()
+	updated.Status.ReadyReplicas = 3
+	err = fakeClient.Status().Update(ctx, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	stale := &capiexp.MachinePool{}
+	err = client.Get(ctx, ctrl.ObjectKeyFromObject(machinePool), stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleList := &capiexp.MachinePoolList{}
+	err = client.List(ctx, staleList, ctrl.InNamespace("org-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := &capiexp.MachinePool{}
+	err = client.Get(ctx, ctrl.ObjectKeyFromObject(machinePool), current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if stale.Status.ReadyReplicas != 1 {
+		t.Errorf("expected stale MachinePool with 1 ready replica, got %d", stale.Status.ReadyReplicas)
+	}
+	if len(staleList.Items) != 1 || staleList.Items[0].Status.ReadyReplicas != 1 {
+		t.Errorf("expected stale MachinePool list with 1 ready replica, got %#v", staleList.Items)
+	}
+	if current.Status.ReadyReplicas != 3 {
+		t.Errorf("expected current MachinePool with 3 ready replicas, got %d", current.Status.ReadyReplicas)
+	}
+}
+
+func TestNewFaultClient(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       FaultClientConfig
+		errorMatcher func(error) bool
+	}{
+		{
+			name:   "case 0: valid config",
+			config: FaultClientConfig{Client: NewFakeClient(), Faults: []Fault{{Error: ErrorTimeout}}},
+		},
+		{
+			name:         "case 1: missing client",
+			config:       FaultClientConfig{},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+		{
+			name:         "case 2: fault without effect",
+			config:       FaultClientConfig{Client: NewFakeClient(), Faults: []Fault{{Operations: []Operation{OperationGet}}}},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+		{
+			name:         "case 3: unknown error type",
+			config:       FaultClientConfig{Client: NewFakeClient(), Faults: []Fault{{Error: "Gone"}}},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+		{
+			name:         "case 4: both error type and error",
+			config:       FaultClientConfig{Client: NewFakeClient(), Faults: []Fault{{Error: ErrorTimeout, Err: fmt.Errorf("injected")}}},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+		{
+			name:         "case 5: stale update",
+			config:       FaultClientConfig{Client: NewFakeClient(), Faults: []Fault{{Operations: []Operation{OperationUpdate}, StaleRead: true}}},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+		{
+			name:         "case 6: zero call number",
+			config:       FaultClientConfig{Client: NewFakeClient(), Faults: []Fault{{Calls: []int{0}, Error: ErrorTimeout}}},
+			errorMatcher: errors.IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// act
+			_, err := NewFaultClient(tc.config)
+
+			// assert
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package conditionstest
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+// LoadObject loads a Cluster, Machine, MachinePool or MockProviderCluster
+// object from the specified YAML manifest.
+func LoadObject(manifestPath string) (ctrl.Object, error) {
+	object, err := internal.LoadCR(manifestPath)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return object, nil
+}
+
+// CreateObjects loads objects from the specified YAML manifests and creates
+// them with the specified client.
+func CreateObjects(ctx context.Context, client ctrl.Client, manifestPaths ...string) error {
+	for _, manifestPath := range manifestPaths {
+		object, err := LoadObject(manifestPath)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = client.Create(ctx, object)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package conditionstest
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/types"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+// AreEqualIgnoringLastTransitionTime checks if two conditions have all fields
+// equal, except LastTransitionTime. Two nil conditions are equal.
+func AreEqualIgnoringLastTransitionTime(c1, c2 *capi.Condition) bool {
+	if c1 == nil || c2 == nil {
+		return c1 == nil && c2 == nil
+	}
+
+	return internal.AreEqualWithIgnoringLastTransitionTime(c1, c2)
+}
+
+// HaveCondition returns a gomega matcher which succeeds when the actual
+// object has a condition with the specified type, status, reason and
+// severity. Empty reason or severity match any reason or severity.
+func HaveCondition(conditionType capi.ConditionType, status corev1.ConditionStatus, reason string, severity capi.ConditionSeverity) types.GomegaMatcher {
+	return &conditionMatcher{
+		expected: capi.Condition{
+			Type:     conditionType,
+			Status:   status,
+			Reason:   reason,
+			Severity: severity,
+		},
+	}
+}
+
+// HaveConditionTrue returns a gomega matcher which succeeds when the actual
+// object has a condition with the specified type and status True.
+func HaveConditionTrue(conditionType capi.ConditionType) types.GomegaMatcher {
+	return HaveCondition(conditionType, corev1.ConditionTrue, "", "")
+}
+
+type conditionMatcher struct {
+	expected capi.Condition
+	actual   *capi.Condition
+}
+
+func (m *conditionMatcher) Match(actual interface{}) (bool, error) {
+	getter, ok := actual.(capiconditions.Getter)
+	if !ok {
+		return false, fmt.Errorf("HaveCondition matcher expects a conditions getter, got %T", actual)
+	}
+
+	m.actual = capiconditions.Get(getter, m.expected.Type)
+	if m.actual == nil {
+		return false, nil
+	}
+
+	return m.actual.Status == m.expected.Status &&
+		(m.expected.Reason == "" || m.actual.Reason == m.expected.Reason) &&
+		(m.expected.Severity == "" || m.actual.Severity == m.expected.Severity), nil
+}
+
+func (m *conditionMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected\n\t%s\nto match\n\t%s", internal.SprintComparedCondition(m.actual), m.sprintExpected())
+}
+
+func (m *conditionMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected\n\t%s\nnot to match\n\t%s", internal.SprintComparedCondition(m.actual), m.sprintExpected())
+}
+
+func (m *conditionMatcher) sprintExpected() string {
+	reason := m.expected.Reason
+	if reason == "" {
+		reason = "*"
+	}
+	severity := string(m.expected.Severity)
+	if severity == "" {
+		severity = "*"
+	}
+
+	return fmt.Sprintf("%s(Status=%q, Reason=%q, Severity=%q)", m.expected.Type, m.expected.Status, reason, severity)
+}
@@ -0,0 +1,20 @@
+package conditionstest
+
+import (
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+// MockProviderCluster is a provider-specific object with conditions, that can
+// be used as infrastructure or control plane object in tests.
+type MockProviderCluster = internal.MockProviderCluster
+
+// MockProviderClusterList is a list of MockProviderCluster objects.
+type MockProviderClusterList = internal.MockProviderClusterList
+
+var (
+	// MockGroupVersion is the group version of mock provider types.
+	MockGroupVersion = internal.SchemeGroupVersion
+
+	// AddMockToScheme adds mock provider types to a scheme.
+	AddMockToScheme = internal.AddMockToScheme
+)
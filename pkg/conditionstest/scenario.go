@@ -0,0 +1,352 @@
+package conditionstest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+)
+
+const (
+	// ScenarioFile describes the scenario.
+	ScenarioFile = "scenario.yaml"
+	// ObjectsFile contains input objects as a multi-document YAML.
+	ObjectsFile = "objects.yaml"
+	// ExpectedFile is the golden file with expected conditions of the tested
+	// object.
+	ExpectedFile = "expected.yaml"
+)
+
+// update is registered in every test binary that imports this package, so
+// that golden files of any package are updated without its own flag, e.g. with
+// go test ./pkg/conditions/scaling/ -update.
+var update = flag.Bool("update", false, "update golden files of test scenarios")
+
+// HandlerFunc creates the handler under test with the specified config,
+// which contains the fake client, the logger, the fake clock of the scenario
+// and the post-check that validates conditions, see NewPostCheck.
+type HandlerFunc func(config handler.Config) (handler.Interface, error)
+
+// Scenario describes a golden test scenario. It is read from ScenarioFile in
+// the scenario directory.
+type Scenario struct {
+	// Description explains what is tested.
+	Description string `json:"description,omitempty"`
+	// Handler is the name of the handler under test, as registered in
+	// ScenarioConfig.Handlers.
+	Handler string `json:"handler"`
+	// Now is the fake clock time used while the handler runs. Defaults to the
+	// current time.
+	Now *metav1.Time `json:"now,omitempty"`
+	// Object references the tested object from ObjectsFile. Defaults to the
+	// first object.
+	Object *ObjectReference `json:"object,omitempty"`
+}
+
+// ObjectReference references an input object.
+type ObjectReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// ExpectedCondition is a condition stored in golden files. LastTransitionTime
+// is omitted, since it is set by the real clock.
+type ExpectedCondition struct {
+	Type     capi.ConditionType     `json:"type"`
+	Status   string                 `json:"status"`
+	Severity capi.ConditionSeverity `json:"severity,omitempty"`
+	Reason   string                 `json:"reason,omitempty"`
+	Message  string                 `json:"message,omitempty"`
+}
+
+// ScenarioConfig configures RunScenarios.
+type ScenarioConfig struct {
+	// Dir contains one subdirectory per scenario.
+	Dir string
+	// Handlers maps handler names used in scenario files to functions that
+	// create the handlers.
+	Handlers map[string]HandlerFunc
+	// Update writes actual conditions to golden files instead of comparing
+	// them. Golden files are also updated when tests run with the -update
+	// flag.
+	Update bool
+	// ExtraConditionTypes are condition types set by the handlers that are
+	// not known to the default validator, e.g. types of custom conditions.
+	// Conditions produced by the handlers are validated with
+	// NewPostCheck.
+	ExtraConditionTypes []capi.ConditionType
+}
+
+// RunScenarios runs every scenario in config.Dir as a subtest. For each
+// scenario it creates a fake client with the input objects, runs
+// EnsureCreated of the handler under test on the tested object with a fake
+// clock, and compares the resulting conditions with the golden file.
+func RunScenarios(t *testing.T, config ScenarioConfig) {
+	entries, err := os.ReadDir(config.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(config.Dir, entry.Name())
+		t.Run(entry.Name(), func(t *testing.T) {
+			runScenario(t, dir, config)
+		})
+	}
+}
+
+func runScenario(t *testing.T, dir string, config ScenarioConfig) {
+	// arrange
+	scenario, err := ReadScenario(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scenario.Description != "" {
+		t.Log(scenario.Description)
+	}
+
+	newHandler, ok := config.Handlers[scenario.Handler]
+	if !ok {
+		t.Fatalf("handler %q is not registered", scenario.Handler)
+	}
+
+	objects, err := LoadObjects(filepath.Join(dir, ObjectsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tested, err := findObject(objects, scenario.Object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	client := NewFakeClient(objects...)
+	object := tested.DeepCopyObject().(ctrl.Object)
+	err = client.Get(ctx, ctrl.ObjectKeyFromObject(tested), object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handlerConfig := handler.Config{
+		CtrlClient: client,
+		Logger:     newLogger(t),
+		PostCheck:  NewPostCheck(t, config.ExtraConditionTypes...),
+	}
+	if scenario.Now != nil {
+		now := scenario.Now.Time
+		handlerConfig.Now = func() time.Time { return now }
+	}
+	h, err := newHandler(handlerConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err = h.EnsureCreated(ctx, object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	actual, err := marshalConditions(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, filepath.Join(dir, ExpectedFile), actual, config.Update || *update)
+}
+
+// AssertGolden compares actual data with the specified golden file, or writes
+// actual data to the golden file when tests run with the -update flag.
+func AssertGolden(t *testing.T, goldenPath string, actual []byte) {
+	t.Helper()
+
+	assertGolden(t, goldenPath, actual, *update)
+}
+
+func assertGolden(t *testing.T, goldenPath string, actual []byte, update bool) {
+	t.Helper()
+
+	if update {
+		err := os.WriteFile(goldenPath, actual, 0644) // nolint:gosec
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("%s, run with -update to create it", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("%s does not match, run with -update to update it\n%s", goldenPath, diffLines(string(expected), string(actual)))
+	}
+}
+
+// ReadScenario reads ScenarioFile from the specified scenario directory.
+func ReadScenario(dir string) (Scenario, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ScenarioFile))
+	if err != nil {
+		return Scenario{}, microerror.Mask(err)
+	}
+
+	var scenario Scenario
+	err = yaml.UnmarshalStrict(data, &scenario)
+	if err != nil {
+		return Scenario{}, microerror.Maskf(errors.InvalidConfigError, "invalid scenario %s: %s", dir, err)
+	}
+	if scenario.Handler == "" {
+		return Scenario{}, microerror.Maskf(errors.InvalidConfigError, "scenario %s must specify handler", dir)
+	}
+
+	return scenario, nil
+}
+
+// LoadObjects loads all objects from a multi-document YAML manifest. Objects
+// of any type registered in NewScheme are supported.
+func LoadObjects(manifestPath string) ([]ctrl.Object, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer file.Close()
+
+	decoder := serializer.NewCodecFactory(NewScheme()).UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(file))
+
+	var objects []ctrl.Object
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+
+		decoded, _, err := decoder.Decode(document, nil, nil)
+		if err != nil {
+			return nil, microerror.Maskf(errors.InvalidConfigError, "invalid object in %s: %s", manifestPath, err)
+		}
+		object, ok := decoded.(ctrl.Object)
+		if !ok {
+			return nil, microerror.Maskf(errors.WrongTypeError, "expected ctrl.Object, got %T", decoded)
+		}
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+func findObject(objects []ctrl.Object, reference *ObjectReference) (ctrl.Object, error) {
+	if len(objects) == 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "scenario does not have any objects")
+	}
+	if reference == nil {
+		return objects[0], nil
+	}
+
+	for _, object := range objects {
+		if object.GetObjectKind().GroupVersionKind().Kind == reference.Kind &&
+			object.GetNamespace() == reference.Namespace &&
+			object.GetName() == reference.Name {
+			return object, nil
+		}
+	}
+
+	return nil, microerror.Maskf(errors.InvalidConfigError, "object %s %s/%s not found", reference.Kind, reference.Namespace, reference.Name)
+}
+
+func marshalConditions(object ctrl.Object) ([]byte, error) {
+	getter, ok := object.(capiconditions.Getter)
+	if !ok {
+		return nil, microerror.Maskf(errors.WrongTypeError, "expected object with conditions, got %T", object)
+	}
+
+	expected := []ExpectedCondition{}
+	for _, condition := range getter.GetConditions() {
+		expected = append(expected, ExpectedCondition{
+			Type:     condition.Type,
+			Status:   string(condition.Status),
+			Severity: condition.Severity,
+			Reason:   condition.Reason,
+			Message:  condition.Message,
+		})
+	}
+	sort.SliceStable(expected, func(i, j int) bool {
+		return expected[i].Type < expected[j].Type
+	})
+
+	data, err := yaml.Marshal(expected)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return data, nil
+}
+
+func newLogger(t *testing.T) micrologger.Logger {
+	logger, err := micrologger.New(micrologger.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return logger
+}
+
+// diffLines returns expected and actual lines that differ, prefixed with -
+// and + respectively.
+func diffLines(expected, actual string) string {
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+
+	var diff strings.Builder
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		var e, a string
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			a = actualLines[i]
+		}
+		if e == a {
+			continue
+		}
+		if i < len(expectedLines) {
+			diff.WriteString("- " + e + "\n")
+		}
+		if i < len(actualLines) {
+			diff.WriteString("+ " + a + "\n")
+		}
+	}
+
+	return diff.String()
+}
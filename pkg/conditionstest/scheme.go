@@ -0,0 +1,41 @@
+package conditionstest
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+// NewScheme returns a scheme with core, Cluster API (including experimental
+// MachinePool) and mock provider types.
+func NewScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	addToSchemeFuncs := []func(s *runtime.Scheme) error{
+		corev1.AddToScheme,
+		capi.AddToScheme,
+		capiexp.AddToScheme,
+		internal.AddMockToScheme,
+	}
+	for _, f := range addToSchemeFuncs {
+		err := f(scheme)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return scheme
+}
+
+// NewFakeClient returns a fake client with NewScheme scheme, that contains
+// the specified objects.
+func NewFakeClient(objects ...ctrl.Object) ctrl.Client {
+	return fake.NewClientBuilder().
+		WithScheme(NewScheme()).
+		WithObjects(objects...).
+		Build()
+}
@@ -0,0 +1,38 @@
+package conditionstest
+
+import (
+	"testing"
+
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/catalog"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/validation"
+)
+
+// NewPostCheck returns a post-check that validates conditions after handlers
+// ensure them, so that a handler configured with it and producing an invalid
+// condition or a reason that is not registered in the default catalog fails
+// with InvalidConditionError. Extra condition types, e.g. types of custom
+// conditions, are known for all kinds and registered as custom condition
+// types.
+func NewPostCheck(t *testing.T, extraConditionTypes ...capi.ConditionType) handler.PostCheckFunc {
+	t.Helper()
+
+	c := catalog.NewDefaultCatalog()
+	for _, conditionType := range extraConditionTypes {
+		err := c.Register(catalog.CustomConditionType(conditionType))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, err := validation.NewValidator(validation.Config{
+		ExtraConditionTypes: extraConditionTypes,
+		Catalog:             c,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v.PostCheck
+}
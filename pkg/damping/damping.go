@@ -0,0 +1,148 @@
+package damping
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+// PendingTransitionsAnnotation is the annotation where pending condition
+// transitions are stored, so they survive operator restarts. The value is a
+// JSON object with condition types as keys.
+const PendingTransitionsAnnotation = "conditions.giantswarm.io/pending-transitions"
+
+// Config defines flap damping (hysteresis) of a condition. When enabled, a
+// transition of the condition to False is published only after it has been
+// observed for the specified duration or for the specified number of
+// reconciliations, whichever comes first. Zero value disables damping.
+type Config struct {
+	// FalseAfter is the duration for which the condition must be False
+	// before it is published.
+	FalseAfter time.Duration
+	// FalseAfterReconciles is the number of consecutive reconciliations in
+	// which the condition must be False before it is published.
+	FalseAfterReconciles int
+	// DampRecovery enables damping of transitions to True with the same
+	// settings. By default recovery to True is published immediately.
+	DampRecovery bool
+}
+
+// Enabled checks if damping is enabled.
+func (c Config) Enabled() bool {
+	return c.FalseAfter > 0 || c.FalseAfterReconciles > 0
+}
+
+// Validate checks if damping config is valid.
+func (c Config) Validate() error {
+	if c.FalseAfter < 0 {
+		return microerror.Maskf(errors.InvalidConfigError, "%T.FalseAfter must not be negative", c)
+	}
+	if c.FalseAfterReconciles < 0 {
+		return microerror.Maskf(errors.InvalidConfigError, "%T.FalseAfterReconciles must not be negative", c)
+	}
+
+	return nil
+}
+
+type pendingTransition struct {
+	Status     corev1.ConditionStatus `json:"status"`
+	Since      metav1.Time            `json:"since"`
+	Reconciles int                    `json:"reconciles"`
+}
+
+// Apply damps the transition of the condition of specified type from the
+// specified previous value to its current value on the object. When the
+// transition has to be damped, the previous value is restored and the pending
+// transition is recorded in PendingTransitionsAnnotation. It returns true if
+// the annotation has been changed, so that the caller can persist it.
+func Apply(object conditions.Object, conditionType capi.ConditionType, previous *capi.Condition, config Config, now time.Time) bool {
+	if !config.Enabled() {
+		return false
+	}
+
+	pending := getPendingTransitions(object)
+	current := capiconditions.Get(object, conditionType)
+
+	damped := previous != nil && current != nil &&
+		previous.Status != current.Status &&
+		(current.Status == corev1.ConditionFalse || (current.Status == corev1.ConditionTrue && config.DampRecovery))
+
+	if !damped {
+		// No status change, initial value or undamped transition, so there
+		// is nothing pending anymore.
+		return deletePendingTransition(object, pending, conditionType)
+	}
+
+	transition, ok := pending[conditionType]
+	if !ok || transition.Status != current.Status {
+		transition = pendingTransition{
+			Status: current.Status,
+			Since:  metav1.NewTime(now.UTC().Truncate(time.Second)),
+		}
+	}
+	transition.Reconciles++
+
+	durationReached := config.FalseAfter > 0 && now.Sub(transition.Since.Time) >= config.FalseAfter
+	reconcilesReached := config.FalseAfterReconciles > 0 && transition.Reconciles >= config.FalseAfterReconciles
+	if durationReached || reconcilesReached {
+		// Transition has persisted long enough, it is published now.
+		return deletePendingTransition(object, pending, conditionType)
+	}
+
+	capiconditions.Set(object, previous)
+	pending[conditionType] = transition
+	setPendingTransitions(object, pending)
+
+	return true
+}
+
+func getPendingTransitions(object conditions.Object) map[capi.ConditionType]pendingTransition {
+	pending := map[capi.ConditionType]pendingTransition{}
+
+	value, ok := object.GetAnnotations()[PendingTransitionsAnnotation]
+	if !ok {
+		return pending
+	}
+
+	// Invalid annotation value is ignored and overwritten, so that damping
+	// starts over instead of blocking condition updates.
+	_ = json.Unmarshal([]byte(value), &pending)
+
+	return pending
+}
+
+func setPendingTransitions(object conditions.Object, pending map[capi.ConditionType]pendingTransition) {
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	if len(pending) == 0 {
+		delete(annotations, PendingTransitionsAnnotation)
+	} else {
+		// Marshalling a map of plain structs cannot fail.
+		bytes, _ := json.Marshal(pending)
+		annotations[PendingTransitionsAnnotation] = string(bytes)
+	}
+
+	object.SetAnnotations(annotations)
+}
+
+func deletePendingTransition(object conditions.Object, pending map[capi.ConditionType]pendingTransition, conditionType capi.ConditionType) bool {
+	if _, ok := pending[conditionType]; !ok {
+		return false
+	}
+
+	delete(pending, conditionType)
+	setPendingTransitions(object, pending)
+
+	return true
+}
@@ -0,0 +1,108 @@
+package damping
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestApply(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		// config is the damping config.
+		config Config
+		// previous is the status published before the first reconciliation.
+		previous corev1.ConditionStatus
+		// observed are the statuses observed in consecutive reconciliations,
+		// one per minute.
+		observed []corev1.ConditionStatus
+		// expected are the statuses published in consecutive
+		// reconciliations.
+		expected []corev1.ConditionStatus
+	}{
+		{
+			name:     "case 0: damping disabled, False is published immediately",
+			previous: corev1.ConditionTrue,
+			observed: []corev1.ConditionStatus{corev1.ConditionFalse},
+			expected: []corev1.ConditionStatus{corev1.ConditionFalse},
+		},
+		{
+			name:     "case 1: False is published after 3 reconciliations",
+			config:   Config{FalseAfterReconciles: 3},
+			previous: corev1.ConditionTrue,
+			observed: []corev1.ConditionStatus{corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionFalse},
+			expected: []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionFalse},
+		},
+		{
+			name:     "case 2: flapping False is never published",
+			config:   Config{FalseAfterReconciles: 2},
+			previous: corev1.ConditionTrue,
+			observed: []corev1.ConditionStatus{corev1.ConditionFalse, corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionTrue},
+			expected: []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionTrue},
+		},
+		{
+			name:     "case 3: False is published after 2 minutes",
+			config:   Config{FalseAfter: 2 * time.Minute},
+			previous: corev1.ConditionTrue,
+			observed: []corev1.ConditionStatus{corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionFalse},
+			expected: []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionFalse},
+		},
+		{
+			name:     "case 4: recovery to True is published immediately",
+			config:   Config{FalseAfterReconciles: 3},
+			previous: corev1.ConditionFalse,
+			observed: []corev1.ConditionStatus{corev1.ConditionTrue},
+			expected: []corev1.ConditionStatus{corev1.ConditionTrue},
+		},
+		{
+			name:     "case 5: damped recovery to True is published after 2 reconciliations",
+			config:   Config{FalseAfterReconciles: 2, DampRecovery: true},
+			previous: corev1.ConditionFalse,
+			observed: []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionTrue},
+			expected: []corev1.ConditionStatus{corev1.ConditionFalse, corev1.ConditionTrue},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			machinePool := &capiexp.MachinePool{}
+			setStatus(machinePool, tc.previous)
+
+			for i, observed := range tc.observed {
+				previous := capiconditions.Get(machinePool, capiexp.ReplicasReadyCondition)
+				setStatus(machinePool, observed)
+
+				// act
+				Apply(machinePool, capiexp.ReplicasReadyCondition, previous, tc.config, start.Add(time.Duration(i)*time.Minute))
+
+				// assert
+				published := capiconditions.Get(machinePool, capiexp.ReplicasReadyCondition)
+				if published.Status != tc.expected[i] {
+					t.Fatalf("reconciliation %d: expected published status %s, got %s", i, tc.expected[i], published.Status)
+				}
+			}
+
+			if published := capiconditions.Get(machinePool, capiexp.ReplicasReadyCondition); published.Status == tc.observed[len(tc.observed)-1] {
+				if _, ok := machinePool.GetAnnotations()[PendingTransitionsAnnotation]; ok {
+					t.Fatalf("expected %s annotation to be removed after the transition is published", PendingTransitionsAnnotation)
+				}
+			}
+		})
+	}
+}
+
+func setStatus(machinePool *capiexp.MachinePool, status corev1.ConditionStatus) {
+	if status == corev1.ConditionTrue {
+		capiconditions.MarkTrue(machinePool, capiexp.ReplicasReadyCondition)
+	} else {
+		capiconditions.MarkFalse(machinePool, capiexp.ReplicasReadyCondition, capiexp.WaitingForReplicasReadyReason, capi.ConditionSeverityWarning, "")
+	}
+}
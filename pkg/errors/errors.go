@@ -1,9 +1,12 @@
 package errors
 
 import (
+	goerrors "errors"
 	"strings"
 
 	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 )
 
 var InvalidConfigError = &microerror.Error{
@@ -24,6 +27,11 @@ func IsUnknownKindError(err error) bool {
 	return microerror.Cause(err) == UnknownKindError
 }
 
+// IsFailedToRetrieveExternalObject asserts any error returned by
+// capiexternal.Get.
+//
+// Deprecated: use IsExternalObjectNotFound, which does not match transient
+// errors.
 func IsFailedToRetrieveExternalObject(err error) bool {
 	if err == nil {
 		return false
@@ -38,6 +46,20 @@ func IsFailedToRetrieveExternalObject(err error) bool {
 	}
 }
 
+// IsExternalObjectNotFound asserts that an external object, like a
+// provider-specific infrastructure object, does not exist or that its kind is
+// not known. Unlike IsFailedToRetrieveExternalObject, it does not match other
+// errors returned while retrieving the object, like timeouts.
+func IsExternalObjectNotFound(err error) bool {
+	if apierrors.IsNotFound(err) {
+		return true
+	}
+
+	var noKindMatch *meta.NoKindMatchError
+	var noResourceMatch *meta.NoResourceMatchError
+	return goerrors.As(err, &noKindMatch) || goerrors.As(err, &noResourceMatch)
+}
+
 var WrongTypeError = &microerror.Error{
 	Kind: "WrongTypeError",
 }
@@ -46,3 +68,30 @@ var WrongTypeError = &microerror.Error{
 func IsWrongTypeError(err error) bool {
 	return microerror.Cause(err) == WrongTypeError
 }
+
+var ExpressionEvaluationFailedError = &microerror.Error{
+	Kind: "ExpressionEvaluationFailedError",
+}
+
+// IsExpressionEvaluationFailed asserts ExpressionEvaluationFailedError.
+func IsExpressionEvaluationFailed(err error) bool {
+	return microerror.Cause(err) == ExpressionEvaluationFailedError
+}
+
+var TransitionNotFoundError = &microerror.Error{
+	Kind: "TransitionNotFoundError",
+}
+
+// IsTransitionNotFound asserts TransitionNotFoundError.
+func IsTransitionNotFound(err error) bool {
+	return microerror.Cause(err) == TransitionNotFoundError
+}
+
+var InvalidConditionError = &microerror.Error{
+	Kind: "InvalidConditionError",
+}
+
+// IsInvalidCondition asserts InvalidConditionError.
+func IsInvalidCondition(err error) bool {
+	return microerror.Cause(err) == InvalidConditionError
+}
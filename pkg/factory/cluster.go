@@ -9,8 +9,11 @@ import (
 	"github.com/giantswarm/conditions-handler/pkg/conditions/composite"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/controlplaneready"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/creating"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/frozen"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/infrastructureready"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsupgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/summary"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/upgrading"
 	"github.com/giantswarm/conditions-handler/pkg/handler"
@@ -18,32 +21,70 @@ import (
 
 // NewClusterConditionsHandler creates a composite handler for reconciling
 // MachinePool conditions, which consists of condition handlers for
-// InfrastructureReady, ControlPlaneReady, NodePoolsReady, Ready, Creating and
-// Upgrading conditions.
+// Paused, Frozen, InfrastructureReady, ControlPlaneReady, NodePoolsReady,
+// NodePoolsUpgrading, Ready, Creating and Upgrading conditions.
 func NewClusterConditionsHandler(config handler.Config) (*composite.Handler, error) {
 	var err error
 
-	var infrastructureReadyHandler *infrastructureready.Handler
+	var pausedHandler *paused.Handler
 	{
-		c := infrastructureready.HandlerConfig{
+		c := paused.HandlerConfig{
 			CtrlClient:   config.CtrlClient,
 			Logger:       config.Logger,
-			Name:         "clusterInfrastructureReadyHandler",
+			Name:         "clusterPausedHandler",
 			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 		}
-		infrastructureReadyHandler, err = infrastructureready.NewHandler(c)
+		pausedHandler, err = paused.NewHandler(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 	}
 
-	var controlPlaneReadyHandler *controlplaneready.Handler
+	var frozenHandler *frozen.Handler
 	{
-		c := controlplaneready.HandlerConfig{
+		c := frozen.HandlerConfig{
 			CtrlClient:   config.CtrlClient,
 			Logger:       config.Logger,
-			Name:         "clusterControlPlaneReadyHandler",
+			Name:         "clusterFrozenHandler",
 			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
+		}
+		frozenHandler, err = frozen.NewHandler(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var infrastructureReadyHandler *infrastructureready.Handler
+	{
+		c := infrastructureready.HandlerConfig{
+			CtrlClient:         config.CtrlClient,
+			Logger:             config.Logger,
+			Name:               "clusterInfrastructureReadyHandler",
+			UpdateStatus:       false,
+			PostCheck:          config.PostCheck,
+			Now:                config.Now,
+			InfrastructureGVKs: config.InfrastructureGVKs,
+		}
+		infrastructureReadyHandler, err = infrastructureready.NewHandler(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var controlPlaneReadyHandler *controlplaneready.Handler
+	{
+		c := controlplaneready.HandlerConfig{
+			CtrlClient:       config.CtrlClient,
+			Logger:           config.Logger,
+			Name:             "clusterControlPlaneReadyHandler",
+			UpdateStatus:     false,
+			PostCheck:        config.PostCheck,
+			Now:              config.Now,
+			ControlPlaneGVKs: config.ControlPlaneGVKs,
 		}
 		controlPlaneReadyHandler, err = controlplaneready.NewHandler(c)
 		if err != nil {
@@ -58,6 +99,8 @@ func NewClusterConditionsHandler(config handler.Config) (*composite.Handler, err
 			Logger:       config.Logger,
 			Name:         "clusterNodePoolsReadyHandler",
 			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 		}
 		nodePoolsReadyHandler, err = nodepoolsready.NewHandler(c)
 		if err != nil {
@@ -65,12 +108,30 @@ func NewClusterConditionsHandler(config handler.Config) (*composite.Handler, err
 		}
 	}
 
+	var nodePoolsUpgradingHandler *nodepoolsupgrading.Handler
+	{
+		c := nodepoolsupgrading.HandlerConfig{
+			CtrlClient:   config.CtrlClient,
+			Logger:       config.Logger,
+			Name:         "clusterNodePoolsUpgradingHandler",
+			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
+		}
+		nodePoolsUpgradingHandler, err = nodepoolsupgrading.NewHandler(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var readyHandler *summary.Handler
 	{
 		c := summary.HandlerConfig{
 			CtrlClient:   config.CtrlClient,
 			Logger:       config.Logger,
 			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 			ConditionsToSummarize: []capi.ConditionType{
 				capi.InfrastructureReadyCondition,
 				capi.ControlPlaneReadyCondition,
@@ -95,6 +156,8 @@ func NewClusterConditionsHandler(config handler.Config) (*composite.Handler, err
 			Logger:       config.Logger,
 			Name:         "clusterCreatingConditionHandler",
 			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 		}
 
 		creatingHandler, err = creating.NewHandler(c)
@@ -110,6 +173,8 @@ func NewClusterConditionsHandler(config handler.Config) (*composite.Handler, err
 			Logger:       config.Logger,
 			Name:         "clusterUpgradingConditionHandler",
 			UpdateStatus: true,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 		}
 
 		upgradingHandler, err = upgrading.NewHandler(c)
@@ -125,9 +190,12 @@ func NewClusterConditionsHandler(config handler.Config) (*composite.Handler, err
 			Logger:     config.Logger,
 			Name:       config.Name,
 			Handlers: []handler.Interface{
+				pausedHandler,
+				frozenHandler,
 				infrastructureReadyHandler,
 				controlPlaneReadyHandler,
 				nodePoolsReadyHandler,
+				nodePoolsUpgradingHandler,
 				readyHandler,
 				creatingHandler,
 				upgradingHandler,
@@ -7,8 +7,11 @@ import (
 
 	"github.com/giantswarm/conditions-handler/pkg/conditions/composite"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/creating"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/frozen"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/infrastructureready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/replicasready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/summary"
 	"github.com/giantswarm/conditions-handler/pkg/conditions/upgrading"
 	"github.com/giantswarm/conditions-handler/pkg/handler"
@@ -16,19 +19,71 @@ import (
 
 // NewMachinePoolConditionsHandler creates a composite handler for reconciling
 // MachinePool conditions, which consists of condition handlers for
-// InfrastructureReady, Ready, Creating and Upgrading conditions.
+// Paused, Frozen, InfrastructureReady, Scaling, ReplicasReady, Ready,
+// Creating and Upgrading conditions.
 func NewMachinePoolConditionsHandler(config handler.Config) (*composite.Handler, error) {
 	var err error
 
+	var pausedHandler *paused.Handler
+	{
+		c := paused.HandlerConfig{
+			CtrlClient:   config.CtrlClient,
+			Logger:       config.Logger,
+			Name:         "machinePoolPausedHandler",
+			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
+		}
+		pausedHandler, err = paused.NewHandler(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var frozenHandler *frozen.Handler
+	{
+		c := frozen.HandlerConfig{
+			CtrlClient:   config.CtrlClient,
+			Logger:       config.Logger,
+			Name:         "machinePoolFrozenHandler",
+			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
+		}
+		frozenHandler, err = frozen.NewHandler(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var infrastructureReadyHandler *infrastructureready.Handler
 	{
 		c := infrastructureready.HandlerConfig{
+			CtrlClient:         config.CtrlClient,
+			Logger:             config.Logger,
+			Name:               "machinePoolInfrastructureReadyHandler",
+			UpdateStatus:       false,
+			PostCheck:          config.PostCheck,
+			Now:                config.Now,
+			InfrastructureGVKs: config.InfrastructureGVKs,
+		}
+		infrastructureReadyHandler, err = infrastructureready.NewHandler(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var scalingHandler *scaling.Handler
+	{
+		c := scaling.HandlerConfig{
 			CtrlClient:   config.CtrlClient,
 			Logger:       config.Logger,
-			Name:         "machinePoolInfrastructureReadyHandler",
+			Name:         "machinePoolScalingHandler",
 			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 		}
-		infrastructureReadyHandler, err = infrastructureready.NewHandler(c)
+		scalingHandler, err = scaling.NewHandler(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -41,6 +96,8 @@ func NewMachinePoolConditionsHandler(config handler.Config) (*composite.Handler,
 			Logger:       config.Logger,
 			Name:         "machinePoolReplicasReadyHandler",
 			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 		}
 		replicasReadyHandler, err = replicasready.NewHandler(c)
 		if err != nil {
@@ -54,6 +111,8 @@ func NewMachinePoolConditionsHandler(config handler.Config) (*composite.Handler,
 			CtrlClient:           config.CtrlClient,
 			Logger:               config.Logger,
 			UpdateStatus:         false,
+			PostCheck:            config.PostCheck,
+			Now:                  config.Now,
 			SummaryConditionType: capi.ReadyCondition,
 			ConditionsToSummarize: []capi.ConditionType{
 				capi.InfrastructureReadyCondition,
@@ -75,6 +134,8 @@ func NewMachinePoolConditionsHandler(config handler.Config) (*composite.Handler,
 			Logger:       config.Logger,
 			Name:         "machinePoolCreatingConditionHandler",
 			UpdateStatus: false,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 		}
 
 		creatingHandler, err = creating.NewHandler(c)
@@ -90,6 +151,8 @@ func NewMachinePoolConditionsHandler(config handler.Config) (*composite.Handler,
 			Logger:       config.Logger,
 			Name:         "machinePoolUpgradingConditionHandler",
 			UpdateStatus: true,
+			PostCheck:    config.PostCheck,
+			Now:          config.Now,
 		}
 
 		upgradingHandler, err = upgrading.NewHandler(c)
@@ -105,7 +168,10 @@ func NewMachinePoolConditionsHandler(config handler.Config) (*composite.Handler,
 			Logger:     config.Logger,
 			Name:       config.Name,
 			Handlers: []handler.Interface{
+				pausedHandler,
+				frozenHandler,
 				infrastructureReadyHandler,
+				scalingHandler,
 				replicasReadyHandler,
 				readyHandler,
 				creatingHandler,
@@ -0,0 +1,24 @@
+package factory
+
+import (
+	"testing"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+)
+
+func TestScenarios(t *testing.T) {
+	conditionstest.RunScenarios(t, conditionstest.ScenarioConfig{
+		Dir: "testdata/scenarios",
+		Handlers: map[string]conditionstest.HandlerFunc{
+			"cluster": func(config handler.Config) (handler.Interface, error) {
+				config.Name = "clusterConditionsHandler"
+				return NewClusterConditionsHandler(config)
+			},
+			"machinePool": func(config handler.Config) (handler.Interface, error) {
+				config.Name = "machinePoolConditionsHandler"
+				return NewMachinePoolConditionsHandler(config)
+			},
+		},
+	})
+}
@@ -0,0 +1,82 @@
+package factory
+
+import (
+	"testing"
+
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+	"github.com/giantswarm/conditions-handler/pkg/watch"
+)
+
+func TestWatchSources(t *testing.T) {
+	infrastructureGVK := schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "AzureCluster"}
+	machinePoolInfrastructureGVK := schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "AzureMachinePool"}
+	controlPlaneGVK := schema.GroupVersionKind{Group: "controlplane.cluster.x-k8s.io", Version: "v1beta1", Kind: "KubeadmControlPlane"}
+
+	testCases := []struct {
+		name         string
+		newHandler   func(config handler.Config) (handler.Interface, error)
+		reconciled   schema.GroupVersionKind
+		config       handler.Config
+		expectedGVKs []schema.GroupVersionKind
+	}{
+		{
+			name: "case 0: Cluster conditions handler watches infrastructure and control plane objects",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return NewClusterConditionsHandler(config)
+			},
+			reconciled: watch.ClusterGVK,
+			config: handler.Config{
+				InfrastructureGVKs: []schema.GroupVersionKind{infrastructureGVK},
+				ControlPlaneGVKs:   []schema.GroupVersionKind{controlPlaneGVK},
+			},
+			expectedGVKs: []schema.GroupVersionKind{infrastructureGVK, controlPlaneGVK},
+		},
+		{
+			name: "case 1: MachinePool conditions handler watches infrastructure objects",
+			newHandler: func(config handler.Config) (handler.Interface, error) {
+				return NewMachinePoolConditionsHandler(config)
+			},
+			reconciled: watch.MachinePoolGVK,
+			config: handler.Config{
+				InfrastructureGVKs: []schema.GroupVersionKind{machinePoolInfrastructureGVK},
+			},
+			expectedGVKs: []schema.GroupVersionKind{machinePoolInfrastructureGVK},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			logger, err := micrologger.New(micrologger.Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.config.CtrlClient = internal.NewFakeClient()
+			tc.config.Logger = logger
+			tc.config.Name = "test"
+			h, err := tc.newHandler(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			sources := watch.Sources(h, tc.reconciled)
+
+			// assert
+			watched := map[schema.GroupVersionKind]bool{}
+			for _, source := range sources {
+				watched[source.GVK] = true
+			}
+			for _, gvk := range tc.expectedGVKs {
+				if !watched[gvk] {
+					t.Errorf("expected %s to be watched, got sources %v", gvk.Kind, sources)
+				}
+			}
+		})
+	}
+}
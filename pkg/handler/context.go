@@ -0,0 +1,20 @@
+package handler
+
+import "context"
+
+type pausedContextKey struct{}
+
+// NewPausedContext returns a new context that tells handlers whether the
+// reconciled object or its Cluster is paused.
+func NewPausedContext(ctx context.Context, paused bool) context.Context {
+	return context.WithValue(ctx, pausedContextKey{}, paused)
+}
+
+// IsPaused checks if the reconciled object or its Cluster is paused, as found
+// by reconciler.Reconciler. Condition handlers of this module check the pause
+// by themselves. Other handlers must check it with IsPaused and leave
+// conditions of paused objects untouched.
+func IsPaused(ctx context.Context) bool {
+	paused, _ := ctx.Value(pausedContextKey{}).(bool)
+	return paused
+}
@@ -2,8 +2,12 @@ package handler
 
 import (
 	"context"
+	"time"
 
+	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -11,8 +15,31 @@ type Config struct {
 	CtrlClient ctrl.Client
 	Logger     micrologger.Logger
 	Name       string
+
+	// PostCheck is run by the handlers after ensuring their conditions. It
+	// is meant for debug and test mode, e.g. with validation.Validator
+	// PostCheck. By default conditions are not checked.
+	PostCheck PostCheckFunc
+	// Now returns the current time used by the handlers, e.g. in condition
+	// messages and recorded condition transitions. It is meant for tests with
+	// a fake clock. Defaults to time.Now.
+	Now func() time.Time
+
+	// InfrastructureGVKs are kinds of provider-specific infrastructure
+	// objects, e.g. AzureCluster for Cluster handlers or AzureMachinePool for
+	// MachinePool handlers. They are watched by InfrastructureReady handlers,
+	// see watch.SetupWatches.
+	InfrastructureGVKs []schema.GroupVersionKind
+	// ControlPlaneGVKs are kinds of control plane objects, e.g.
+	// KubeadmControlPlane. They are watched by ControlPlaneReady handler.
+	ControlPlaneGVKs []schema.GroupVersionKind
 }
 
+// PostCheckFunc checks the condition of the specified type after it has been
+// ensured by a handler. A returned error fails the handler, so the invalid
+// condition is not saved.
+type PostCheckFunc func(object conditions.Object, conditionType capi.ConditionType) error
+
 // Interface defines the building blocks of an operator's reconciliation logic.
 // Note there can be multiple hanlders reconciling the same object in a chain.
 // In that case they are guaranteed to be executed in order one after another.
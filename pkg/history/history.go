@@ -0,0 +1,418 @@
+// Package history records condition transitions of an object in a bounded
+// ring buffer, so that it can be seen how conditions changed over time, e.g.
+// that a condition flapped, even though the object status keeps only the
+// latest value of each condition.
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+const (
+	// Annotation is the annotation where condition transitions are stored
+	// with StorageAnnotation. The value is a base64-encoded gzip-compressed
+	// JSON list of entries, see DecodeAnnotation.
+	Annotation = "conditions.giantswarm.io/history"
+
+	// ConfigMapKey is the key of the companion ConfigMap data where
+	// condition transitions are stored with StorageConfigMap. The value is a
+	// JSON list of entries.
+	ConfigMapKey = "history.json"
+
+	// MaxAnnotationSize is the maximum size of the Annotation value. When
+	// the encoded history is larger, the oldest entries are dropped.
+	MaxAnnotationSize = 32 * 1024
+)
+
+// Storage defines where condition transitions are stored.
+type Storage string
+
+const (
+	// StorageAnnotation stores transitions in the Annotation of the object.
+	// This is the default.
+	StorageAnnotation Storage = "Annotation"
+
+	// StorageConfigMap stores transitions in a companion ConfigMap in the
+	// namespace of the object, see ConfigMapName. The ConfigMap is owned by
+	// the object, so it is deleted together with the object.
+	StorageConfigMap Storage = "ConfigMap"
+)
+
+// Config defines recording of condition transitions. Handlers that record
+// transitions of the same object share its history, so they should use the
+// same config. Zero value disables recording.
+type Config struct {
+	// MaxEntries is the maximum number of recorded transitions of all
+	// conditions of the object. When the history is full, the oldest
+	// transitions are dropped.
+	MaxEntries int
+	// MaxAge is the maximum age of recorded transitions. Older transitions
+	// are dropped when a new one is recorded. Zero value keeps transitions
+	// regardless of their age.
+	MaxAge time.Duration
+	// Storage defines where transitions are stored. Defaults to
+	// StorageAnnotation.
+	Storage Storage
+}
+
+// Enabled checks if recording is enabled.
+func (c Config) Enabled() bool {
+	return c.MaxEntries > 0
+}
+
+// Validate checks if history config is valid.
+func (c Config) Validate() error {
+	if c.MaxEntries < 0 {
+		return microerror.Maskf(errors.InvalidConfigError, "%T.MaxEntries must not be negative", c)
+	}
+	if c.MaxAge < 0 {
+		return microerror.Maskf(errors.InvalidConfigError, "%T.MaxAge must not be negative", c)
+	}
+	switch c.Storage {
+	case "", StorageAnnotation, StorageConfigMap:
+	default:
+		return microerror.Maskf(errors.InvalidConfigError, "%T.Storage must be %s or %s, got %q", c, StorageAnnotation, StorageConfigMap, c.Storage)
+	}
+
+	return nil
+}
+
+// Entry is a recorded condition transition. From is empty when the
+// condition was not set before, and To is empty when the condition has been
+// removed.
+type Entry struct {
+	Type   capi.ConditionType     `json:"type"`
+	From   corev1.ConditionStatus `json:"from,omitempty"`
+	To     corev1.ConditionStatus `json:"to,omitempty"`
+	Reason string                 `json:"reason,omitempty"`
+	Time   metav1.Time            `json:"time"`
+}
+
+func (e Entry) String() string {
+	from := e.From
+	if from == "" {
+		from = "-"
+	}
+	to := e.To
+	if to == "" {
+		to = "-"
+	}
+
+	text := fmt.Sprintf("%s %s %s -> %s", e.Time.UTC().Format(time.RFC3339), e.Type, from, to)
+	if e.Reason != "" {
+		text += " " + e.Reason
+	}
+
+	return text
+}
+
+// Append appends the transition of the condition of specified type from the
+// specified previous value to the specified current value, and drops the
+// oldest entries that exceed the configured retention limits. Only status
+// changes are transitions, so it returns false when the status has not
+// changed, or when the transition has already been recorded.
+func Append(entries []Entry, conditionType capi.ConditionType, previous, current *capi.Condition, config Config, now time.Time) ([]Entry, bool) {
+	var from, to corev1.ConditionStatus
+	if previous != nil {
+		from = previous.Status
+	}
+	if current != nil {
+		to = current.Status
+	}
+	if from == to {
+		return entries, false
+	}
+	// The transition is not recorded again when it is already the last
+	// recorded transition of the condition, e.g. when the object is
+	// reconciled again after its status update failed.
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Type == conditionType {
+			if entries[i].To == to {
+				return entries, false
+			}
+			break
+		}
+	}
+
+	entry := Entry{
+		Type: conditionType,
+		From: from,
+		To:   to,
+		Time: metav1.NewTime(now.UTC().Truncate(time.Second)),
+	}
+	if current != nil {
+		entry.Reason = current.Reason
+		if !current.LastTransitionTime.IsZero() {
+			entry.Time = metav1.NewTime(current.LastTransitionTime.UTC())
+		}
+	}
+
+	entries = append(entries, entry)
+	if config.MaxAge > 0 {
+		for len(entries) > 0 && now.Sub(entries[0].Time.Time) > config.MaxAge {
+			entries = entries[1:]
+		}
+	}
+	if len(entries) > config.MaxEntries {
+		entries = entries[len(entries)-config.MaxEntries:]
+	}
+
+	return entries, true
+}
+
+// Record records the transition of the condition of specified type from the
+// specified previous value to its current value on the object. With
+// StorageAnnotation, the history is set in the Annotation of the object and
+// true is returned, so that the caller can persist it. With
+// StorageConfigMap, the companion ConfigMap is created or updated.
+func Record(ctx context.Context, ctrlClient ctrl.Client, object conditions.Object, conditionType capi.ConditionType, previous *capi.Condition, config Config, now time.Time) (bool, error) {
+	if !config.Enabled() {
+		return false, nil
+	}
+
+	current := capiconditions.Get(object, conditionType)
+
+	if config.Storage == StorageConfigMap {
+		configMap, err := getConfigMap(ctx, ctrlClient, object)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		// Invalid history is ignored and overwritten, so that recording
+		// starts over instead of blocking condition updates.
+		entries, _ := DecodeConfigMap(configMap)
+		entries, changed := Append(entries, conditionType, previous, current, config, now)
+		if !changed {
+			return false, nil
+		}
+
+		err = saveConfigMap(ctx, ctrlClient, object, configMap, entries)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		return false, nil
+	}
+
+	entries, _ := DecodeAnnotation(object.GetAnnotations()[Annotation])
+	entries, changed := Append(entries, conditionType, previous, current, config, now)
+	if !changed {
+		return false, nil
+	}
+
+	value, err := EncodeAnnotation(entries)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[Annotation] = value
+	object.SetAnnotations(annotations)
+
+	return true, nil
+}
+
+// Read returns transitions recorded for the object in its Annotation and in
+// its companion ConfigMap, ordered by time.
+func Read(ctx context.Context, ctrlClient ctrl.Client, object conditions.Object) ([]Entry, error) {
+	entries, err := DecodeAnnotation(object.GetAnnotations()[Annotation])
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	configMap, err := getConfigMap(ctx, ctrlClient, object)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	configMapEntries, err := DecodeConfigMap(configMap)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return Merge(entries, configMapEntries), nil
+}
+
+// Merge merges the specified histories and orders their entries by time.
+func Merge(histories ...[]Entry) []Entry {
+	var entries []Entry
+	for _, history := range histories {
+		entries = append(entries, history...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(&entries[j].Time)
+	})
+
+	return entries
+}
+
+// ConfigMapName returns the name of the companion ConfigMap of the object
+// with the specified kind and name, e.g. cluster-test1-condition-history.
+func ConfigMapName(kind, name string) string {
+	return fmt.Sprintf("%s-%s-condition-history", strings.ToLower(kind), name)
+}
+
+// DecodeAnnotation decodes the value of the Annotation. Empty value is an
+// empty history.
+func DecodeAnnotation(value string) ([]Entry, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var entries []Entry
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return entries, nil
+}
+
+// DecodeConfigMap decodes the history stored in the companion ConfigMap.
+// Nil ConfigMap is an empty history.
+func DecodeConfigMap(configMap *corev1.ConfigMap) ([]Entry, error) {
+	if configMap == nil || configMap.Data[ConfigMapKey] == "" {
+		return nil, nil
+	}
+
+	var entries []Entry
+	err := json.Unmarshal([]byte(configMap.Data[ConfigMapKey]), &entries)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return entries, nil
+}
+
+// EncodeAnnotation encodes the history as the value of the Annotation,
+// dropping the oldest entries when the encoded value is larger than
+// MaxAnnotationSize.
+func EncodeAnnotation(entries []Entry) (string, error) {
+	for {
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		_, err = writer.Write(data)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+		err = writer.Close()
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		value := base64.StdEncoding.EncodeToString(compressed.Bytes())
+		if len(value) <= MaxAnnotationSize || len(entries) <= 1 {
+			return value, nil
+		}
+
+		// Dropping entries proportionally to the excess size, so that large
+		// histories are not encoded once per dropped entry.
+		keep := len(entries) * MaxAnnotationSize / len(value)
+		if keep >= len(entries) {
+			keep = len(entries) - 1
+		}
+		entries = entries[len(entries)-keep:]
+	}
+}
+
+// getConfigMap returns the companion ConfigMap of the object, or nil when it
+// does not exist.
+func getConfigMap(ctx context.Context, ctrlClient ctrl.Client, object conditions.Object) (*corev1.ConfigMap, error) {
+	gvk, err := apiutil.GVKForObject(object, ctrlClient.Scheme())
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = ctrlClient.Get(ctx, ctrl.ObjectKey{Namespace: object.GetNamespace(), Name: ConfigMapName(gvk.Kind, object.GetName())}, configMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return configMap, nil
+}
+
+// saveConfigMap creates the companion ConfigMap of the object when the
+// specified ConfigMap is nil, or updates it otherwise.
+func saveConfigMap(ctx context.Context, ctrlClient ctrl.Client, object conditions.Object, configMap *corev1.ConfigMap, entries []Entry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if configMap != nil {
+		configMap.Data = map[string]string{ConfigMapKey: string(data)}
+		err = ctrlClient.Update(ctx, configMap)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	gvk, err := apiutil.GVKForObject(object, ctrlClient.Scheme())
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	configMap = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: object.GetNamespace(),
+			Name:      ConfigMapName(gvk.Kind, object.GetName()),
+		},
+		Data: map[string]string{ConfigMapKey: string(data)},
+	}
+	err = controllerutil.SetOwnerReference(object, configMap, ctrlClient.Scheme())
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = ctrlClient.Create(ctx, configMap)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
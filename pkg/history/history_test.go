@@ -0,0 +1,370 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+func TestAppend(t *testing.T) {
+	start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		// config is the history config.
+		config Config
+		// statuses are the statuses observed in consecutive reconciliations,
+		// one per hour. Empty status means that the condition is not set.
+		statuses []corev1.ConditionStatus
+		// expected are the recorded transitions.
+		expected []string
+	}{
+		{
+			name:     "case 0: status changes are recorded",
+			config:   Config{MaxEntries: 10},
+			statuses: []corev1.ConditionStatus{corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionTrue},
+			expected: []string{
+				"2022-03-01T10:00:00Z Ready - -> False WaitingForInfrastructure",
+				"2022-03-01T12:00:00Z Ready False -> True",
+			},
+		},
+		{
+			name:     "case 1: removed condition is recorded",
+			config:   Config{MaxEntries: 10},
+			statuses: []corev1.ConditionStatus{corev1.ConditionTrue, ""},
+			expected: []string{
+				"2022-03-01T10:00:00Z Ready - -> True",
+				"2022-03-01T11:00:00Z Ready True -> -",
+			},
+		},
+		{
+			name:     "case 2: oldest transitions are dropped when history is full",
+			config:   Config{MaxEntries: 2},
+			statuses: []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionTrue},
+			expected: []string{
+				"2022-03-01T11:00:00Z Ready True -> False WaitingForInfrastructure",
+				"2022-03-01T12:00:00Z Ready False -> True",
+			},
+		},
+		{
+			name:     "case 3: transitions older than max age are dropped",
+			config:   Config{MaxEntries: 10, MaxAge: 90 * time.Minute},
+			statuses: []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionTrue},
+			expected: []string{
+				"2022-03-01T11:00:00Z Ready True -> False WaitingForInfrastructure",
+				"2022-03-01T12:00:00Z Ready False -> True",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			cluster := &capi.Cluster{}
+			var entries []Entry
+
+			for i, status := range tc.statuses {
+				now := start.Add(time.Duration(i) * time.Hour)
+				previous := capiconditions.Get(cluster, capi.ReadyCondition)
+				setStatus(cluster, status, now)
+
+				// act
+				entries, _ = Append(entries, capi.ReadyCondition, previous, capiconditions.Get(cluster, capi.ReadyCondition), tc.config, now)
+			}
+
+			// assert
+			var recorded []string
+			for _, entry := range entries {
+				recorded = append(recorded, entry.String())
+			}
+			if strings.Join(recorded, "\n") != strings.Join(tc.expected, "\n") {
+				t.Errorf("expected history:\n%s\ngot:\n%s", strings.Join(tc.expected, "\n"), strings.Join(recorded, "\n"))
+			}
+		})
+	}
+}
+
+func TestAppendRecordedTransition(t *testing.T) {
+	testName := "transition that is already recorded is not recorded again"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+		cluster := &capi.Cluster{}
+		setStatus(cluster, corev1.ConditionTrue, now.Add(-time.Hour))
+		previous := capiconditions.Get(cluster, capi.ReadyCondition)
+		setStatus(cluster, corev1.ConditionFalse, now)
+		entries, _ := Append(nil, capi.ReadyCondition, previous, capiconditions.Get(cluster, capi.ReadyCondition), Config{MaxEntries: 10}, now)
+
+		// act
+		// The status update failed, so the object is reconciled again with
+		// the previous status and the same transition is observed.
+		setStatus(cluster, corev1.ConditionFalse, now.Add(time.Minute))
+		entries, changed := Append(entries, capi.ReadyCondition, previous, capiconditions.Get(cluster, capi.ReadyCondition), Config{MaxEntries: 10}, now.Add(time.Minute))
+
+		// assert
+		if changed || len(entries) != 1 {
+			t.Errorf("expected single recorded transition, got %v", entries)
+		}
+	})
+}
+
+func TestRecord(t *testing.T) {
+	testCases := []struct {
+		name                       string
+		storage                    Storage
+		expectedAnnotationChanged  bool
+		expectedConfigMapCreated   bool
+		expectedRecordedTransition string
+	}{
+		{
+			name:                       "case 0: history is stored in annotation",
+			storage:                    StorageAnnotation,
+			expectedAnnotationChanged:  true,
+			expectedRecordedTransition: "2022-03-01T10:00:00Z Ready True -> False WaitingForInfrastructure",
+		},
+		{
+			name:                       "case 1: history is stored in companion ConfigMap",
+			storage:                    StorageConfigMap,
+			expectedConfigMapCreated:   true,
+			expectedRecordedTransition: "2022-03-01T10:00:00Z Ready True -> False WaitingForInfrastructure",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			ctx := context.Background()
+			ctrlClient := newFakeClient()
+			now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-test",
+					Name:      "test1",
+				},
+			}
+			err := ctrlClient.Create(ctx, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			setStatus(cluster, corev1.ConditionTrue, now.Add(-time.Hour))
+			previous := capiconditions.Get(cluster, capi.ReadyCondition)
+			setStatus(cluster, corev1.ConditionFalse, now)
+			config := Config{MaxEntries: 10, Storage: tc.storage}
+
+			// act
+			annotationChanged, err := Record(ctx, ctrlClient, cluster, capi.ReadyCondition, previous, config, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			if annotationChanged != tc.expectedAnnotationChanged {
+				t.Errorf("expected annotation changed to be %t, got %t", tc.expectedAnnotationChanged, annotationChanged)
+			}
+			configMap := &corev1.ConfigMap{}
+			err = ctrlClient.Get(ctx, ctrl.ObjectKey{Namespace: "org-test", Name: "cluster-test1-condition-history"}, configMap)
+			if (err == nil) != tc.expectedConfigMapCreated {
+				t.Errorf("expected ConfigMap created to be %t, got error %v", tc.expectedConfigMapCreated, err)
+			}
+			if err == nil && (len(configMap.OwnerReferences) != 1 || configMap.OwnerReferences[0].Name != "test1") {
+				t.Errorf("expected ConfigMap owned by Cluster test1, got owner references %v", configMap.OwnerReferences)
+			}
+			entries, err := Read(ctx, ctrlClient, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].String() != tc.expectedRecordedTransition {
+				t.Errorf("expected recorded transition %q, got %v", tc.expectedRecordedTransition, entries)
+			}
+
+			// Unchanged status is not recorded.
+			annotationChanged, err = Record(ctx, ctrlClient, cluster, capi.ReadyCondition, capiconditions.Get(cluster, capi.ReadyCondition), config, now.Add(time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if annotationChanged {
+				t.Errorf("expected annotation not to be changed when status has not changed")
+			}
+			entries, err = Read(ctx, ctrlClient, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("expected single recorded transition, got %v", entries)
+			}
+		})
+	}
+}
+
+func TestRecordInvalidAnnotation(t *testing.T) {
+	testName := "invalid history annotation is overwritten"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+		cluster := &capi.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{Annotation: "not base64"},
+			},
+		}
+		setStatus(cluster, corev1.ConditionTrue, now)
+
+		// act
+		_, err := Record(context.Background(), newFakeClient(), cluster, capi.ReadyCondition, nil, Config{MaxEntries: 10}, now)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := DecodeAnnotation(cluster.GetAnnotations()[Annotation])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("expected single recorded transition, got %v", entries)
+		}
+	})
+}
+
+func TestEncodeAnnotation(t *testing.T) {
+	testName := "oldest entries are dropped when annotation is too large"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+		random := rand.New(rand.NewSource(1)) // nolint:gosec
+		var entries []Entry
+		for i := 0; i < 5000; i++ {
+			entries = append(entries, Entry{
+				Type:   capi.ConditionType(fmt.Sprintf("Condition%d", i)),
+				To:     corev1.ConditionTrue,
+				Reason: fmt.Sprintf("Reason%016x", random.Uint64()),
+				Time:   metav1.NewTime(start.Add(time.Duration(i) * time.Second)),
+			})
+		}
+
+		// act
+		value, err := EncodeAnnotation(entries)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(value) > MaxAnnotationSize {
+			t.Errorf("expected annotation size at most %d, got %d", MaxAnnotationSize, len(value))
+		}
+		decoded, err := DecodeAnnotation(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded) == 0 || len(decoded) == len(entries) {
+			t.Fatalf("expected some entries to be dropped, got %d of %d", len(decoded), len(entries))
+		}
+		if decoded[len(decoded)-1].String() != entries[len(entries)-1].String() {
+			t.Errorf("expected newest entry %v to be kept, got %v", entries[len(entries)-1], decoded[len(decoded)-1])
+		}
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        Config
+		errorMatching func(error) bool
+	}{
+		{
+			name:   "case 0: zero value is valid",
+			config: Config{},
+		},
+		{
+			name:   "case 1: ConfigMap storage is valid",
+			config: Config{MaxEntries: 10, MaxAge: time.Hour, Storage: StorageConfigMap},
+		},
+		{
+			name:          "case 2: negative max entries",
+			config:        Config{MaxEntries: -1},
+			errorMatching: errors.IsInvalidConfig,
+		},
+		{
+			name:          "case 3: negative max age",
+			config:        Config{MaxEntries: 10, MaxAge: -time.Hour},
+			errorMatching: errors.IsInvalidConfig,
+		},
+		{
+			name:          "case 4: unknown storage",
+			config:        Config{MaxEntries: 10, Storage: "Secret"},
+			errorMatching: errors.IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+
+			// act
+			err := tc.config.Validate()
+
+			// assert
+			switch {
+			case err == nil && tc.errorMatching == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatching == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatching != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatching(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
+
+// setStatus sets Ready condition status, and its last transition time to the
+// specified time when the status has changed.
+func setStatus(cluster *capi.Cluster, status corev1.ConditionStatus, now time.Time) {
+	changed := !capiconditions.Has(cluster, capi.ReadyCondition) || capiconditions.Get(cluster, capi.ReadyCondition).Status != status
+	switch status {
+	case corev1.ConditionTrue:
+		capiconditions.MarkTrue(cluster, capi.ReadyCondition)
+	case corev1.ConditionFalse:
+		capiconditions.MarkFalse(cluster, capi.ReadyCondition, capi.WaitingForInfrastructureFallbackReason, capi.ConditionSeverityWarning, "")
+	default:
+		capiconditions.Delete(cluster, capi.ReadyCondition)
+		return
+	}
+
+	for i := range cluster.Status.Conditions {
+		if changed && cluster.Status.Conditions[i].Type == capi.ReadyCondition {
+			cluster.Status.Conditions[i].LastTransitionTime = metav1.NewTime(now)
+		}
+	}
+}
+
+func newFakeClient() ctrl.Client {
+	scheme := runtime.NewScheme()
+	for _, f := range []func(*runtime.Scheme) error{capi.AddToScheme, corev1.AddToScheme} {
+		err := f(scheme)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
@@ -0,0 +1,219 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/composite"
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+	"github.com/giantswarm/conditions-handler/pkg/factory"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/reconciler"
+)
+
+// newCluster returns a Cluster with ready infrastructure and control plane
+// objects.
+func newCluster(namespace string) (*capi.Cluster, *conditionstest.MockProviderCluster, *conditionstest.MockProviderCluster) {
+	infrastructure := conditionstest.NewMockProviderCluster(namespace, "test1").Ready().Build()
+	controlPlane := conditionstest.NewMockProviderCluster(namespace, "test1-control-plane").Ready().Build()
+	cluster := conditionstest.NewCluster(namespace, "test1").
+		WithLabel(capi.ClusterLabelName, "test1").
+		WithReleaseVersion("20.0.0").
+		WithInfrastructureRef(infrastructure).
+		WithControlPlaneRef(controlPlane).
+		Build()
+
+	return cluster, infrastructure, controlPlane
+}
+
+func newClusterConditionsHandler(t *testing.T) *composite.Handler {
+	t.Helper()
+
+	h, err := factory.NewClusterConditionsHandler(handler.Config{
+		CtrlClient: k8sClient,
+		Logger:     newLogger(t),
+		Name:       "clusterConditionsHandler",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestClusterConditionsHandler(t *testing.T) {
+	// arrange
+	namespace := newNamespace(t)
+	cluster, infrastructure, controlPlane := newCluster(namespace)
+	create(t, infrastructure, controlPlane, cluster)
+	h := newClusterConditionsHandler(t)
+
+	// act
+	err := h.EnsureCreated(context.Background(), get(t, cluster))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	expectConditions(t, cluster,
+		conditionstest.HaveConditionTrue(capi.InfrastructureReadyCondition),
+		conditionstest.HaveConditionTrue(capi.ControlPlaneReadyCondition),
+		conditionstest.HaveCondition(conditions.NodePoolsReady, corev1.ConditionFalse, conditions.NodePoolsNotFoundReason, capi.ConditionSeverityInfo),
+		conditionstest.HaveConditionTrue(capi.ReadyCondition),
+		conditionstest.HaveConditionTrue(conditions.Creating),
+		conditionstest.HaveCondition(conditions.Upgrading, corev1.ConditionFalse, conditions.UpgradeNotStartedReason, capi.ConditionSeverityInfo),
+	)
+}
+
+func TestMachinePoolConditionsHandler(t *testing.T) {
+	// arrange
+	namespace := newNamespace(t)
+	cluster, infrastructure, controlPlane := newCluster(namespace)
+	machinePoolInfrastructure := conditionstest.NewMockProviderCluster(namespace, "np1").Ready().Build()
+	machinePool := conditionstest.NewMachinePool(namespace, "np1").
+		ForCluster(cluster.Name).
+		WithReleaseVersion("20.0.0").
+		WithLastDeployedReleaseVersion("20.0.0").
+		WithReplicas(2, 2).
+		WithInfrastructureRef(machinePoolInfrastructure).
+		Build()
+	create(t, infrastructure, controlPlane, cluster, machinePoolInfrastructure, machinePool)
+
+	h, err := factory.NewMachinePoolConditionsHandler(handler.Config{
+		CtrlClient: k8sClient,
+		Logger:     newLogger(t),
+		Name:       "machinePoolConditionsHandler",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err = h.EnsureCreated(context.Background(), get(t, machinePool))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	expectConditions(t, machinePool,
+		conditionstest.HaveConditionTrue(capi.InfrastructureReadyCondition),
+		conditionstest.HaveConditionTrue(capiexp.ReplicasReadyCondition),
+		conditionstest.HaveConditionTrue(capi.ReadyCondition),
+		conditionstest.HaveCondition(conditions.Creating, corev1.ConditionFalse, conditions.ExistingObjectReason, capi.ConditionSeverityInfo),
+		conditionstest.HaveCondition(conditions.Upgrading, corev1.ConditionFalse, conditions.UpgradeNotStartedReason, capi.ConditionSeverityInfo),
+	)
+}
+
+// TestStatusUpdateConflict checks that a status update of a stale object is
+// rejected by the API server, that the handler does not fail on the
+// conflict, and that the next reconciliation saves the conditions.
+func TestStatusUpdateConflict(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	namespace := newNamespace(t)
+	cluster, infrastructure, controlPlane := newCluster(namespace)
+	create(t, infrastructure, controlPlane, cluster)
+	h := newClusterConditionsHandler(t)
+
+	stale := get(t, cluster).(*capi.Cluster)
+	updated := get(t, cluster).(*capi.Cluster)
+	updated.Labels["test"] = "conflict"
+	err := k8sClient.Update(ctx, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err = h.EnsureCreated(ctx, stale)
+
+	// assert
+	if err != nil {
+		t.Fatalf("expected conflict to be handled, got %s", err)
+	}
+	if len(get(t, cluster).(*capi.Cluster).Status.Conditions) != 0 {
+		t.Fatalf("expected conditions of stale object not to be saved")
+	}
+
+	r, err := reconciler.NewReconciler(reconciler.Config{
+		CtrlClient: k8sClient,
+		Logger:     newLogger(t),
+		Handler:    h,
+		Object:     &capi.Cluster{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: cluster.Name}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectConditions(t, cluster,
+		conditionstest.HaveConditionTrue(capi.ReadyCondition),
+		conditionstest.HaveConditionTrue(conditions.Creating),
+	)
+}
+
+// conflictingHandler updates the reconciled object in the API before running
+// the wrapped handler, so that the status update of the reconciled object
+// conflicts.
+type conflictingHandler struct {
+	handler.Interface
+	t *testing.T
+}
+
+func (h *conflictingHandler) EnsureCreated(ctx context.Context, obj interface{}) error {
+	cluster := obj.(*capi.Cluster)
+	updated := get(h.t, cluster).(*capi.Cluster)
+	updated.Labels["test"] = "conflict"
+	err := k8sClient.Update(ctx, updated)
+	if err != nil {
+		return err
+	}
+
+	return h.Interface.EnsureCreated(ctx, obj)
+}
+
+// TestReconcilerRequeuesOnConflict checks that the reconciler requeues the
+// object when its status update conflicts.
+func TestReconcilerRequeuesOnConflict(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	namespace := newNamespace(t)
+	cluster, infrastructure, controlPlane := newCluster(namespace)
+	create(t, infrastructure, controlPlane, cluster)
+
+	r, err := reconciler.NewReconciler(reconciler.Config{
+		CtrlClient: k8sClient,
+		Logger:     newLogger(t),
+		Handler:    &conflictingHandler{Interface: newClusterConditionsHandler(t), t: t},
+		Object:     &capi.Cluster{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: cluster.Name}})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Requeue {
+		t.Fatalf("expected object to be requeued, got %#v", result)
+	}
+	if capiconditions.Has(get(t, cluster).(*capi.Cluster), capi.ReadyCondition) {
+		t.Fatalf("expected conditions of conflicting update not to be saved")
+	}
+}
@@ -0,0 +1,248 @@
+//go:build integration
+// +build integration
+
+// Package integration contains tests that run condition handlers against a
+// real API server started with envtest, which has Cluster API CRDs and the
+// mock provider CRD installed. Unlike the fake client, the API server
+// enforces status subresource semantics, optimistic concurrency and CRD
+// schema validation.
+//
+// Run the tests with `make test-integration`, or with `go test -tags
+// integration ./pkg/integration/...` when KUBEBUILDER_ASSETS points to
+// kube-apiserver and etcd binaries.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/onsi/gomega/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+)
+
+const (
+	pollInterval = 100 * time.Millisecond
+	pollTimeout  = 30 * time.Second
+)
+
+var (
+	restConfig *rest.Config
+	k8sClient  ctrl.Client
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	capiDir, err := moduleDir("sigs.k8s.io/cluster-api")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	testEnv := &envtest.Environment{
+		Scheme: conditionstest.NewScheme(),
+		CRDDirectoryPaths: []string{
+			filepath.Join(capiDir, "config", "crd", "bases"),
+			filepath.Join("testdata", "crds"),
+		},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	restConfig, err = testEnv.Start()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start envtest, is KUBEBUILDER_ASSETS set? %s\n", err)
+		return 1
+	}
+	defer func() {
+		err := testEnv.Stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to stop envtest: %s\n", err)
+		}
+	}()
+
+	k8sClient, err = ctrl.New(restConfig, ctrl.Options{Scheme: conditionstest.NewScheme()})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+// moduleDir returns the directory of the specified module in the module
+// cache, so that CRDs match the Cluster API version in go.mod.
+func moduleDir(module string) (string, error) {
+	out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", module).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find module %s: %w", module, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// newNamespace creates a namespace that is deleted when the test is done.
+func newNamespace(t *testing.T) string {
+	t.Helper()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "conditions-",
+		},
+	}
+	err := k8sClient.Create(context.Background(), namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = k8sClient.Delete(context.Background(), namespace)
+	})
+
+	return namespace.Name
+}
+
+// create creates the specified objects together with their status, which is
+// ignored by the API server on create.
+func create(t *testing.T, objects ...ctrl.Object) {
+	t.Helper()
+
+	ctx := context.Background()
+	for _, object := range objects {
+		withStatus := object.DeepCopyObject().(ctrl.Object)
+
+		err := k8sClient.Create(ctx, object)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		withStatus.SetResourceVersion(object.GetResourceVersion())
+		withStatus.SetUID(object.GetUID())
+		withStatus.SetCreationTimestamp(object.GetCreationTimestamp())
+		err = k8sClient.Status().Update(ctx, withStatus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = k8sClient.Get(ctx, ctrl.ObjectKeyFromObject(object), object)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// get returns the current version of the specified object.
+func get(t *testing.T, object ctrl.Object) ctrl.Object {
+	t.Helper()
+
+	current := object.DeepCopyObject().(ctrl.Object)
+	err := k8sClient.Get(context.Background(), ctrl.ObjectKeyFromObject(object), current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return current
+}
+
+// expectConditions checks that the stored object matches all matchers.
+func expectConditions(t *testing.T, object ctrl.Object, matchers ...types.GomegaMatcher) {
+	t.Helper()
+
+	current := get(t, object)
+	for _, matcher := range matchers {
+		ok, err := matcher.Match(current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Error(matcher.FailureMessage(current))
+		}
+	}
+}
+
+// eventuallyConditions waits until the stored object matches all matchers.
+func eventuallyConditions(t *testing.T, object ctrl.Object, matchers ...types.GomegaMatcher) {
+	t.Helper()
+
+	var failure string
+	err := wait.PollImmediate(pollInterval, pollTimeout, func() (bool, error) {
+		current := object.DeepCopyObject().(ctrl.Object)
+		err := k8sClient.Get(context.Background(), ctrl.ObjectKeyFromObject(object), current)
+		if err != nil {
+			return false, err
+		}
+
+		for _, matcher := range matchers {
+			ok, err := matcher.Match(current)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				failure = matcher.FailureMessage(current)
+				return false, nil
+			}
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("%s: %s", err, failure)
+	}
+}
+
+// startManager starts a manager, set up with the specified func, that is
+// stopped when the test is done.
+func startManager(t *testing.T, setup func(mgr manager.Manager) error) {
+	t.Helper()
+
+	mgr, err := manager.New(restConfig, manager.Options{
+		Scheme:             conditionstest.NewScheme(),
+		MetricsBindAddress: "0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = setup(mgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- mgr.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		err := <-done
+		if err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func newLogger(t *testing.T) micrologger.Logger {
+	t.Helper()
+
+	logger, err := micrologger.New(micrologger.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return logger
+}
@@ -0,0 +1,123 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/infrastructureready"
+	"github.com/giantswarm/conditions-handler/pkg/conditionstest"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/reconciler"
+)
+
+func setupClusterReconciler(t *testing.T, h handler.Interface) func(mgr manager.Manager) error {
+	return func(mgr manager.Manager) error {
+		r, err := reconciler.NewReconciler(reconciler.Config{
+			CtrlClient: mgr.GetClient(),
+			Logger:     newLogger(t),
+			Handler:    h,
+			Object:     &capi.Cluster{},
+		})
+		if err != nil {
+			return err
+		}
+
+		return r.SetupWithManager(mgr)
+	}
+}
+
+// TestInfrastructureWatch checks that a change of the infrastructure object
+// triggers reconciliation of its Cluster.
+func TestInfrastructureWatch(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	namespace := newNamespace(t)
+	infrastructure := conditionstest.NewMockProviderCluster(namespace, "test1").
+		NotReady("Provisioning", capi.ConditionSeverityWarning, "Infrastructure is being provisioned").
+		Build()
+	cluster := conditionstest.NewCluster(namespace, "test1").
+		WithLabel(capi.ClusterLabelName, "test1").
+		WithInfrastructureRef(infrastructure).
+		Build()
+	create(t, cluster)
+	infrastructure.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: capi.GroupVersion.String(),
+			Kind:       "Cluster",
+			Name:       cluster.Name,
+			UID:        cluster.UID,
+		},
+	}
+	create(t, infrastructure)
+
+	h, err := infrastructureready.NewHandler(infrastructureready.HandlerConfig{
+		CtrlClient: k8sClient,
+		Logger:     newLogger(t),
+		Name:       "infrastructureWatchTestHandler",
+		InfrastructureGVKs: []schema.GroupVersionKind{
+			conditionstest.MockGroupVersion.WithKind("MockProviderCluster"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	startManager(t, setupClusterReconciler(t, h))
+	eventuallyConditions(t, cluster,
+		conditionstest.HaveCondition(capi.InfrastructureReadyCondition, corev1.ConditionFalse, "Provisioning", capi.ConditionSeverityWarning),
+	)
+
+	// act
+	updated := get(t, infrastructure).(*conditionstest.MockProviderCluster)
+	capiconditions.MarkTrue(updated, capi.ReadyCondition)
+	err = k8sClient.Status().Update(ctx, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	eventuallyConditions(t, cluster,
+		conditionstest.HaveConditionTrue(capi.InfrastructureReadyCondition),
+	)
+}
+
+// TestMachinePoolWatch checks that creating a MachinePool triggers
+// reconciliation of its Cluster with the Cluster conditions handler.
+func TestMachinePoolWatch(t *testing.T) {
+	// arrange
+	namespace := newNamespace(t)
+	cluster, infrastructure, controlPlane := newCluster(namespace)
+	create(t, infrastructure, controlPlane, cluster)
+
+	h := newClusterConditionsHandler(t)
+	startManager(t, setupClusterReconciler(t, h))
+	eventuallyConditions(t, cluster,
+		conditionstest.HaveCondition(conditions.NodePoolsReady, corev1.ConditionFalse, conditions.NodePoolsNotFoundReason, capi.ConditionSeverityInfo),
+	)
+
+	// act
+	machinePoolInfrastructure := conditionstest.NewMockProviderCluster(namespace, "np1").Ready().Build()
+	machinePool := conditionstest.NewMachinePool(namespace, "np1").
+		ForCluster(cluster.Name).
+		WithReleaseVersion("20.0.0").
+		WithReplicas(1, 1).
+		WithInfrastructureRef(machinePoolInfrastructure).
+		WithCondition(capiconditions.TrueCondition(capi.ReadyCondition)).
+		Build()
+	create(t, machinePoolInfrastructure, machinePool)
+
+	// assert
+	eventuallyConditions(t, cluster,
+		conditionstest.HaveConditionTrue(conditions.NodePoolsReady),
+	)
+}
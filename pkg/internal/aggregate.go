@@ -0,0 +1,54 @@
+package internal
+
+import (
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// readyConditionGetter wraps an object and exposes one of its conditions as
+// Ready condition.
+type readyConditionGetter struct {
+	capiconditions.Getter
+	conditions capi.Conditions
+}
+
+func (g *readyConditionGetter) GetConditions() capi.Conditions {
+	return g.conditions
+}
+
+// NewReadyConditionGetter returns a capiconditions.Getter that exposes the
+// condition of specified type from the specified object as its Ready
+// condition. capiconditions.SetAggregate always aggregates Ready conditions
+// of the source objects, so this is used to aggregate other conditions.
+//
+// Optional transform func can be used to change the exposed condition, e.g.
+// to invert negative polarity conditions like Upgrading. When the condition
+// is not set, transform func is called with nil.
+func NewReadyConditionGetter(from capiconditions.Getter, conditionType capi.ConditionType, transform func(*capi.Condition) *capi.Condition) capiconditions.Getter {
+	condition := capiconditions.Get(from, conditionType)
+	if transform != nil {
+		condition = transform(condition)
+	}
+
+	var conditions capi.Conditions
+	if condition != nil {
+		readyCondition := *condition
+		readyCondition.Type = capi.ReadyCondition
+		conditions = capi.Conditions{readyCondition}
+	}
+
+	return &readyConditionGetter{
+		Getter:     from,
+		conditions: conditions,
+	}
+}
+
+// Aggregate returns a condition of specified type that is computed in the
+// same way as capiconditions.SetAggregate computes it, without setting it on
+// any object. It returns nil when none of the source objects has Ready
+// condition set.
+func Aggregate(from []capiconditions.Getter, conditionType capi.ConditionType, options ...capiconditions.MergeOption) *capi.Condition {
+	target := &capi.Cluster{}
+	capiconditions.SetAggregate(target, conditionType, from, options...)
+	return capiconditions.Get(target, conditionType)
+}
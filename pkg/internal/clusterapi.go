@@ -9,6 +9,7 @@ import (
 	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/cache"
 	"github.com/giantswarm/conditions-handler/pkg/errors"
 )
 
@@ -28,7 +29,7 @@ func ListMachinePoolsByMetadata(ctx context.Context, c client.Client, obj metav1
 
 func ListMachinePoolsByClusterID(ctx context.Context, c client.Client, clusterNamespace, clusterID string) (*capiexp.MachinePoolList, error) {
 	machinePools := &capiexp.MachinePoolList{}
-	err := c.List(ctx, machinePools, client.MatchingLabels{capi.ClusterLabelName: clusterID}, client.InNamespace(clusterNamespace))
+	err := cache.List(ctx, c, machinePools, client.MatchingLabels{capi.ClusterLabelName: clusterID}, client.InNamespace(clusterNamespace))
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
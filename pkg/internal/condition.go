@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// SetConditionAt sets the specified condition like capiconditions.Set, but
+// LastTransitionTime is changed only when the condition status changes, and
+// then it is set to the specified time. Conditions like Upgrading or Scaling
+// report progress in their messages, and their LastTransitionTime still tells
+// when the progress started.
+func SetConditionAt(object conditions.Object, condition *capi.Condition, now time.Time) {
+	lastTransitionTime := metav1.NewTime(now.UTC().Truncate(time.Second))
+	if existing := capiconditions.Get(object, condition.Type); existing != nil && existing.Status == condition.Status {
+		lastTransitionTime = existing.LastTransitionTime
+	}
+
+	capiconditions.Set(object, condition)
+
+	objectConditions := object.GetConditions()
+	for i := range objectConditions {
+		if objectConditions[i].Type == condition.Type {
+			objectConditions[i].LastTransitionTime = lastTransitionTime
+		}
+	}
+	object.SetConditions(objectConditions)
+}
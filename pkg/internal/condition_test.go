@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestSetConditionAt(t *testing.T) {
+	start := time.Date(2022, 3, 31, 10, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Minute)
+
+	testCases := []struct {
+		name                       string
+		condition                  *capi.Condition
+		expectedLastTransitionTime time.Time
+	}{
+		{
+			name: "case 0: LastTransitionTime is kept when only the message changes",
+			condition: &capi.Condition{
+				Type:    "Upgrading",
+				Status:  corev1.ConditionTrue,
+				Message: "Control plane upgrade done, 1/3 node pools upgraded",
+			},
+			expectedLastTransitionTime: start,
+		},
+		{
+			name:                       "case 1: LastTransitionTime is set to the specified time when the status changes",
+			condition:                  capiconditions.FalseCondition("Upgrading", "UpgradeCompleted", capi.ConditionSeverityInfo, ""),
+			expectedLastTransitionTime: now,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			cluster := &capi.Cluster{}
+			SetConditionAt(cluster, capiconditions.TrueCondition("Upgrading"), start)
+
+			// act
+			SetConditionAt(cluster, tc.condition, now)
+
+			// assert
+			condition := capiconditions.Get(cluster, "Upgrading")
+			if !AreEqualWithIgnoringLastTransitionTime(condition, tc.condition) {
+				t.Errorf("expected %s, got %s", SprintComparedCondition(tc.condition), SprintComparedCondition(condition))
+			}
+			if !condition.LastTransitionTime.Time.Equal(tc.expectedLastTransitionTime) {
+				t.Errorf("expected LastTransitionTime %s, got %s", tc.expectedLastTransitionTime, condition.LastTransitionTime.Time)
+			}
+		})
+	}
+}
@@ -2,26 +2,48 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/giantswarm/conditions/pkg/conditions"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/conditions-handler/pkg/damping"
 	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/history"
 )
 
 type HandlerConfig struct {
 	CtrlClient ctrl.Client
 	Logger     micrologger.Logger
 
-	UpdateStatus      bool
-	ConditionType     capi.ConditionType
+	UpdateStatus  bool
+	ConditionType capi.ConditionType
+	Damping       damping.Config
+	// History defines recording of condition transitions, see
+	// history.Config. Transitions are recorded after the status is saved. By
+	// default transitions are not recorded.
+	History history.Config
+	// RunWhenPaused runs EnsureCreatedFunc also for paused objects and
+	// objects of paused Clusters. By default the condition is left
+	// untouched while the object is paused.
+	RunWhenPaused bool
+	// PostCheck checks the condition after EnsureCreatedFunc, and fails the
+	// handler when the condition is invalid. By default the condition is not
+	// checked.
+	PostCheck handler.PostCheckFunc
+	// Now returns the current time used by the handler, see Handler.Now.
+	// Defaults to time.Now.
+	Now               func() time.Time
 	EnsureCreatedFunc func(ctx context.Context, object conditions.Object) error
 	EnsureDeletedFunc func(ctx context.Context, object conditions.Object) error
 }
@@ -32,6 +54,11 @@ type Handler struct {
 
 	conditionType     capi.ConditionType
 	updateStatus      bool
+	damping           damping.Config
+	history           history.Config
+	runWhenPaused     bool
+	postCheck         handler.PostCheckFunc
+	now               func() time.Time
 	ensureCreatedFunc func(ctx context.Context, object conditions.Object) error
 	ensureDeletedFunc func(ctx context.Context, object conditions.Object) error
 }
@@ -43,12 +70,28 @@ func NewHandler(config HandlerConfig) (*Handler, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Logger must not be empty", config)
 	}
+	err := config.Damping.Validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	err = config.History.Validate()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
 
 	h := &Handler{
 		ctrlClient:        config.CtrlClient,
 		logger:            config.Logger,
 		conditionType:     config.ConditionType,
 		updateStatus:      config.UpdateStatus,
+		damping:           config.Damping,
+		history:           config.History,
+		runWhenPaused:     config.RunWhenPaused,
+		postCheck:         config.PostCheck,
+		now:               config.Now,
 		ensureCreatedFunc: config.EnsureCreatedFunc,
 		ensureDeletedFunc: config.EnsureDeletedFunc,
 	}
@@ -83,16 +126,117 @@ func (h *Handler) EnsureCreated(ctx context.Context, object conditions.Object) (
 		}
 	}()
 
-	err = h.ensureCreatedFunc(ctx, object)
-	if err != nil {
-		return microerror.Mask(err)
+	// Frozen conditions and conditions of paused objects are not changed,
+	// but the status is still updated below, so that changes made by
+	// handlers that run when paused, like Paused condition handler, are
+	// saved.
+	skipped := false
+	if IsFrozen(object, h.conditionType) {
+		h.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("skipping condition %s, it is frozen with %s annotation", h.conditionType, SkipAnnotation))
+		skippedTotal.WithLabelValues(string(h.conditionType), skipReasonFrozen).Inc()
+		skipped = true
+	} else if !h.runWhenPaused {
+		var pauseInfo PauseInfo
+		pauseInfo, err = GetPauseInfo(ctx, h.ctrlClient, object)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if pauseInfo.Paused {
+			h.logger.Debugf(ctx, "skipping condition %s, %s is paused with %s", h.conditionType, pauseInfo.Object, pauseInfo.PausedBy)
+			skippedTotal.WithLabelValues(string(h.conditionType), skipReasonPaused).Inc()
+			skipped = true
+		}
+	}
+
+	if !skipped {
+		err = h.ensureCreatedFunc(ctx, object)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		// Post-check runs before anything is written, so that an invalid
+		// condition does not change the object at all.
+		if h.postCheck != nil {
+			err = h.postCheck(object, h.conditionType)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		pendingTransitionsChanged := damping.Apply(object, h.conditionType, initialConditionValue, h.damping, h.now())
+		if pendingTransitionsChanged {
+			err = h.patchAnnotations(ctx, object, damping.PendingTransitionsAnnotation)
+			if apierrors.IsConflict(err) {
+				h.logger.Debugf(ctx, "conflict trying to save object in k8s API concurrently", "stack", microerror.JSON(microerror.Mask(err)))
+				h.logger.Debugf(ctx, "cancelling resource")
+				return nil
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+		}
 	}
 
 	currentConditionValue := capiconditions.Get(object, h.conditionType)
 	conditionChanged = !conditions.AreEqual(initialConditionValue, currentConditionValue)
 
+	pending, hasPending := pendingHistoryFromContext(ctx)
+
 	if h.updateStatus {
 		err = h.ctrlClient.Status().Update(ctx, object)
+		if apierrors.IsConflict(err) {
+			h.logger.Debugf(ctx, "conflict trying to save object in k8s API concurrently", "stack", microerror.JSON(microerror.Mask(err)))
+			h.logger.Debugf(ctx, "cancelling resource")
+			// Transitions of the previous handlers are not saved either.
+			if hasPending {
+				pending.discard()
+			}
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		// Transitions of the previous handlers are saved with this status
+		// update, so they are recorded now.
+		if hasPending {
+			err = pending.Record(ctx)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	// History is recorded only after the condition is saved, so that
+	// transitions that are not saved, e.g. because of a conflict, are not
+	// recorded. Handlers that do not save the status leave their transitions
+	// in PendingHistory carried by the context, until the status is saved.
+	// Without PendingHistory they are recorded right away, and the caller
+	// that saves the status must handle conflicts.
+	if !skipped {
+		if hasPending && !h.updateStatus {
+			now := h.now()
+			pending.add(func(ctx context.Context) error {
+				return h.recordHistory(ctx, object, initialConditionValue, now)
+			})
+		} else {
+			err = h.recordHistory(ctx, object, initialConditionValue, h.now())
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordHistory records the transition of the condition from the specified
+// initial value, and saves the history when it is changed.
+func (h *Handler) recordHistory(ctx context.Context, object conditions.Object, initialConditionValue *capi.Condition, now time.Time) error {
+	historyChanged, err := history.Record(ctx, h.ctrlClient, object, h.conditionType, initialConditionValue, h.history, now)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if historyChanged {
+		err = h.patchAnnotations(ctx, object, history.Annotation)
 		if apierrors.IsConflict(err) {
 			h.logger.Debugf(ctx, "conflict trying to save object in k8s API concurrently", "stack", microerror.JSON(microerror.Mask(err)))
 			h.logger.Debugf(ctx, "cancelling resource")
@@ -105,6 +249,13 @@ func (h *Handler) EnsureCreated(ctx context.Context, object conditions.Object) (
 	return nil
 }
 
+// Now returns the current time, as measured by the clock of the handler.
+// Condition handlers use it instead of time.Now, so that they can be run with
+// a fake clock.
+func (h *Handler) Now() time.Time {
+	return h.now()
+}
+
 func (h *Handler) EnsureDeleted(_ context.Context, _ conditions.Object) (err error) {
 	if h.ensureDeletedFunc == nil {
 		return
@@ -113,6 +264,46 @@ func (h *Handler) EnsureDeleted(_ context.Context, _ conditions.Object) (err err
 	return nil
 }
 
+// patchAnnotations persists the specified annotations, like pending
+// condition transitions and condition history, which are not saved by status
+// update. Annotations missing in the object are removed.
+func (h *Handler) patchAnnotations(ctx context.Context, object conditions.Object, annotations ...string) error {
+	values := map[string]interface{}{}
+	for _, annotation := range annotations {
+		var value interface{}
+		if v, ok := object.GetAnnotations()[annotation]; ok {
+			value = v
+		}
+		values[annotation] = value
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": values,
+		},
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Patching a copy, so that the condition changes made in this
+	// reconciliation are not overwritten with the object from the API.
+	patched, ok := object.DeepCopyObject().(ctrl.Object)
+	if !ok {
+		return microerror.Maskf(errors.WrongTypeError, "expected 'client.Object', got '%T'", object)
+	}
+
+	err = h.ctrlClient.Patch(ctx, patched, ctrl.RawPatch(types.MergePatchType, patch))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	h.logger.Debugf(ctx, "saved annotations %v of condition %s", annotations, h.conditionType)
+	object.SetResourceVersion(patched.GetResourceVersion())
+
+	return nil
+}
+
 func sprintCondition(conditionType capi.ConditionType, condition *capi.Condition) string {
 	var text string
 	if condition != nil {
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/damping"
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/history"
+)
+
+func TestHistory(t *testing.T) {
+	testCases := []struct {
+		name               string
+		history            history.Config
+		statuses           []corev1.ConditionStatus
+		expectedHistory    []corev1.ConditionStatus
+		expectedAnnotation bool
+	}{
+		{
+			name:     "case 0: history is not recorded by default",
+			statuses: []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse},
+		},
+		{
+			name:               "case 1: transitions are saved in history annotation",
+			history:            history.Config{MaxEntries: 10},
+			statuses:           []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionFalse},
+			expectedHistory:    []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse},
+			expectedAnnotation: true,
+		},
+		{
+			name:            "case 2: transitions are saved in companion ConfigMap",
+			history:         history.Config{MaxEntries: 10, Storage: history.StorageConfigMap},
+			statuses:        []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse},
+			expectedHistory: []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			ctx := context.Background()
+			logger, err := micrologger.New(micrologger.Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctrlClient := NewFakeClient(capi.AddToScheme, corev1.AddToScheme)
+			var status corev1.ConditionStatus
+			h, err := NewHandler(HandlerConfig{
+				CtrlClient:    ctrlClient,
+				Logger:        logger,
+				ConditionType: conditions.Upgrading,
+				History:       tc.history,
+				EnsureCreatedFunc: func(_ context.Context, object conditions.Object) error {
+					if status == corev1.ConditionTrue {
+						capiconditions.MarkTrue(object, conditions.Upgrading)
+					} else {
+						capiconditions.MarkFalse(object, conditions.Upgrading, conditions.UpgradeCompletedReason, capi.ConditionSeverityInfo, "")
+					}
+					return nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "org-test",
+					Name:      "test1",
+				},
+			}
+			err = ctrlClient.Create(ctx, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			for _, status = range tc.statuses {
+				err = h.EnsureCreated(ctx, cluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// assert
+			saved := &capi.Cluster{}
+			err = ctrlClient.Get(ctx, ctrl.ObjectKeyFromObject(cluster), saved)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := history.Read(ctx, ctrlClient, saved)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var recorded []corev1.ConditionStatus
+			for _, entry := range entries {
+				recorded = append(recorded, entry.To)
+			}
+			if len(recorded) != len(tc.expectedHistory) {
+				t.Fatalf("expected recorded transitions to %v, got %v", tc.expectedHistory, recorded)
+			}
+			for i := range recorded {
+				if recorded[i] != tc.expectedHistory[i] {
+					t.Fatalf("expected recorded transitions to %v, got %v", tc.expectedHistory, recorded)
+				}
+			}
+			_, hasAnnotation := saved.GetAnnotations()[history.Annotation]
+			if hasAnnotation != tc.expectedAnnotation {
+				t.Errorf("expected %s annotation set to be %t, got %t", history.Annotation, tc.expectedAnnotation, hasAnnotation)
+			}
+		})
+	}
+}
+
+func TestPostCheckRunsBeforeWrites(t *testing.T) {
+	testName := "object is not changed when post-check fails"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		ctx := context.Background()
+		logger, err := micrologger.New(micrologger.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctrlClient := NewFakeClient(capi.AddToScheme, corev1.AddToScheme)
+		h, err := NewHandler(HandlerConfig{
+			CtrlClient:    ctrlClient,
+			Logger:        logger,
+			UpdateStatus:  true,
+			ConditionType: conditions.Upgrading,
+			Damping:       damping.Config{FalseAfterReconciles: 3},
+			History:       history.Config{MaxEntries: 10},
+			PostCheck: func(_ conditions.Object, _ capi.ConditionType) error {
+				return microerror.Maskf(errors.InvalidConditionError, "invalid")
+			},
+			EnsureCreatedFunc: func(_ context.Context, object conditions.Object) error {
+				capiconditions.MarkFalse(object, conditions.Upgrading, conditions.UpgradeCompletedReason, capi.ConditionSeverityInfo, "")
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cluster := &capi.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "org-test",
+				Name:      "test1",
+			},
+		}
+		capiconditions.MarkTrue(cluster, conditions.Upgrading)
+		err = ctrlClient.Create(ctx, cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err = h.EnsureCreated(ctx, cluster)
+
+		// assert
+		if !errors.IsInvalidCondition(err) {
+			t.Fatalf("expected invalid condition error, got %#v", err)
+		}
+		saved := &capi.Cluster{}
+		err = ctrlClient.Get(ctx, ctrl.ObjectKeyFromObject(cluster), saved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(saved.GetAnnotations()) > 0 {
+			t.Errorf("expected no annotations to be saved, got %v", saved.GetAnnotations())
+		}
+		if !capiconditions.IsTrue(saved, conditions.Upgrading) {
+			t.Errorf("expected Upgrading condition not to be changed, got %s", SprintComparedCondition(capiconditions.Get(saved, conditions.Upgrading)))
+		}
+	})
+}
@@ -11,3 +11,14 @@ const (
 	// UpgradingToNodePools is set to True during the first cluster upgrade to node pools release.
 	UpgradingToNodePools = "release.giantswarm.io/upgrading-to-node-pools"
 )
+
+const (
+	// AutoscalerMinSize is the cluster-autoscaler annotation that defines the
+	// minimum size of a node group, i.e. the minimum number of MachinePool
+	// replicas.
+	AutoscalerMinSize = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"
+
+	// LegacyAutoscalerMinSize is the older variant of AutoscalerMinSize
+	// annotation that is still supported by cluster-autoscaler.
+	LegacyAutoscalerMinSize = "cluster.k8s.io/cluster-api-autoscaler-node-group-min-size"
+)
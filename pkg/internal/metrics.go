@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	skipReasonPaused = "paused"
+	skipReasonFrozen = "frozen"
+)
+
+var skippedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "conditions_handler",
+		Name:      "skipped_total",
+		Help:      "Number of times a condition handler skipped an object, by condition type and reason.",
+	},
+	[]string{"condition_type", "reason"},
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(skippedTotal)
+}
@@ -1,3 +1,5 @@
+// +groupName=mock.giantswarm.io
+// +versionName=v1alpha1
 package internal
 
 import (
@@ -41,6 +43,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 }
 
 // MockProviderCluster is the Schema for the mockproviderclusters API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 type MockProviderCluster struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -86,6 +90,8 @@ func (c *MockProviderCluster) DeepCopyInto(out *MockProviderCluster) {
 	*out = *c
 }
 
+// MockProviderClusterList contains a list of MockProviderCluster
+// +kubebuilder:object:root=true
 type MockProviderClusterList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata"`
@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/cache"
+)
+
+const (
+	// PausedBySpec is set in PauseInfo.PausedBy when a Cluster is paused
+	// with Cluster.Spec.Paused.
+	PausedBySpec = "Spec.Paused"
+	// PausedByAnnotation is set in PauseInfo.PausedBy when an object is
+	// paused with cluster.x-k8s.io/paused annotation.
+	PausedByAnnotation = "annotation"
+)
+
+// PauseInfo describes why and by whom an object is paused.
+type PauseInfo struct {
+	// Paused is true when the object or its Cluster is paused.
+	Paused bool
+	// Object is the paused object, i.e. the reconciled object or its
+	// Cluster, in the form "Kind namespace/name".
+	Object string
+	// PausedBy is either PausedBySpec or PausedByAnnotation.
+	PausedBy string
+	// Manager is the field manager that paused the object, when it is known
+	// from managed fields.
+	Manager string
+	// Since is the time when the object was paused, when it is known from
+	// managed fields.
+	Since *metav1.Time
+}
+
+// GetPauseInfo checks if the specified object is paused with
+// cluster.x-k8s.io/paused annotation, or, for Clusters, with Spec.Paused. For
+// other objects, their Cluster is checked as well.
+func GetPauseInfo(ctx context.Context, client ctrl.Client, object conditions.Object) (PauseInfo, error) {
+	if info := getObjectPauseInfo(object); info.Paused {
+		return info, nil
+	}
+
+	if _, ok := object.(*capi.Cluster); ok {
+		return PauseInfo{}, nil
+	}
+
+	clusterName := object.GetLabels()[capi.ClusterLabelName]
+	if machinePool, ok := object.(*capiexp.MachinePool); ok && clusterName == "" {
+		clusterName = machinePool.Spec.ClusterName
+	}
+	if clusterName == "" {
+		return PauseInfo{}, nil
+	}
+
+	cluster := &capi.Cluster{}
+	err := cache.Get(ctx, client, ctrl.ObjectKey{Namespace: object.GetNamespace(), Name: clusterName}, cluster)
+	if apierrors.IsNotFound(microerror.Cause(err)) {
+		return PauseInfo{}, nil
+	} else if err != nil {
+		return PauseInfo{}, microerror.Mask(err)
+	}
+
+	return getObjectPauseInfo(cluster), nil
+}
+
+func getObjectPauseInfo(object conditions.Object) PauseInfo {
+	var pausedBy string
+	var fieldPath []string
+	if cluster, ok := object.(*capi.Cluster); ok && cluster.Spec.Paused {
+		pausedBy = PausedBySpec
+		fieldPath = []string{"f:spec", "f:paused"}
+	} else if _, ok := object.GetAnnotations()[capi.PausedAnnotation]; ok {
+		pausedBy = PausedByAnnotation
+		fieldPath = []string{"f:metadata", "f:annotations", "f:" + capi.PausedAnnotation}
+	} else {
+		return PauseInfo{}
+	}
+
+	info := PauseInfo{
+		Paused:   true,
+		Object:   fmt.Sprintf("%s %s/%s", objectKind(object), object.GetNamespace(), object.GetName()),
+		PausedBy: pausedBy,
+	}
+
+	for _, entry := range object.GetManagedFields() {
+		if entry.FieldsV1 == nil || !hasField(entry.FieldsV1.Raw, fieldPath) {
+			continue
+		}
+		if info.Since == nil || (entry.Time != nil && info.Since.Before(entry.Time)) {
+			info.Manager = entry.Manager
+			info.Since = entry.Time
+		}
+	}
+
+	return info
+}
+
+func hasField(raw []byte, fieldPath []string) bool {
+	var fields map[string]interface{}
+	err := json.Unmarshal(raw, &fields)
+	if err != nil {
+		return false
+	}
+
+	for _, field := range fieldPath {
+		value, ok := fields[field]
+		if !ok {
+			return false
+		}
+		fields, _ = value.(map[string]interface{})
+	}
+
+	return true
+}
+
+func objectKind(object conditions.Object) string {
+	switch object.(type) {
+	case *capi.Cluster:
+		return "Cluster"
+	case *capiexp.MachinePool:
+		return "MachinePool"
+	default:
+		kind := object.GetObjectKind().GroupVersionKind().Kind
+		if kind == "" {
+			kind = fmt.Sprintf("%T", object)
+		}
+		return kind
+	}
+}
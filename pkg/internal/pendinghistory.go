@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+)
+
+type pendingHistoryContextKey struct{}
+
+// PendingHistory collects condition transitions of handlers that do not save
+// the object status, so that they are recorded only after the status is
+// saved by another handler in the same pass, e.g. by the last handler of a
+// composite handler, or by reconciler.Reconciler. This way transitions that
+// are not saved because of a conflict are not recorded.
+type PendingHistory struct {
+	records []func(ctx context.Context) error
+}
+
+// NewPendingHistoryContext returns a context that carries new pending
+// history, and true. When the specified context already carries pending
+// history, e.g. in nested composite handlers, it is returned with false, so
+// that it is recorded by whoever created it.
+func NewPendingHistoryContext(ctx context.Context) (context.Context, *PendingHistory, bool) {
+	if pending, ok := pendingHistoryFromContext(ctx); ok {
+		return ctx, pending, false
+	}
+
+	pending := &PendingHistory{}
+	return context.WithValue(ctx, pendingHistoryContextKey{}, pending), pending, true
+}
+
+// Record records all pending transitions.
+func (p *PendingHistory) Record(ctx context.Context) error {
+	records := p.records
+	p.records = nil
+	for _, record := range records {
+		err := record(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PendingHistory) discard() {
+	p.records = nil
+}
+
+func (p *PendingHistory) add(record func(ctx context.Context) error) {
+	p.records = append(p.records, record)
+}
+
+func pendingHistoryFromContext(ctx context.Context) (*PendingHistory, bool) {
+	pending, ok := ctx.Value(pendingHistoryContextKey{}).(*PendingHistory)
+	return pending, ok
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// SkipAnnotation is a comma-separated list of condition types that are
+	// frozen on the annotated object, e.g. "Upgrading,ReplicasReady".
+	// Handlers of frozen conditions leave them untouched, e.g. during
+	// incident handling or a manual rollback, and Frozen condition handler
+	// lists them in Frozen condition.
+	SkipAnnotation = "conditions.giantswarm.io/skip"
+)
+
+// FrozenConditions returns condition types listed in SkipAnnotation of the
+// specified object.
+func FrozenConditions(object conditions.Object) []capi.ConditionType {
+	value, ok := object.GetAnnotations()[SkipAnnotation]
+	if !ok {
+		return nil
+	}
+
+	var conditionTypes []capi.ConditionType
+	for _, conditionType := range strings.Split(value, ",") {
+		conditionType = strings.TrimSpace(conditionType)
+		if conditionType != "" {
+			conditionTypes = append(conditionTypes, capi.ConditionType(conditionType))
+		}
+	}
+
+	return conditionTypes
+}
+
+// IsFrozen checks if the specified condition type is frozen with
+// SkipAnnotation on the specified object.
+func IsFrozen(object conditions.Object, conditionType capi.ConditionType) bool {
+	for _, frozen := range FrozenConditions(object) {
+		if strings.EqualFold(string(frozen), string(conditionType)) {
+			return true
+		}
+	}
+
+	return false
+}
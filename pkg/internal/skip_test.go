@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestSkipAnnotation(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		annotations            map[string]string
+		expectedUpgrading      corev1.ConditionStatus
+		initialFrozenCondition bool
+	}{
+		{
+			name:              "case 0: condition is updated when there is no skip annotation",
+			expectedUpgrading: corev1.ConditionTrue,
+		},
+		{
+			name:              "case 1: frozen condition is left untouched",
+			annotations:       map[string]string{SkipAnnotation: "upgrading, ReplicasReady"},
+			expectedUpgrading: corev1.ConditionFalse,
+		},
+		{
+			name:              "case 2: condition not listed in skip annotation is updated",
+			annotations:       map[string]string{SkipAnnotation: "ReplicasReady"},
+			expectedUpgrading: corev1.ConditionTrue,
+		},
+		{
+			name:                   "case 3: conditions of other types are not changed",
+			expectedUpgrading:      corev1.ConditionTrue,
+			initialFrozenCondition: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			logger, err := micrologger.New(micrologger.Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			h, err := NewHandler(HandlerConfig{
+				CtrlClient:    NewFakeClient(capi.AddToScheme),
+				Logger:        logger,
+				ConditionType: conditions.Upgrading,
+				EnsureCreatedFunc: func(_ context.Context, object conditions.Object) error {
+					capiconditions.MarkTrue(object, conditions.Upgrading)
+					return nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "org-test",
+					Name:        "test1",
+					Annotations: tc.annotations,
+				},
+			}
+			capiconditions.MarkFalse(cluster, conditions.Upgrading, conditions.UpgradeCompletedReason, capi.ConditionSeverityInfo, "")
+			if tc.initialFrozenCondition {
+				capiconditions.MarkTrue(cluster, "Frozen")
+			}
+
+			// act
+			err = h.EnsureCreated(context.Background(), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			upgrading := capiconditions.Get(cluster, conditions.Upgrading)
+			if upgrading.Status != tc.expectedUpgrading {
+				t.Errorf("expected Upgrading status %s, got %s", tc.expectedUpgrading, SprintComparedCondition(upgrading))
+			}
+			if capiconditions.Has(cluster, "Frozen") != tc.initialFrozenCondition {
+				t.Errorf("expected Frozen condition set to be %t, got %t", tc.initialFrozenCondition, capiconditions.Has(cluster, "Frozen"))
+			}
+		})
+	}
+}
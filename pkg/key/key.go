@@ -14,6 +14,10 @@ import (
 
 const (
 	releaseVersion = "release.giantswarm.io/version"
+
+	// SkipAnnotation is a comma-separated list of condition types that
+	// handlers leave untouched on the annotated object.
+	SkipAnnotation = internal.SkipAnnotation
 )
 
 func ToClusterPointer(v interface{}) (*capi.Cluster, error) {
@@ -70,3 +74,9 @@ func IsFirstNodePoolUpgradeInProgress(object conditions.Object) bool {
 	upgradingToNodePools, isUpgradingToNodePoolsSet := cluster.GetAnnotations()[internal.UpgradingToNodePools]
 	return isUpgradingToNodePoolsSet && strings.ToLower(upgradingToNodePools) == "true"
 }
+
+// FrozenConditions returns condition types that are frozen on the specified
+// object with SkipAnnotation.
+func FrozenConditions(object conditions.Object) []capi.ConditionType {
+	return internal.FrozenConditions(object)
+}
@@ -0,0 +1,606 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/controlplaneready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/creating"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/custom"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/degraded"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/frozen"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/infrastructureready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsupgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/replicasready"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/summary"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/upgrading"
+	"github.com/giantswarm/conditions-handler/pkg/damping"
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+)
+
+const (
+	TypeControlPlaneReady   = "controlPlaneReady"
+	TypeCreating            = "creating"
+	TypeCustom              = "custom"
+	TypeDegraded            = "degraded"
+	TypeFrozen              = "frozen"
+	TypeInfrastructureReady = "infrastructureReady"
+	TypeNodePoolsReady      = "nodePoolsReady"
+	TypeNodePoolsUpgrading  = "nodePoolsUpgrading"
+	TypePaused              = "paused"
+	TypeReplicasReady       = "replicasReady"
+	TypeScaling             = "scaling"
+	TypeSummary             = "summary"
+	TypeUpgrading           = "upgrading"
+)
+
+// GVK is a serializable schema.GroupVersionKind.
+type GVK struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// DampingSettings are serializable damping.Config settings.
+type DampingSettings struct {
+	FalseAfter           metav1.Duration `json:"falseAfter,omitempty"`
+	FalseAfterReconciles int             `json:"falseAfterReconciles,omitempty"`
+	DampRecovery         bool            `json:"dampRecovery,omitempty"`
+}
+
+// WatchSettings are settings of infrastructureReady and controlPlaneReady
+// handlers.
+type WatchSettings struct {
+	// Watches are kinds of the referenced objects used for setting up
+	// watches.
+	Watches []GVK `json:"watches,omitempty"`
+}
+
+// UpgradingSettings are settings of upgrading handler.
+type UpgradingSettings struct {
+	CheckNodePools bool `json:"checkNodePools,omitempty"`
+}
+
+// NodePoolsReadySettings are settings of nodePoolsReady handler, see
+// nodepoolsready.HandlerConfig.
+type NodePoolsReadySettings struct {
+	// Discovery is one of label (default), clusterName, ownerReference or
+	// combined.
+	Discovery             string                           `json:"discovery,omitempty"`
+	NodePoolConditionType capi.ConditionType               `json:"nodePoolConditionType,omitempty"`
+	DisableStepCounter    bool                             `json:"disableStepCounter,omitempty"`
+	MaxMessages           int                              `json:"maxMessages,omitempty"`
+	NoNodePools           nodepoolsready.NoNodePoolsPolicy `json:"noNodePools,omitempty"`
+	SkipDeleting          bool                             `json:"skipDeleting,omitempty"`
+	SkipPaused            bool                             `json:"skipPaused,omitempty"`
+	Damping               DampingSettings                  `json:"damping,omitempty"`
+}
+
+// ReplicasReadySettings are settings of replicasReady handler, see
+// replicasready.HandlerConfig.
+type ReplicasReadySettings struct {
+	MinReadyPercentage int                              `json:"minReadyPercentage,omitempty"`
+	MinReadyReplicas   int32                            `json:"minReadyReplicas,omitempty"`
+	AutoscalerAware    bool                             `json:"autoscalerAware,omitempty"`
+	ZeroReplicas       replicasready.ZeroReplicasPolicy `json:"zeroReplicas,omitempty"`
+	ScalingGracePeriod metav1.Duration                  `json:"scalingGracePeriod,omitempty"`
+	Damping            DampingSettings                  `json:"damping,omitempty"`
+}
+
+// SummarySettings are settings of summary handler.
+type SummarySettings struct {
+	// ConditionType is the summary condition. Defaults to Ready.
+	ConditionType capi.ConditionType `json:"conditionType,omitempty"`
+	// Conditions are summarized conditions.
+	Conditions []capi.ConditionType `json:"conditions"`
+	// Ignore rules match summarized conditions that are ignored.
+	Ignore []IgnoreRule `json:"ignore,omitempty"`
+	// Strategy is either empty (default) or Priority.
+	Strategy summary.Strategy `json:"strategy,omitempty"`
+	// Inputs customize how summarized conditions are taken into account.
+	Inputs map[capi.ConditionType]InputSettings `json:"inputs,omitempty"`
+	// MessageTemplate is a text/template for the summary message.
+	MessageTemplate string `json:"messageTemplate,omitempty"`
+}
+
+// InputSettings are serializable summary.InputOptions.
+type InputSettings struct {
+	Optional    bool                   `json:"optional,omitempty"`
+	MaxSeverity capi.ConditionSeverity `json:"maxSeverity,omitempty"`
+}
+
+// LookupSettings are serializable custom.Lookup settings.
+type LookupSettings struct {
+	Name       string `json:"name"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	ObjectName string `json:"objectName"`
+}
+
+// CustomSettings are settings of custom handler, whose condition is computed
+// by CEL expressions, see custom.HandlerConfig.
+type CustomSettings struct {
+	ConditionType    capi.ConditionType `json:"conditionType"`
+	NegativePolarity bool               `json:"negativePolarity,omitempty"`
+	Lookups          []LookupSettings   `json:"lookups,omitempty"`
+	Status           string             `json:"status"`
+	Reason           string             `json:"reason,omitempty"`
+	Severity         string             `json:"severity,omitempty"`
+	Message          string             `json:"message,omitempty"`
+}
+
+// DegradedSettings are settings of degraded handler, see
+// degraded.HandlerConfig.
+type DegradedSettings struct {
+	Inputs           []capi.ConditionType                   `json:"inputs,omitempty"`
+	LifecycleWindows map[capi.ConditionType]metav1.Duration `json:"lifecycleWindows,omitempty"`
+}
+
+func builtinRegistrations() map[string]Registration {
+	return map[string]Registration{
+		TypeControlPlaneReady:   {Build: buildControlPlaneReady, Kinds: []string{KindCluster}},
+		TypeCreating:            {Build: buildCreating},
+		TypeCustom:              {Build: buildCustom},
+		TypeDegraded:            {Build: buildDegraded},
+		TypeFrozen:              {Build: buildFrozen},
+		TypeInfrastructureReady: {Build: buildInfrastructureReady},
+		TypeNodePoolsReady:      {Build: buildNodePoolsReady, Kinds: []string{KindCluster}},
+		TypeNodePoolsUpgrading:  {Build: buildNodePoolsUpgrading, Kinds: []string{KindCluster}},
+		TypePaused:              {Build: buildPaused},
+		TypeReplicasReady:       {Build: buildReplicasReady, Kinds: []string{KindMachinePool}},
+		TypeScaling:             {Build: buildScaling, Kinds: []string{KindMachinePool}},
+		TypeSummary:             {Build: buildSummary},
+		TypeUpgrading:           {Build: buildUpgrading},
+	}
+}
+
+func buildControlPlaneReady(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	var settings WatchSettings
+	err := DecodeSettings(spec, &settings)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := controlplaneready.NewHandler(controlplaneready.HandlerConfig{
+		CtrlClient:       config.CtrlClient,
+		Logger:           config.Logger,
+		Name:             config.Name,
+		UpdateStatus:     spec.UpdateStatus,
+		History:          spec.History.config(),
+		PostCheck:        config.PostCheck,
+		Now:              config.Now,
+		ControlPlaneGVKs: toGVKs(settings.Watches),
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildCreating(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	err := DecodeSettings(spec, &struct{}{})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := creating.NewHandler(creating.HandlerConfig{
+		CtrlClient:   config.CtrlClient,
+		Logger:       config.Logger,
+		Name:         config.Name,
+		UpdateStatus: spec.UpdateStatus,
+		History:      spec.History.config(),
+		PostCheck:    config.PostCheck,
+		Now:          config.Now,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildCustom(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	var settings CustomSettings
+	err := DecodeSettings(spec, &settings)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var lookups []custom.Lookup
+	for _, l := range settings.Lookups {
+		lookups = append(lookups, custom.Lookup{
+			Name:       l.Name,
+			APIVersion: l.APIVersion,
+			Kind:       l.Kind,
+			ObjectName: l.ObjectName,
+		})
+	}
+
+	h, err := custom.NewHandler(custom.HandlerConfig{
+		CtrlClient:       config.CtrlClient,
+		Logger:           config.Logger,
+		Name:             config.Name,
+		UpdateStatus:     spec.UpdateStatus,
+		History:          spec.History.config(),
+		PostCheck:        config.PostCheck,
+		Now:              config.Now,
+		ConditionType:    settings.ConditionType,
+		NegativePolarity: settings.NegativePolarity,
+		Lookups:          lookups,
+		Expressions: custom.Expressions{
+			Status:   settings.Status,
+			Reason:   settings.Reason,
+			Severity: settings.Severity,
+			Message:  settings.Message,
+		},
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildDegraded(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	var settings DegradedSettings
+	err := DecodeSettings(spec, &settings)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var lifecycleWindows map[capi.ConditionType]time.Duration
+	if len(settings.LifecycleWindows) > 0 {
+		lifecycleWindows = map[capi.ConditionType]time.Duration{}
+		for conditionType, window := range settings.LifecycleWindows {
+			lifecycleWindows[conditionType] = window.Duration
+		}
+	}
+
+	h, err := degraded.NewHandler(degraded.HandlerConfig{
+		CtrlClient:       config.CtrlClient,
+		Logger:           config.Logger,
+		Name:             config.Name,
+		UpdateStatus:     spec.UpdateStatus,
+		History:          spec.History.config(),
+		PostCheck:        config.PostCheck,
+		Now:              config.Now,
+		Inputs:           settings.Inputs,
+		LifecycleWindows: lifecycleWindows,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildFrozen(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	err := DecodeSettings(spec, &struct{}{})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := frozen.NewHandler(frozen.HandlerConfig{
+		CtrlClient:   config.CtrlClient,
+		Logger:       config.Logger,
+		Name:         config.Name,
+		UpdateStatus: spec.UpdateStatus,
+		History:      spec.History.config(),
+		PostCheck:    config.PostCheck,
+		Now:          config.Now,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildInfrastructureReady(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	var settings WatchSettings
+	err := DecodeSettings(spec, &settings)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := infrastructureready.NewHandler(infrastructureready.HandlerConfig{
+		CtrlClient:         config.CtrlClient,
+		Logger:             config.Logger,
+		Name:               config.Name,
+		UpdateStatus:       spec.UpdateStatus,
+		History:            spec.History.config(),
+		PostCheck:          config.PostCheck,
+		Now:                config.Now,
+		InfrastructureGVKs: toGVKs(settings.Watches),
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildNodePoolsReady(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	var settings NodePoolsReadySettings
+	err := DecodeSettings(spec, &settings)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	discovery, err := newDiscovery(config, settings.Discovery)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := nodepoolsready.NewHandler(nodepoolsready.HandlerConfig{
+		CtrlClient:            config.CtrlClient,
+		Logger:                config.Logger,
+		Name:                  config.Name,
+		UpdateStatus:          spec.UpdateStatus,
+		History:               spec.History.config(),
+		PostCheck:             config.PostCheck,
+		Now:                   config.Now,
+		Discovery:             discovery,
+		NodePoolConditionType: settings.NodePoolConditionType,
+		DisableStepCounter:    settings.DisableStepCounter,
+		MaxMessages:           settings.MaxMessages,
+		NoNodePools:           settings.NoNodePools,
+		SkipDeleting:          settings.SkipDeleting,
+		SkipPaused:            settings.SkipPaused,
+		Damping:               settings.Damping.config(),
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildNodePoolsUpgrading(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	err := DecodeSettings(spec, &struct{}{})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := nodepoolsupgrading.NewHandler(nodepoolsupgrading.HandlerConfig{
+		CtrlClient:   config.CtrlClient,
+		Logger:       config.Logger,
+		Name:         config.Name,
+		UpdateStatus: spec.UpdateStatus,
+		History:      spec.History.config(),
+		PostCheck:    config.PostCheck,
+		Now:          config.Now,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildPaused(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	err := DecodeSettings(spec, &struct{}{})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := paused.NewHandler(paused.HandlerConfig{
+		CtrlClient:   config.CtrlClient,
+		Logger:       config.Logger,
+		Name:         config.Name,
+		UpdateStatus: spec.UpdateStatus,
+		History:      spec.History.config(),
+		PostCheck:    config.PostCheck,
+		Now:          config.Now,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildReplicasReady(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	var settings ReplicasReadySettings
+	err := DecodeSettings(spec, &settings)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := replicasready.NewHandler(replicasready.HandlerConfig{
+		CtrlClient:   config.CtrlClient,
+		Logger:       config.Logger,
+		Name:         config.Name,
+		UpdateStatus: spec.UpdateStatus,
+		History:      spec.History.config(),
+		PostCheck:    config.PostCheck,
+		Now:          config.Now,
+		Policy: replicasready.Policy{
+			MinReadyPercentage: settings.MinReadyPercentage,
+			MinReadyReplicas:   settings.MinReadyReplicas,
+			AutoscalerAware:    settings.AutoscalerAware,
+			ZeroReplicas:       settings.ZeroReplicas,
+		},
+		Damping:            settings.Damping.config(),
+		ScalingGracePeriod: settings.ScalingGracePeriod.Duration,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildScaling(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	err := DecodeSettings(spec, &struct{}{})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := scaling.NewHandler(scaling.HandlerConfig{
+		CtrlClient:   config.CtrlClient,
+		Logger:       config.Logger,
+		Name:         config.Name,
+		UpdateStatus: spec.UpdateStatus,
+		History:      spec.History.config(),
+		PostCheck:    config.PostCheck,
+		Now:          config.Now,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildSummary(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	var settings SummarySettings
+	err := DecodeSettings(spec, &settings)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if len(settings.Conditions) == 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Conditions must not be empty", settings)
+	}
+
+	var ignoreOptions []conditions.CheckOption
+	for _, rule := range settings.Ignore {
+		if rule.ConditionType == "" {
+			return nil, microerror.Maskf(errors.InvalidConfigError, "%T.ConditionType must not be empty", rule)
+		}
+		ignoreOptions = append(ignoreOptions, rule.CheckOption())
+	}
+
+	var inputOptions map[capi.ConditionType]summary.InputOptions
+	if len(settings.Inputs) > 0 {
+		inputOptions = map[capi.ConditionType]summary.InputOptions{}
+		for conditionType, input := range settings.Inputs {
+			inputOptions[conditionType] = summary.InputOptions{
+				Optional:    input.Optional,
+				MaxSeverity: input.MaxSeverity,
+			}
+		}
+	}
+
+	h, err := summary.NewHandler(summary.HandlerConfig{
+		CtrlClient:            config.CtrlClient,
+		Logger:                config.Logger,
+		Name:                  config.Name,
+		UpdateStatus:          spec.UpdateStatus,
+		History:               spec.History.config(),
+		PostCheck:             config.PostCheck,
+		Now:                   config.Now,
+		SummaryConditionType:  settings.ConditionType,
+		ConditionsToSummarize: settings.Conditions,
+		IgnoreOptions:         ignoreOptions,
+		Strategy:              settings.Strategy,
+		InputOptions:          inputOptions,
+		MessageTemplate:       settings.MessageTemplate,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+func buildUpgrading(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+	var settings UpgradingSettings
+	err := DecodeSettings(spec, &settings)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	h, err := upgrading.NewHandler(upgrading.HandlerConfig{
+		CtrlClient:     config.CtrlClient,
+		Logger:         config.Logger,
+		Name:           config.Name,
+		UpdateStatus:   spec.UpdateStatus,
+		History:        spec.History.config(),
+		PostCheck:      config.PostCheck,
+		Now:            config.Now,
+		CheckNodePools: settings.CheckNodePools,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
+
+// DecodeSettings decodes handler settings into the specified value. Unknown
+// settings are reported as invalid config.
+func DecodeSettings(spec HandlerSpec, settings interface{}) error {
+	if len(spec.Settings) == 0 || string(spec.Settings) == "null" {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(spec.Settings))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(settings)
+	if err != nil {
+		return microerror.Maskf(errors.InvalidConfigError, "invalid settings of %s handler %q: %s", spec.Type, spec.Name, err)
+	}
+
+	return nil
+}
+
+func newDiscovery(config handler.Config, discovery string) (nodepoolsready.Discovery, error) {
+	c := nodepoolsready.DiscoveryConfig{
+		CtrlClient: config.CtrlClient,
+	}
+
+	switch discovery {
+	case "", "label":
+		return nodepoolsready.NewLabelDiscovery(c)
+	case "clusterName":
+		return nodepoolsready.NewClusterNameDiscovery(c)
+	case "ownerReference":
+		return nodepoolsready.NewOwnerReferenceDiscovery(c)
+	case "combined":
+		label, err := nodepoolsready.NewLabelDiscovery(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		clusterName, err := nodepoolsready.NewClusterNameDiscovery(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		ownerReference, err := nodepoolsready.NewOwnerReferenceDiscovery(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		return nodepoolsready.NewCombinedDiscovery(label, clusterName, ownerReference)
+	default:
+		return nil, microerror.Maskf(errors.InvalidConfigError, "unknown node pools discovery %q, expected one of label, clusterName, ownerReference or combined", discovery)
+	}
+}
+
+func (s DampingSettings) config() damping.Config {
+	return damping.Config{
+		FalseAfter:           s.FalseAfter.Duration,
+		FalseAfterReconciles: s.FalseAfterReconciles,
+		DampRecovery:         s.DampRecovery,
+	}
+}
+
+func toGVKs(gvks []GVK) []schema.GroupVersionKind {
+	var result []schema.GroupVersionKind
+	for _, gvk := range gvks {
+		result = append(result, schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind})
+	}
+
+	return result
+}
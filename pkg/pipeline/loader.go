@@ -0,0 +1,152 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/conditions-handler/pkg/conditions/composite"
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+)
+
+type LoaderConfig struct {
+	CtrlClient ctrl.Client
+	Logger     micrologger.Logger
+
+	// Registry contains handler types that can be used in pipelines.
+	// Defaults to NewDefaultRegistry.
+	Registry *Registry
+	// PostCheck is passed to all built handlers, see handler.Config.
+	PostCheck handler.PostCheckFunc
+	// Now is passed to all built handlers, see handler.Config.
+	Now func() time.Time
+}
+
+// Loader builds composite handlers from pipeline descriptions.
+type Loader struct {
+	ctrlClient ctrl.Client
+	logger     micrologger.Logger
+	registry   *Registry
+	postCheck  handler.PostCheckFunc
+	now        func() time.Time
+}
+
+func NewLoader(config LoaderConfig) (*Loader, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	registry := config.Registry
+	if registry == nil {
+		registry = NewDefaultRegistry()
+	}
+
+	l := &Loader{
+		ctrlClient: config.CtrlClient,
+		logger:     config.Logger,
+		registry:   registry,
+		postCheck:  config.PostCheck,
+		now:        config.Now,
+	}
+
+	return l, nil
+}
+
+// Parse parses a YAML or JSON pipeline description. Unknown fields are
+// reported as invalid config.
+func Parse(data []byte) (Pipeline, error) {
+	var pipeline Pipeline
+	err := yaml.UnmarshalStrict(data, &pipeline)
+	if err != nil {
+		return Pipeline{}, microerror.Maskf(errors.InvalidConfigError, "invalid pipeline: %s", err)
+	}
+
+	return pipeline, nil
+}
+
+// Load builds a composite handler from a YAML or JSON pipeline description.
+func (l *Loader) Load(data []byte) (*composite.Handler, error) {
+	pipeline, err := Parse(data)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return l.Build(pipeline)
+}
+
+// LoadFile builds a composite handler from a YAML or JSON pipeline
+// description in the specified file.
+func (l *Loader) LoadFile(path string) (*composite.Handler, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return l.Load(data)
+}
+
+// Build builds a composite handler from the specified pipeline.
+func (l *Loader) Build(pipeline Pipeline) (*composite.Handler, error) {
+	if pipeline.Name == "" {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Name must not be empty", pipeline)
+	}
+	if pipeline.Kind != KindCluster && pipeline.Kind != KindMachinePool {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Kind must be %s or %s, got %q", pipeline, KindCluster, KindMachinePool, pipeline.Kind)
+	}
+	if len(pipeline.Handlers) == 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Handlers must not be empty", pipeline)
+	}
+
+	var handlers []handler.Interface
+	names := map[string]bool{}
+	for _, spec := range pipeline.Handlers {
+		if spec.Name == "" {
+			spec.Name = fmt.Sprintf("%s/%s", pipeline.Name, spec.Type)
+		}
+		if names[spec.Name] {
+			return nil, microerror.Maskf(errors.InvalidConfigError, "handler name %q is not unique in pipeline %q", spec.Name, pipeline.Name)
+		}
+		names[spec.Name] = true
+
+		registration, err := l.registry.get(spec.Type, pipeline.Kind)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		config := handler.Config{
+			CtrlClient: l.ctrlClient,
+			Logger:     l.logger,
+			Name:       spec.Name,
+			PostCheck:  l.postCheck,
+			Now:        l.now,
+		}
+		h, err := registration.Build(config, spec)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		handlers = append(handlers, h)
+	}
+
+	h, err := composite.NewHandler(composite.HandlerConfig{
+		CtrlClient:   l.ctrlClient,
+		Logger:       l.logger,
+		Name:         pipeline.Name,
+		Handlers:     handlers,
+		DisableCache: pipeline.DisableCache,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return h, nil
+}
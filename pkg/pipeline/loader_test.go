@@ -0,0 +1,236 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestLoadFile(t *testing.T) {
+	testName := "composite handler is built from pipeline file"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		loader := newTestLoader(t)
+
+		// act
+		h, err := loader.LoadFile("testdata/cluster.yaml")
+
+		// assert
+		if err != nil {
+			t.Fatalf("expected no error, got %#q", err)
+		}
+		if h.Name() != "clusterConditionsHandler" {
+			t.Errorf("expected composite handler name clusterConditionsHandler, got %s", h.Name())
+		}
+		expectedNames := []string{
+			"clusterConditionsHandler/paused",
+			"clusterConditionsHandler/frozen",
+			"clusterConditionsHandler/infrastructureReady",
+			"clusterConditionsHandler/controlPlaneReady",
+			"clusterConditionsHandler/nodePoolsReady",
+			"clusterConditionsHandler/nodePoolsUpgrading",
+			"kubeconfigSecretPresentHandler",
+			"clusterReadyHandler",
+			"clusterConditionsHandler/degraded",
+			"clusterConditionsHandler/creating",
+			"clusterConditionsHandler/upgrading",
+		}
+		var names []string
+		for _, child := range h.Handlers() {
+			names = append(names, child.Name())
+		}
+		if strings.Join(names, ",") != strings.Join(expectedNames, ",") {
+			t.Errorf("expected handlers %v, got %v", expectedNames, names)
+		}
+	})
+}
+
+func TestLoadInvalidPipeline(t *testing.T) {
+	testCases := []struct {
+		name          string
+		pipeline      string
+		expectedError string
+	}{
+		{
+			name:          "case 0: unknown handler type",
+			pipeline:      "{name: test, kind: Cluster, handlers: [{type: unknown}]}",
+			expectedError: `unknown handler type "unknown"`,
+		},
+		{
+			name:          "case 1: handler type that does not support pipeline kind",
+			pipeline:      "{name: test, kind: MachinePool, handlers: [{type: nodePoolsReady}]}",
+			expectedError: `handler type "nodePoolsReady" does not support kind "MachinePool"`,
+		},
+		{
+			name:          "case 2: unknown handler setting",
+			pipeline:      "{name: test, kind: Cluster, handlers: [{type: upgrading, settings: {checkNodePool: true}}]}",
+			expectedError: `unknown field "checkNodePool"`,
+		},
+		{
+			name:          "case 3: unknown pipeline field",
+			pipeline:      "{name: test, kind: Cluster, handler: []}",
+			expectedError: `unknown field "handler"`,
+		},
+		{
+			name:          "case 4: summary without summarized conditions",
+			pipeline:      "{name: test, kind: Cluster, handlers: [{type: summary}]}",
+			expectedError: "Conditions must not be empty",
+		},
+		{
+			name:          "case 5: duplicated handler names",
+			pipeline:      "{name: test, kind: Cluster, handlers: [{type: creating}, {type: creating}]}",
+			expectedError: `handler name "test/creating" is not unique`,
+		},
+		{
+			name:          "case 6: invalid custom handler expression",
+			pipeline:      "{name: test, kind: Cluster, handlers: [{type: custom, settings: {conditionType: Test, status: 'object.metadata.name =='}}]}",
+			expectedError: "invalid status expression",
+		},
+		{
+			name:          "case 7: unknown kind",
+			pipeline:      "{name: test, kind: Machine, handlers: [{type: creating}]}",
+			expectedError: "Kind must be Cluster or MachinePool",
+		},
+		{
+			name:          "case 8: unknown history storage",
+			pipeline:      "{name: test, kind: Cluster, handlers: [{type: creating, history: {maxEntries: 10, storage: Secret}}]}",
+			expectedError: "Storage must be Annotation or ConfigMap",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			loader := newTestLoader(t)
+
+			// act
+			_, err := loader.Load([]byte(tc.pipeline))
+
+			// assert
+			if !errors.IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error, got %#v", err)
+			}
+			if !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("expected error containing %q, got %q", tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	testName := "handler type can be registered and used in pipelines"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		registry := NewDefaultRegistry()
+		err := registry.Register("myHandler", Registration{
+			Build: func(config handler.Config, spec HandlerSpec) (handler.Interface, error) {
+				return buildCreating(config, spec)
+			},
+			Kinds: []string{KindMachinePool},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		loader, err := NewLoader(LoaderConfig{
+			CtrlClient: internal.NewFakeClient(capi.AddToScheme, capiexp.AddToScheme),
+			Logger:     newTestLogger(t),
+			Registry:   registry,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		h, err := loader.Load([]byte("{name: test, kind: MachinePool, handlers: [{type: myHandler, name: customHandler}]}"))
+
+		// assert
+		if err != nil {
+			t.Fatalf("expected no error, got %#q", err)
+		}
+		if len(h.Handlers()) != 1 || h.Handlers()[0].Name() != "customHandler" {
+			t.Errorf("expected single customHandler, got %v", h.Handlers())
+		}
+		err = registry.Register("myHandler", Registration{Build: buildCreating})
+		if !errors.IsInvalidConfig(err) {
+			t.Errorf("expected invalid config error when registering type twice, got %#v", err)
+		}
+	})
+}
+
+func TestLoaderPostCheck(t *testing.T) {
+	testName := "post-check is passed to all handlers built by the loader"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		ctx := context.Background()
+		client := internal.NewFakeClient(capi.AddToScheme, capiexp.AddToScheme)
+		cluster := &capi.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-test", Name: "test1"}}
+		err := client.Create(ctx, cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var checked []capi.ConditionType
+		loader, err := NewLoader(LoaderConfig{
+			CtrlClient: client,
+			Logger:     newTestLogger(t),
+			PostCheck: func(_ conditions.Object, conditionType capi.ConditionType) error {
+				checked = append(checked, conditionType)
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := loader.Load([]byte("{name: test, kind: Cluster, handlers: [{type: paused}, {type: creating}]}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err = h.EnsureCreated(ctx, cluster)
+
+		// assert
+		if err != nil {
+			t.Fatalf("expected no error, got %#q", err)
+		}
+		expected := []capi.ConditionType{"Paused", conditions.Creating}
+		if fmt.Sprint(checked) != fmt.Sprint(expected) {
+			t.Errorf("expected checked conditions %v, got %v", expected, checked)
+		}
+	})
+}
+
+func newTestLoader(t *testing.T) *Loader {
+	loader, err := NewLoader(LoaderConfig{
+		CtrlClient: internal.NewFakeClient(capi.AddToScheme, capiexp.AddToScheme),
+		Logger:     newTestLogger(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loader
+}
+
+func newTestLogger(t *testing.T) micrologger.Logger {
+	logger, err := micrologger.New(micrologger.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return logger
+}
@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"sort"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+)
+
+// BuildFunc creates a handler from its spec. The spec name is already
+// defaulted, and the specified config contains the handler name.
+type BuildFunc func(config handler.Config, spec HandlerSpec) (handler.Interface, error)
+
+// Registration describes a handler type.
+type Registration struct {
+	// Build creates handlers of the registered type.
+	Build BuildFunc
+	// Kinds are object kinds that handlers of the registered type can
+	// reconcile. Empty list means all kinds.
+	Kinds []string
+}
+
+// Registry contains handler types that can be used in pipelines.
+type Registry struct {
+	registrations map[string]Registration
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		registrations: map[string]Registration{},
+	}
+}
+
+// NewDefaultRegistry creates a registry with all handler types implemented
+// in this library.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for handlerType, registration := range builtinRegistrations() {
+		r.registrations[handlerType] = registration
+	}
+
+	return r
+}
+
+// Register adds a handler type to the registry, so that custom handlers
+// can be used in pipelines.
+func (r *Registry) Register(handlerType string, registration Registration) error {
+	if handlerType == "" {
+		return microerror.Maskf(errors.InvalidConfigError, "handler type must not be empty")
+	}
+	if registration.Build == nil {
+		return microerror.Maskf(errors.InvalidConfigError, "%T.Build must not be empty", registration)
+	}
+	if _, ok := r.registrations[handlerType]; ok {
+		return microerror.Maskf(errors.InvalidConfigError, "handler type %q is already registered", handlerType)
+	}
+
+	r.registrations[handlerType] = registration
+	return nil
+}
+
+// Types returns sorted registered handler types.
+func (r *Registry) Types() []string {
+	var types []string
+	for handlerType := range r.registrations {
+		types = append(types, handlerType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+func (r *Registry) get(handlerType, kind string) (Registration, error) {
+	registration, ok := r.registrations[handlerType]
+	if !ok {
+		return Registration{}, microerror.Maskf(errors.InvalidConfigError, "unknown handler type %q, expected one of %v", handlerType, r.Types())
+	}
+
+	if len(registration.Kinds) == 0 {
+		return registration, nil
+	}
+	for _, k := range registration.Kinds {
+		if k == kind {
+			return registration, nil
+		}
+	}
+
+	return Registration{}, microerror.Maskf(errors.InvalidConfigError, "handler type %q does not support kind %q, expected one of %v", handlerType, kind, registration.Kinds)
+}
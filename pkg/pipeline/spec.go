@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"encoding/json"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/history"
+)
+
+const (
+	KindCluster     = "Cluster"
+	KindMachinePool = "MachinePool"
+)
+
+// Pipeline describes a composite handler, i.e. which condition handlers
+// reconcile objects of the specified kind, in which order and with which
+// settings.
+type Pipeline struct {
+	// Name is the composite handler name.
+	Name string `json:"name"`
+	// Kind is the reconciled object kind, Cluster or MachinePool.
+	Kind string `json:"kind"`
+	// DisableCache disables request-scoped cache of the composite handler.
+	DisableCache bool `json:"disableCache,omitempty"`
+	// Handlers are executed in the specified order.
+	Handlers []HandlerSpec `json:"handlers"`
+}
+
+// HandlerSpec describes a single condition handler in a pipeline.
+type HandlerSpec struct {
+	// Type is the handler type under which the handler is registered in the
+	// registry, e.g. infrastructureReady or summary.
+	Type string `json:"type"`
+	// Name is the handler name. Defaults to the pipeline name followed by the
+	// handler type.
+	Name string `json:"name,omitempty"`
+	// UpdateStatus enables updating object status after the handler is done.
+	UpdateStatus bool `json:"updateStatus,omitempty"`
+	// History enables recording of condition transitions, see
+	// history.Config.
+	History HistorySettings `json:"history,omitempty"`
+	// Settings are handler type specific settings.
+	Settings json.RawMessage `json:"settings,omitempty"`
+}
+
+// HistorySettings are serializable history.Config settings.
+type HistorySettings struct {
+	MaxEntries int             `json:"maxEntries,omitempty"`
+	MaxAge     metav1.Duration `json:"maxAge,omitempty"`
+	// Storage is Annotation (default) or ConfigMap.
+	Storage string `json:"storage,omitempty"`
+}
+
+func (s HistorySettings) config() history.Config {
+	return history.Config{
+		MaxEntries: s.MaxEntries,
+		MaxAge:     s.MaxAge.Duration,
+		Storage:    history.Storage(s.Storage),
+	}
+}
+
+// IgnoreRule matches conditions that are ignored, e.g. by the summary
+// handler. Empty fields match any value.
+type IgnoreRule struct {
+	ConditionType capi.ConditionType     `json:"conditionType"`
+	Status        string                 `json:"status,omitempty"`
+	Reason        string                 `json:"reason,omitempty"`
+	Severity      capi.ConditionSeverity `json:"severity,omitempty"`
+}
+
+// CheckOption returns a check option that matches conditions described by
+// the rule.
+func (r IgnoreRule) CheckOption() conditions.CheckOption {
+	return func(condition *capi.Condition) bool {
+		return condition != nil &&
+			condition.Type == r.ConditionType &&
+			(r.Status == "" || string(condition.Status) == r.Status) &&
+			(r.Reason == "" || condition.Reason == r.Reason) &&
+			(r.Severity == "" || condition.Severity == r.Severity)
+	}
+}
@@ -0,0 +1,231 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiannotations "sigs.k8s.io/cluster-api/util/annotations"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+	"github.com/giantswarm/conditions-handler/pkg/watch"
+)
+
+const (
+	// DefaultRequeueAfter is the default delay after which an object is
+	// reconciled again when an external object it refers to is not found.
+	DefaultRequeueAfter = 30 * time.Second
+)
+
+type Config struct {
+	CtrlClient ctrl.Client
+	Logger     micrologger.Logger
+
+	// Handler is the handler, usually a composite handler, that ensures
+	// conditions of the reconciled objects.
+	Handler handler.Interface
+	// Object is the reconciled object kind, e.g. &capi.Cluster{}. It is
+	// copied for every reconciliation.
+	Object ctrl.Object
+
+	// SkipStatusUpdate disables persisting object status after the handler
+	// is done. It can be set when the handler already updates the status.
+	SkipStatusUpdate bool
+	// RequeueAfter is the delay after which an object is reconciled again
+	// when an external object it refers to is not found. Defaults to
+	// DefaultRequeueAfter.
+	RequeueAfter time.Duration
+	// ResyncPeriod, when set, is the delay after which an object is
+	// reconciled again after a successful reconciliation.
+	ResyncPeriod time.Duration
+}
+
+// Reconciler is a controller-runtime reconcile.Reconciler that runs the
+// configured handler for every reconciled object.
+type Reconciler struct {
+	ctrlClient ctrl.Client
+	logger     micrologger.Logger
+
+	handler          handler.Interface
+	object           ctrl.Object
+	skipStatusUpdate bool
+	requeueAfter     time.Duration
+	resyncPeriod     time.Duration
+}
+
+func NewReconciler(config Config) (*Reconciler, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Handler == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Handler must not be empty", config)
+	}
+	if config.Object == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Object must not be empty", config)
+	}
+	if config.RequeueAfter < 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.RequeueAfter must not be negative", config)
+	}
+	if config.ResyncPeriod < 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.ResyncPeriod must not be negative", config)
+	}
+
+	requeueAfter := config.RequeueAfter
+	if requeueAfter == 0 {
+		requeueAfter = DefaultRequeueAfter
+	}
+
+	r := &Reconciler{
+		ctrlClient:       config.CtrlClient,
+		logger:           config.Logger,
+		handler:          config.Handler,
+		object:           config.Object,
+		skipStatusUpdate: config.SkipStatusUpdate,
+		requeueAfter:     requeueAfter,
+		resyncPeriod:     config.ResyncPeriod,
+	}
+
+	return r, nil
+}
+
+// Reconcile fetches the requested object, runs the handler's EnsureCreated
+// or, for objects being deleted, EnsureDeleted, and persists the object
+// status when its conditions are changed. Paused objects are reconciled as
+// well, so that handlers can report that they are paused, while handlers
+// that mutate conditions skip them. Condition handlers of this module check
+// the pause by themselves, other handlers must check it with
+// handler.IsPaused, since the Reconciler persists their changes of paused
+// objects as well.
+func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	object, ok := r.object.DeepCopyObject().(ctrl.Object)
+	if !ok {
+		return reconcile.Result{}, microerror.Maskf(errors.WrongTypeError, "expected 'client.Object', got '%T'", r.object)
+	}
+
+	err := r.ctrlClient.Get(ctx, request.NamespacedName, object)
+	if apierrors.IsNotFound(err) {
+		r.logger.Debugf(ctx, "object %s not found, skipping reconciliation", request.NamespacedName)
+		return reconcile.Result{}, nil
+	} else if err != nil {
+		return reconcile.Result{}, microerror.Mask(err)
+	}
+
+	paused, err := r.isPaused(ctx, object)
+	if err != nil {
+		return r.handleError(ctx, err)
+	}
+	ctx = handler.NewPausedContext(ctx, paused)
+
+	// Condition transitions of handlers that do not save the status are
+	// recorded in history after the status is saved below.
+	ctx, pending, _ := internal.NewPendingHistoryContext(ctx)
+
+	// Conditions are copied, because handlers change them in place, e.g.
+	// capiconditions.Set overwrites and sorts the existing conditions.
+	initialConditions := getConditions(object).DeepCopy()
+
+	if object.GetDeletionTimestamp().IsZero() {
+		err = r.handler.EnsureCreated(ctx, object)
+	} else {
+		err = r.handler.EnsureDeleted(ctx, object)
+	}
+	if err != nil {
+		return r.handleError(ctx, err)
+	}
+
+	if !r.skipStatusUpdate && !reflect.DeepEqual(initialConditions, getConditions(object)) {
+		err = r.ctrlClient.Status().Update(ctx, object)
+		if err != nil {
+			return r.handleError(ctx, err)
+		}
+		r.logger.Debugf(ctx, "updated object %s status", request.NamespacedName)
+	}
+
+	err = pending.Record(ctx)
+	if err != nil {
+		return r.handleError(ctx, err)
+	}
+
+	return reconcile.Result{RequeueAfter: r.resyncPeriod}, nil
+}
+
+// SetupWithManager registers the reconciler in the specified manager. Besides
+// the reconciled objects, the controller watches objects that the handler
+// reads, see watch.SetupWatches.
+func (r *Reconciler) SetupWithManager(mgr manager.Manager, predicates ...predicate.Predicate) error {
+	gvk, err := apiutil.GVKForObject(r.object, mgr.GetScheme())
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	b := builder.ControllerManagedBy(mgr).
+		Named(r.handler.Name()).
+		For(r.object, builder.WithPredicates(predicates...))
+	b = watch.SetupWatches(b, r.handler, gvk, predicates...)
+
+	err = b.Complete(r)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// handleError maps handler and API errors to reconciliation results.
+// Conflicts are retried immediately and missing external objects are retried
+// after a delay, without reporting an error. All other errors are returned,
+// so they are retried with the controller's backoff.
+func (r *Reconciler) handleError(ctx context.Context, err error) (reconcile.Result, error) {
+	if apierrors.IsConflict(microerror.Cause(err)) {
+		r.logger.Debugf(ctx, "conflict trying to save object in k8s API concurrently, requeueing")
+		return reconcile.Result{Requeue: true}, nil
+	}
+	if errors.IsExternalObjectNotFound(err) {
+		r.logger.Debugf(ctx, "external object not found, requeueing after %s", r.requeueAfter)
+		return reconcile.Result{RequeueAfter: r.requeueAfter}, nil
+	}
+
+	return reconcile.Result{}, microerror.Mask(err)
+}
+
+// isPaused checks if the object is paused with cluster.x-k8s.io/paused
+// annotation, or if its Cluster is paused.
+func (r *Reconciler) isPaused(ctx context.Context, object ctrl.Object) (bool, error) {
+	o, ok := object.(conditions.Object)
+	if !ok {
+		return capiannotations.HasPausedAnnotation(object), nil
+	}
+
+	pauseInfo, err := internal.GetPauseInfo(ctx, r.ctrlClient, o)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return pauseInfo.Paused, nil
+}
+
+func getConditions(object ctrl.Object) capi.Conditions {
+	getter, ok := object.(capiconditions.Getter)
+	if !ok {
+		return nil
+	}
+
+	return getter.GetConditions()
+}
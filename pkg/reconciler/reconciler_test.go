@@ -0,0 +1,226 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+type testHandler struct {
+	err            error
+	createdCalls   int
+	deletedCalls   int
+	conditionToSet capi.ConditionType
+	// skipWhenPaused makes the handler check the pause like handlers that are
+	// not built on this module's condition handlers must do.
+	skipWhenPaused bool
+}
+
+func (h *testHandler) EnsureCreated(ctx context.Context, object interface{}) error {
+	h.createdCalls++
+	if h.err != nil {
+		return h.err
+	}
+	if h.skipWhenPaused && handler.IsPaused(ctx) {
+		return nil
+	}
+	capiconditions.MarkTrue(object.(*capi.Cluster), h.conditionToSet)
+	return nil
+}
+
+func (h *testHandler) EnsureDeleted(_ context.Context, _ interface{}) error {
+	h.deletedCalls++
+	return h.err
+}
+
+func (h *testHandler) Name() string { return "test" }
+
+func TestReconcile(t *testing.T) {
+	now := metav1.Now()
+	testCases := []struct {
+		name                 string
+		cluster              *capi.Cluster
+		handlerErr           error
+		skipWhenPaused       bool
+		expectedCreatedCalls int
+		expectedDeletedCalls int
+		expectedResult       reconcile.Result
+		expectError          bool
+		expectConditionSaved bool
+		expectedReadyStatus  corev1.ConditionStatus
+	}{
+		{
+			name:                 "case 0: EnsureCreated is called and status is persisted",
+			cluster:              newCluster(),
+			expectedCreatedCalls: 1,
+			expectConditionSaved: true,
+			expectedReadyStatus:  corev1.ConditionTrue,
+		},
+		{
+			name: "case 1: EnsureDeleted is called for deleted object",
+			cluster: func() *capi.Cluster {
+				c := newCluster()
+				c.DeletionTimestamp = &now
+				c.Finalizers = []string{"test"}
+				return c
+			}(),
+			expectedDeletedCalls: 1,
+		},
+		{
+			name:                 "case 2: missing external object is requeued after a delay",
+			cluster:              newCluster(),
+			handlerErr:           fmt.Errorf("failed to retrieve AzureCluster external object: %w", apierrors.NewNotFound(schema.GroupResource{Resource: "azureclusters"}, "test1")),
+			expectedCreatedCalls: 1,
+			expectedResult:       reconcile.Result{RequeueAfter: DefaultRequeueAfter},
+		},
+		{
+			name:                 "case 3: conflict is requeued immediately",
+			cluster:              newCluster(),
+			handlerErr:           apierrors.NewConflict(schema.GroupResource{Resource: "clusters"}, "test1", fmt.Errorf("conflict")),
+			expectedCreatedCalls: 1,
+			expectedResult:       reconcile.Result{Requeue: true},
+		},
+		{
+			name:                 "case 4: other errors are returned",
+			cluster:              newCluster(),
+			handlerErr:           fmt.Errorf("something went wrong"),
+			expectedCreatedCalls: 1,
+			expectError:          true,
+		},
+		{
+			name:    "case 5: missing object is skipped",
+			cluster: nil,
+		},
+		{
+			name:                 "case 6: timeout while retrieving external object is returned",
+			cluster:              newCluster(),
+			handlerErr:           fmt.Errorf("failed to retrieve AzureCluster external object: %w", apierrors.NewTimeoutError("timeout", 1)),
+			expectedCreatedCalls: 1,
+			expectError:          true,
+		},
+		{
+			name: "case 7: changed existing condition is persisted",
+			cluster: func() *capi.Cluster {
+				c := newCluster()
+				capiconditions.MarkFalse(c, capi.ReadyCondition, capi.WaitingForInfrastructureFallbackReason, capi.ConditionSeverityInfo, "")
+				return c
+			}(),
+			expectedCreatedCalls: 1,
+			expectConditionSaved: true,
+			expectedReadyStatus:  corev1.ConditionTrue,
+		},
+		{
+			name: "case 8: handler that checks the pause leaves cluster paused with annotation untouched",
+			cluster: func() *capi.Cluster {
+				c := newCluster()
+				c.Annotations = map[string]string{capi.PausedAnnotation: ""}
+				return c
+			}(),
+			skipWhenPaused:       true,
+			expectedCreatedCalls: 1,
+		},
+		{
+			name: "case 9: handler that checks the pause leaves cluster paused with Spec.Paused untouched",
+			cluster: func() *capi.Cluster {
+				c := newCluster()
+				c.Spec.Paused = true
+				return c
+			}(),
+			skipWhenPaused:       true,
+			expectedCreatedCalls: 1,
+		},
+		{
+			name:                 "case 10: handler that checks the pause changes conditions of cluster that is not paused",
+			cluster:              newCluster(),
+			skipWhenPaused:       true,
+			expectedCreatedCalls: 1,
+			expectConditionSaved: true,
+			expectedReadyStatus:  corev1.ConditionTrue,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			t.Log(tc.name)
+			ctx := context.Background()
+			client := internal.NewFakeClient(capi.AddToScheme)
+			if tc.cluster != nil {
+				err := client.Create(ctx, tc.cluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			logger, err := micrologger.New(micrologger.Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := &testHandler{err: tc.handlerErr, conditionToSet: capi.ReadyCondition, skipWhenPaused: tc.skipWhenPaused}
+			r, err := NewReconciler(Config{
+				CtrlClient: client,
+				Logger:     logger,
+				Handler:    h,
+				Object:     &capi.Cluster{},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "org-test", Name: "test1"}}
+
+			// act
+			result, err := r.Reconcile(ctx, request)
+
+			// assert
+			if tc.expectError && err == nil {
+				t.Error("expected error, got nil")
+			} else if !tc.expectError && err != nil {
+				t.Errorf("expected no error, got %#q", err)
+			}
+			if result != tc.expectedResult {
+				t.Errorf("expected result %#v, got %#v", tc.expectedResult, result)
+			}
+			if h.createdCalls != tc.expectedCreatedCalls {
+				t.Errorf("expected %d EnsureCreated calls, got %d", tc.expectedCreatedCalls, h.createdCalls)
+			}
+			if h.deletedCalls != tc.expectedDeletedCalls {
+				t.Errorf("expected %d EnsureDeleted calls, got %d", tc.expectedDeletedCalls, h.deletedCalls)
+			}
+			if tc.cluster != nil {
+				saved := &capi.Cluster{}
+				err = client.Get(ctx, ctrl.ObjectKeyFromObject(tc.cluster), saved)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if capiconditions.Has(saved, capi.ReadyCondition) != tc.expectConditionSaved {
+					t.Errorf("expected condition saved to be %t", tc.expectConditionSaved)
+				}
+				if tc.expectedReadyStatus != "" && capiconditions.Get(saved, capi.ReadyCondition).Status != tc.expectedReadyStatus {
+					t.Errorf("expected saved Ready status %s, got %s", tc.expectedReadyStatus, capiconditions.Get(saved, capi.ReadyCondition).Status)
+				}
+			}
+		})
+	}
+}
+
+func newCluster() *capi.Cluster {
+	return &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-test",
+			Name:      "test1",
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package statemachine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DOT renders the table as a Graphviz DOT directed graph, with an edge for
+// every transition from every state it can be taken from.
+func (t Table) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", t.ConditionType)
+	b.WriteString("  rankdir=LR;\n")
+	for _, state := range States {
+		fmt.Fprintf(&b, "  %q;\n", state)
+	}
+	for _, transition := range t.Transitions {
+		for _, from := range transition.from() {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", from, transition.To, t.label(transition, "\n"))
+		}
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid renders the table as a Mermaid state diagram, with an edge for
+// every transition from every state it can be taken from.
+func (t Table) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	fmt.Fprintf(&b, "  %%%% %s\n", t.ConditionType)
+	fmt.Fprintf(&b, "  [*] --> %s\n", StateUnknown)
+	for _, transition := range t.Transitions {
+		for _, from := range transition.from() {
+			fmt.Fprintf(&b, "  %s --> %s : %s\n", from, transition.To, t.label(transition, "<br/>"))
+		}
+	}
+
+	return b.String()
+}
+
+// label returns the transition name, its conditions in declared fact order,
+// and its action, separated with the specified line separator.
+func (t Table) label(transition Transition, separator string) string {
+	lines := []string{transition.Name}
+
+	var conditions []string
+	for _, fact := range t.Facts {
+		value, ok := transition.When[fact]
+		if !ok {
+			continue
+		}
+		if value {
+			conditions = append(conditions, string(fact))
+		} else {
+			conditions = append(conditions, "not "+string(fact))
+		}
+	}
+	if len(conditions) > 0 {
+		lines = append(lines, "when "+strings.Join(conditions, ", "))
+	}
+	if transition.Action != "" {
+		lines = append(lines, "do "+string(transition.Action))
+	}
+
+	return strings.Join(lines, separator)
+}
+
+func (tr Transition) from() []State {
+	if len(tr.From) == 0 {
+		return States
+	}
+
+	return tr.From
+}
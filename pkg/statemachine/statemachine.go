@@ -0,0 +1,197 @@
+// Package statemachine describes lifecycle conditions, like Creating and
+// Upgrading, as declarative state-transition tables, which condition handlers
+// execute and which can be rendered as Graphviz DOT or Mermaid diagrams.
+package statemachine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+// State is a state of a lifecycle condition, i.e. its status.
+type State string
+
+const (
+	// StateUnknown is the state of a condition that is not set or that has
+	// status Unknown.
+	StateUnknown State = "Unknown"
+	StateTrue    State = "True"
+	StateFalse   State = "False"
+)
+
+// States contains all lifecycle condition states.
+var States = []State{StateUnknown, StateTrue, StateFalse}
+
+// Fact is a named boolean fact about the reconciled object, e.g. whether its
+// desired release version is deployed.
+type Fact string
+
+// Facts holds fact values of the reconciled object. Facts that are not set
+// are false.
+type Facts map[Fact]bool
+
+// Action identifies what the handler does when a transition is taken. Empty
+// action leaves the condition unchanged.
+type Action string
+
+// Transition is a row of a state-transition table.
+type Transition struct {
+	// Name briefly describes the transition.
+	Name string
+	// From contains states in which the transition can be taken. Empty From
+	// matches any state.
+	From []State
+	// When contains facts that must have the specified values for the
+	// transition to be taken. Empty When matches any facts.
+	When Facts
+	// To is the state after the transition.
+	To State
+	// Action is executed by the handler when the transition is taken.
+	Action Action
+}
+
+// Table is a state-transition table of a lifecycle condition. Transitions are
+// evaluated in order and the first matching transition is taken.
+type Table struct {
+	// ConditionType is the lifecycle condition described by the table.
+	ConditionType capi.ConditionType
+	// Facts contains all facts that transitions depend on.
+	Facts []Fact
+	// Transitions are evaluated in order.
+	Transitions []Transition
+}
+
+// StateOf returns the state of the specified condition.
+func StateOf(condition *capi.Condition) State {
+	if condition == nil {
+		return StateUnknown
+	}
+
+	switch condition.Status {
+	case corev1.ConditionTrue:
+		return StateTrue
+	case corev1.ConditionFalse:
+		return StateFalse
+	default:
+		return StateUnknown
+	}
+}
+
+// Next returns the first transition that can be taken from the specified
+// state with the specified facts.
+func (t Table) Next(state State, facts Facts) (Transition, error) {
+	for _, transition := range t.Transitions {
+		if transition.matches(state, facts) {
+			return transition, nil
+		}
+	}
+
+	return Transition{}, microerror.Maskf(errors.TransitionNotFoundError, "%s transition from state %s with facts %s is not defined", t.ConditionType, state, t.sprintFacts(facts))
+}
+
+// Validate checks that transitions use only declared facts and that the
+// table is complete, i.e. that a transition is defined for every state and
+// every combination of facts.
+func (t Table) Validate() error {
+	declared := map[Fact]bool{}
+	for _, fact := range t.Facts {
+		declared[fact] = true
+	}
+	for _, transition := range t.Transitions {
+		for fact := range transition.When {
+			if !declared[fact] {
+				return microerror.Maskf(errors.InvalidConfigError, "%s transition %q uses undeclared fact %s", t.ConditionType, transition.Name, fact)
+			}
+		}
+		if !isState(transition.To) {
+			return microerror.Maskf(errors.InvalidConfigError, "%s transition %q has invalid target state %q", t.ConditionType, transition.Name, transition.To)
+		}
+		for _, state := range transition.From {
+			if !isState(state) {
+				return microerror.Maskf(errors.InvalidConfigError, "%s transition %q has invalid source state %q", t.ConditionType, transition.Name, state)
+			}
+		}
+	}
+
+	for _, state := range States {
+		for _, facts := range t.AllFacts() {
+			_, err := t.Next(state, facts)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// AllFacts returns all combinations of declared fact values.
+func (t Table) AllFacts() []Facts {
+	var combinations []Facts
+	for i := 0; i < 1<<len(t.Facts); i++ {
+		facts := Facts{}
+		for j, fact := range t.Facts {
+			facts[fact] = i&(1<<j) != 0
+		}
+		combinations = append(combinations, facts)
+	}
+
+	return combinations
+}
+
+// Actions returns all actions used in the table.
+func (t Table) Actions() []Action {
+	var actions []Action
+	seen := map[Action]bool{}
+	for _, transition := range t.Transitions {
+		if transition.Action == "" || seen[transition.Action] {
+			continue
+		}
+		seen[transition.Action] = true
+		actions = append(actions, transition.Action)
+	}
+
+	return actions
+}
+
+func (t Table) sprintFacts(facts Facts) string {
+	var values []string
+	for _, fact := range t.Facts {
+		values = append(values, fmt.Sprintf("%s=%t", fact, facts[fact]))
+	}
+
+	return "{" + strings.Join(values, ", ") + "}"
+}
+
+func (tr Transition) matches(state State, facts Facts) bool {
+	if len(tr.From) > 0 && !containsState(tr.From, state) {
+		return false
+	}
+	for fact, value := range tr.When {
+		if facts[fact] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsState(states []State, state State) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isState(state State) bool {
+	return containsState(States, state)
+}
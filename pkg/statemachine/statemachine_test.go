@@ -0,0 +1,172 @@
+package statemachine
+
+import (
+	"testing"
+
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+const (
+	factA Fact = "A"
+	factB Fact = "B"
+)
+
+func testTable() Table {
+	return Table{
+		ConditionType: "Testing",
+		Facts:         []Fact{factA, factB},
+		Transitions: []Transition{
+			{
+				Name:   "started",
+				From:   []State{StateUnknown, StateFalse},
+				When:   Facts{factA: true},
+				To:     StateTrue,
+				Action: "MarkTestingTrue",
+			},
+			{
+				Name:   "completed",
+				From:   []State{StateTrue},
+				When:   Facts{factA: false, factB: true},
+				To:     StateFalse,
+				Action: "MarkTestingFalse",
+			},
+			{
+				Name: "unchanged",
+				To:   StateFalse,
+			},
+		},
+	}
+}
+
+func TestNext(t *testing.T) {
+	testCases := []struct {
+		name               string
+		state              State
+		facts              Facts
+		expectedTransition string
+	}{
+		{
+			name:               "case 0: first matching transition is taken",
+			state:              StateUnknown,
+			facts:              Facts{factA: true, factB: true},
+			expectedTransition: "started",
+		},
+		{
+			name:               "case 1: all facts must match",
+			state:              StateTrue,
+			facts:              Facts{factA: true, factB: true},
+			expectedTransition: "unchanged",
+		},
+		{
+			name:               "case 2: facts that are not set are false",
+			state:              StateTrue,
+			facts:              Facts{factB: true},
+			expectedTransition: "completed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			transition, err := testTable().Next(tc.state, tc.facts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if transition.Name != tc.expectedTransition {
+				t.Errorf("expected transition %q, got %q", tc.expectedTransition, transition.Name)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		table   func() Table
+		errorIs func(error) bool
+	}{
+		{
+			name:  "case 0: complete table is valid",
+			table: testTable,
+		},
+		{
+			name: "case 1: incomplete table is invalid",
+			table: func() Table {
+				table := testTable()
+				table.Transitions = table.Transitions[:2]
+				return table
+			},
+			errorIs: errors.IsTransitionNotFound,
+		},
+		{
+			name: "case 2: undeclared fact is invalid",
+			table: func() Table {
+				table := testTable()
+				table.Transitions[0].When = Facts{"C": true}
+				return table
+			},
+			errorIs: errors.IsInvalidConfig,
+		},
+		{
+			name: "case 3: invalid state is invalid",
+			table: func() Table {
+				table := testTable()
+				table.Transitions[0].To = "Done"
+				return table
+			},
+			errorIs: errors.IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			err := tc.table().Validate()
+			if tc.errorIs == nil && err != nil {
+				t.Fatalf("expected no error, got %s", err)
+			}
+			if tc.errorIs != nil && !tc.errorIs(err) {
+				t.Fatalf("expected error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDOT(t *testing.T) {
+	expected := `digraph Testing {
+  rankdir=LR;
+  "Unknown";
+  "True";
+  "False";
+  "Unknown" -> "True" [label="started\nwhen A\ndo MarkTestingTrue"];
+  "False" -> "True" [label="started\nwhen A\ndo MarkTestingTrue"];
+  "True" -> "False" [label="completed\nwhen not A, B\ndo MarkTestingFalse"];
+  "Unknown" -> "False" [label="unchanged"];
+  "True" -> "False" [label="unchanged"];
+  "False" -> "False" [label="unchanged"];
+}
+`
+	actual := testTable().DOT()
+	if actual != expected {
+		t.Errorf("expected DOT\n%s\ngot\n%s", expected, actual)
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	expected := `stateDiagram-v2
+  %% Testing
+  [*] --> Unknown
+  Unknown --> True : started<br/>when A<br/>do MarkTestingTrue
+  False --> True : started<br/>when A<br/>do MarkTestingTrue
+  True --> False : completed<br/>when not A, B<br/>do MarkTestingFalse
+  Unknown --> False : unchanged
+  True --> False : unchanged
+  False --> False : unchanged
+`
+	actual := testTable().Mermaid()
+	if actual != expected {
+		t.Errorf("expected Mermaid\n%s\ngot\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,314 @@
+// Package validation checks conditions against Cluster API conventions, e.g.
+// that False conditions have a severity and a CamelCase reason, and that
+// condition types are known for the object kind.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+
+	"github.com/giantswarm/conditions-handler/pkg/catalog"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/degraded"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/frozen"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/nodepoolsupgrading"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/scaling"
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+)
+
+// Rule is the name of a convention that a condition violates.
+type Rule string
+
+const (
+	// RuleDuplicateType is violated when a condition type is set more than
+	// once.
+	RuleDuplicateType Rule = "DuplicateType"
+	// RuleUnknownType is violated when a condition type is not known for
+	// the object kind.
+	RuleUnknownType Rule = "UnknownType"
+	// RuleSeverityRequired is violated when a False or Unknown condition does
+	// not have a severity.
+	RuleSeverityRequired Rule = "SeverityRequired"
+	// RuleInvalidSeverity is violated when a severity is not Error, Warning
+	// or Info.
+	RuleInvalidSeverity Rule = "InvalidSeverity"
+	// RuleReasonRequired is violated when a False or Unknown condition does
+	// not have a reason.
+	RuleReasonRequired Rule = "ReasonRequired"
+	// RuleReasonNotCamelCase is violated when a reason is not CamelCase.
+	RuleReasonNotCamelCase Rule = "ReasonNotCamelCase"
+	// RuleTrueWithSeverity is violated when a True condition has a severity.
+	RuleTrueWithSeverity Rule = "TrueWithSeverity"
+	// RuleTrueWithReason is violated when a True condition with positive
+	// polarity has a reason.
+	RuleTrueWithReason Rule = "TrueWithReason"
+	// RuleFutureTransition is violated when LastTransitionTime is in the
+	// future.
+	RuleFutureTransition Rule = "FutureTransition"
+	// RuleUnregisteredReason is violated when a reason is not registered in
+	// the catalog for the condition type, status and severity.
+	RuleUnregisteredReason Rule = "UnregisteredReason"
+)
+
+// DefaultKnownConditionTypes are condition types set by Cluster API and by
+// condition handlers, for every object kind.
+var DefaultKnownConditionTypes = map[string][]capi.ConditionType{
+	"Cluster": {
+		capi.ReadyCondition,
+		capi.InfrastructureReadyCondition,
+		capi.ControlPlaneReadyCondition,
+		capi.ControlPlaneInitializedCondition,
+		conditions.NodePoolsReady,
+		nodepoolsupgrading.NodePoolsUpgrading,
+		conditions.Creating,
+		conditions.Upgrading,
+		paused.Paused,
+		frozen.Frozen,
+		degraded.Degraded,
+	},
+	"MachinePool": {
+		capi.ReadyCondition,
+		capi.BootstrapReadyCondition,
+		capi.InfrastructureReadyCondition,
+		capiexp.ReplicasReadyCondition,
+		conditions.Creating,
+		conditions.Upgrading,
+		scaling.Scaling,
+		paused.Paused,
+		frozen.Frozen,
+		degraded.Degraded,
+	},
+}
+
+// DefaultNegativePolarityConditionTypes are condition types with negative
+// polarity, which are True when something is in progress or wrong. They may
+// have a reason when True.
+var DefaultNegativePolarityConditionTypes = []capi.ConditionType{
+	conditions.Creating,
+	conditions.Upgrading,
+	nodepoolsupgrading.NodePoolsUpgrading,
+	scaling.Scaling,
+	paused.Paused,
+	frozen.Frozen,
+	degraded.Degraded,
+}
+
+var camelCase = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+type Config struct {
+	// KnownConditionTypes are condition types known for object kinds. Types
+	// of objects whose kind is not in the map are not checked. Defaults to
+	// DefaultKnownConditionTypes.
+	KnownConditionTypes map[string][]capi.ConditionType
+	// ExtraConditionTypes are known for all kinds in KnownConditionTypes,
+	// e.g. types of conditions computed by custom handlers.
+	ExtraConditionTypes []capi.ConditionType
+	// NegativePolarityConditionTypes are condition types that may have a
+	// reason when True. Defaults to DefaultNegativePolarityConditionTypes.
+	// Condition types registered in Catalog with negative polarity may have
+	// a reason when True as well.
+	NegativePolarityConditionTypes []capi.ConditionType
+	// MaxClockSkew is the tolerated difference between LastTransitionTime in
+	// the future and the current time.
+	MaxClockSkew time.Duration
+	// Catalog contains registered reasons of condition types. When set,
+	// reasons of registered condition types are checked.
+	Catalog *catalog.Catalog
+}
+
+// Validator checks conditions against Cluster API conventions.
+type Validator struct {
+	knownConditionTypes            map[string]map[capi.ConditionType]bool
+	negativePolarityConditionTypes map[capi.ConditionType]bool
+	maxClockSkew                   time.Duration
+	catalog                        *catalog.Catalog
+}
+
+func NewValidator(config Config) (*Validator, error) {
+	if config.MaxClockSkew < 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.MaxClockSkew must not be negative", config)
+	}
+	if config.KnownConditionTypes == nil {
+		config.KnownConditionTypes = DefaultKnownConditionTypes
+	}
+	if config.NegativePolarityConditionTypes == nil {
+		config.NegativePolarityConditionTypes = DefaultNegativePolarityConditionTypes
+	}
+
+	v := &Validator{
+		knownConditionTypes:            map[string]map[capi.ConditionType]bool{},
+		negativePolarityConditionTypes: map[capi.ConditionType]bool{},
+		maxClockSkew:                   config.MaxClockSkew,
+		catalog:                        config.Catalog,
+	}
+	for kind, conditionTypes := range config.KnownConditionTypes {
+		v.knownConditionTypes[kind] = map[capi.ConditionType]bool{}
+		for _, conditionType := range conditionTypes {
+			v.knownConditionTypes[kind][conditionType] = true
+		}
+		for _, conditionType := range config.ExtraConditionTypes {
+			v.knownConditionTypes[kind][conditionType] = true
+		}
+	}
+	for _, conditionType := range config.NegativePolarityConditionTypes {
+		v.negativePolarityConditionTypes[conditionType] = true
+	}
+
+	return v, nil
+}
+
+// Violation is a condition that violates a convention.
+type Violation struct {
+	ConditionType capi.ConditionType
+	Rule          Rule
+	Message       string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s: %s", v.ConditionType, v.Rule, v.Message)
+}
+
+// Validate checks the specified conditions of an object of the specified
+// kind, and returns all found violations.
+func (v *Validator) Validate(kind string, conditions capi.Conditions) []Violation {
+	var violations []Violation
+	add := func(conditionType capi.ConditionType, rule Rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{
+			ConditionType: conditionType,
+			Rule:          rule,
+			Message:       fmt.Sprintf(format, args...),
+		})
+	}
+
+	seen := map[capi.ConditionType]bool{}
+	knownConditionTypes, kindIsKnown := v.knownConditionTypes[kind]
+	// LastTransitionTime is set by Cluster API with the wall clock, so it is
+	// not compared with the handler clock, which can be faked in tests.
+	now := time.Now()
+
+	for _, c := range conditions {
+		if seen[c.Type] {
+			add(c.Type, RuleDuplicateType, "condition type is set more than once")
+		}
+		seen[c.Type] = true
+
+		if kindIsKnown && !knownConditionTypes[c.Type] {
+			add(c.Type, RuleUnknownType, "condition type is not known for %s", kind)
+		}
+
+		switch c.Status {
+		case corev1.ConditionTrue:
+			if c.Severity != "" {
+				add(c.Type, RuleTrueWithSeverity, "True condition has severity %q", c.Severity)
+			}
+			if c.Reason != "" {
+				if !v.isNegativePolarity(c.Type) {
+					add(c.Type, RuleTrueWithReason, "True condition with positive polarity has reason %q", c.Reason)
+				} else if !isCamelCase(c.Reason) {
+					add(c.Type, RuleReasonNotCamelCase, "reason %q is not CamelCase", c.Reason)
+				}
+			}
+		default:
+			switch c.Severity {
+			case "":
+				add(c.Type, RuleSeverityRequired, "%s condition does not have severity", c.Status)
+			case capi.ConditionSeverityError, capi.ConditionSeverityWarning, capi.ConditionSeverityInfo:
+			default:
+				add(c.Type, RuleInvalidSeverity, "severity %q is not %s, %s or %s", c.Severity, capi.ConditionSeverityError, capi.ConditionSeverityWarning, capi.ConditionSeverityInfo)
+			}
+			if c.Reason == "" {
+				add(c.Type, RuleReasonRequired, "%s condition does not have reason", c.Status)
+			} else if !isCamelCase(c.Reason) {
+				add(c.Type, RuleReasonNotCamelCase, "reason %q is not CamelCase", c.Reason)
+			}
+		}
+
+		if v.catalog != nil && c.Reason != "" {
+			if conditionType, ok := v.catalog.ConditionType(c.Type); ok {
+				reason, registered := conditionType.Reason(c.Reason)
+				if registered && !reason.Allows(c.Status, c.Severity) {
+					add(c.Type, RuleUnregisteredReason, "reason %q is not registered for status %s and severity %q", c.Reason, c.Status, c.Severity)
+				} else if !registered && !conditionType.AnyReason {
+					add(c.Type, RuleUnregisteredReason, "reason %q is not registered", c.Reason)
+				}
+			}
+		}
+
+		if c.LastTransitionTime.Time.After(now.Add(v.maxClockSkew)) {
+			add(c.Type, RuleFutureTransition, "last transition time %s is in the future", c.LastTransitionTime.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return violations
+}
+
+// isNegativePolarity checks if the specified condition type has negative
+// polarity in the validator config or in the catalog.
+func (v *Validator) isNegativePolarity(conditionType capi.ConditionType) bool {
+	if v.negativePolarityConditionTypes[conditionType] {
+		return true
+	}
+	if v.catalog != nil {
+		registered, ok := v.catalog.ConditionType(conditionType)
+		return ok && registered.NegativePolarity
+	}
+
+	return false
+}
+
+// ValidateObject checks conditions of the specified object. The kind is
+// taken from the object type meta, or from the object type when type meta is
+// not set.
+func (v *Validator) ValidateObject(object capiconditions.Getter) []Violation {
+	return v.Validate(kindOf(object), object.GetConditions())
+}
+
+// PostCheck returns an error with all violations of the condition of the
+// specified type, so it can be used as handler.PostCheckFunc.
+func (v *Validator) PostCheck(object conditions.Object, conditionType capi.ConditionType) error {
+	var messages []string
+	for _, violation := range v.ValidateObject(object) {
+		if violation.ConditionType == conditionType {
+			messages = append(messages, violation.String())
+		}
+	}
+	if len(messages) > 0 {
+		return microerror.Maskf(errors.InvalidConditionError, "%s %s/%s has invalid condition: %s", kindOf(object), object.GetNamespace(), object.GetName(), strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+// isCamelCase checks if the reason is CamelCase. Reasons of aggregated
+// conditions are suffixed with the source object, like
+// "ReplicasNotReady @ MachinePool/np1", and only the part before the suffix
+// is checked.
+func isCamelCase(reason string) bool {
+	return camelCase.MatchString(catalog.TrimSourceRef(reason))
+}
+
+func kindOf(object interface{}) string {
+	if o, ok := object.(runtime.Object); ok {
+		if kind := o.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+			return kind
+		}
+	}
+
+	t := reflect.TypeOf(object)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
@@ -0,0 +1,335 @@
+package validation
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/conditions/pkg/conditions"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/conditions-handler/pkg/catalog"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/custom"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/frozen"
+	"github.com/giantswarm/conditions-handler/pkg/conditions/paused"
+	"github.com/giantswarm/conditions-handler/pkg/errors"
+	"github.com/giantswarm/conditions-handler/pkg/internal"
+)
+
+func TestValidate(t *testing.T) {
+	now := metav1.Now()
+	future := metav1.NewTime(time.Now().Add(time.Hour))
+
+	testCases := []struct {
+		name               string
+		config             Config
+		kind               string
+		conditions         capi.Conditions
+		expectedViolations []Rule
+	}{
+		{
+			name: "case 0: valid conditions",
+			kind: "Cluster",
+			conditions: capi.Conditions{
+				{Type: capi.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: now},
+				{Type: capi.InfrastructureReadyCondition, Status: corev1.ConditionFalse, Reason: "WaitingForInfrastructure", Severity: capi.ConditionSeverityWarning, LastTransitionTime: now},
+				{Type: conditions.NodePoolsReady, Status: corev1.ConditionFalse, Reason: "ReplicasNotReady @ MachinePool/np1", Severity: capi.ConditionSeverityWarning, LastTransitionTime: now},
+				{Type: conditions.Upgrading, Status: corev1.ConditionUnknown, Reason: "UpgradeUnknown", Severity: capi.ConditionSeverityInfo, LastTransitionTime: now},
+			},
+		},
+		{
+			name: "case 1: negative polarity conditions with reasons",
+			kind: "MachinePool",
+			conditions: capi.Conditions{
+				{Type: "Scaling", Status: corev1.ConditionTrue, Reason: "ScalingUp", LastTransitionTime: now},
+				{Type: paused.Paused, Status: corev1.ConditionTrue, Reason: paused.PausedByAnnotationReason, LastTransitionTime: now},
+				{Type: frozen.Frozen, Status: corev1.ConditionTrue, Reason: frozen.SkipAnnotationReason, LastTransitionTime: now},
+				{Type: "Degraded", Status: corev1.ConditionTrue, Reason: "ConditionsDegraded", LastTransitionTime: now},
+			},
+		},
+		{
+			name: "case 2: False and Unknown conditions without severity and reason",
+			kind: "Cluster",
+			conditions: capi.Conditions{
+				{Type: capi.ReadyCondition, Status: corev1.ConditionFalse, LastTransitionTime: now},
+				{Type: capi.InfrastructureReadyCondition, Status: corev1.ConditionUnknown, LastTransitionTime: now},
+			},
+			expectedViolations: []Rule{RuleSeverityRequired, RuleReasonRequired, RuleSeverityRequired, RuleReasonRequired},
+		},
+		{
+			name: "case 3: invalid severity and reasons that are not CamelCase",
+			kind: "Cluster",
+			conditions: capi.Conditions{
+				{Type: capi.ReadyCondition, Status: corev1.ConditionFalse, Reason: "not ready", Severity: "Critical", LastTransitionTime: now},
+				{Type: paused.Paused, Status: corev1.ConditionTrue, Reason: "paused_by_annotation", LastTransitionTime: now},
+			},
+			expectedViolations: []Rule{RuleInvalidSeverity, RuleReasonNotCamelCase, RuleReasonNotCamelCase},
+		},
+		{
+			name: "case 4: True condition with severity and reason",
+			kind: "Cluster",
+			conditions: capi.Conditions{
+				{Type: capi.ReadyCondition, Status: corev1.ConditionTrue, Reason: "AllGood", Severity: capi.ConditionSeverityInfo, LastTransitionTime: now},
+			},
+			expectedViolations: []Rule{RuleTrueWithSeverity, RuleTrueWithReason},
+		},
+		{
+			name: "case 5: unknown and duplicate condition types",
+			kind: "MachinePool",
+			conditions: capi.Conditions{
+				{Type: capi.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: now},
+				{Type: capi.ControlPlaneReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: now},
+				{Type: capi.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: now},
+			},
+			expectedViolations: []Rule{RuleUnknownType, RuleDuplicateType},
+		},
+		{
+			name: "case 6: condition types of unknown kind are not checked",
+			kind: "AzureCluster",
+			conditions: capi.Conditions{
+				{Type: "NetworkReady", Status: corev1.ConditionTrue, LastTransitionTime: now},
+			},
+		},
+		{
+			name:   "case 7: extra condition type",
+			config: Config{ExtraConditionTypes: []capi.ConditionType{"KubeconfigSecretPresent"}},
+			kind:   "Cluster",
+			conditions: capi.Conditions{
+				{Type: "KubeconfigSecretPresent", Status: corev1.ConditionTrue, LastTransitionTime: now},
+			},
+		},
+		{
+			name:   "case 8: extra negative polarity condition type",
+			config: Config{NegativePolarityConditionTypes: []capi.ConditionType{capi.ReadyCondition}},
+			kind:   "Cluster",
+			conditions: capi.Conditions{
+				{Type: capi.ReadyCondition, Status: corev1.ConditionTrue, Reason: "Ready", LastTransitionTime: now},
+				{Type: paused.Paused, Status: corev1.ConditionTrue, Reason: paused.PausedByAnnotationReason, LastTransitionTime: now},
+			},
+			expectedViolations: []Rule{RuleTrueWithReason},
+		},
+		{
+			name: "case 9: last transition time in the future",
+			kind: "Cluster",
+			conditions: capi.Conditions{
+				{Type: capi.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: future},
+			},
+			expectedViolations: []Rule{RuleFutureTransition},
+		},
+		{
+			name:   "case 10: last transition time in the future within tolerated clock skew",
+			config: Config{MaxClockSkew: 2 * time.Hour},
+			kind:   "Cluster",
+			conditions: capi.Conditions{
+				{Type: capi.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: future},
+			},
+		},
+		{
+			name:   "case 11: registered reasons",
+			config: Config{Catalog: catalog.NewDefaultCatalog()},
+			kind:   "MachinePool",
+			conditions: capi.Conditions{
+				{Type: "Scaling", Status: corev1.ConditionFalse, Reason: "DesiredReplicasReady", Severity: capi.ConditionSeverityInfo, LastTransitionTime: now},
+				{Type: conditions.Upgrading, Status: corev1.ConditionFalse, Reason: "UpgradeCompleted", Severity: capi.ConditionSeverityInfo, LastTransitionTime: now},
+			},
+		},
+		{
+			name:   "case 12: unregistered reason and registered reason with unregistered severity",
+			config: Config{Catalog: catalog.NewDefaultCatalog()},
+			kind:   "MachinePool",
+			conditions: capi.Conditions{
+				{Type: "Scaling", Status: corev1.ConditionFalse, Reason: "NotScaling", Severity: capi.ConditionSeverityInfo, LastTransitionTime: now},
+				{Type: conditions.Upgrading, Status: corev1.ConditionFalse, Reason: "UpgradeCompleted", Severity: capi.ConditionSeverityWarning, LastTransitionTime: now},
+			},
+			expectedViolations: []Rule{RuleUnregisteredReason, RuleUnregisteredReason},
+		},
+		{
+			name:   "case 13: unregistered mirrored and aggregated reasons",
+			config: Config{Catalog: catalog.NewDefaultCatalog()},
+			kind:   "Cluster",
+			conditions: capi.Conditions{
+				{Type: capi.InfrastructureReadyCondition, Status: corev1.ConditionFalse, Reason: "VPCNotReady", Severity: capi.ConditionSeverityWarning, LastTransitionTime: now},
+				{Type: conditions.NodePoolsReady, Status: corev1.ConditionFalse, Reason: "ReplicasNotReady @ MachinePool/np1", Severity: capi.ConditionSeverityWarning, LastTransitionTime: now},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			v, err := NewValidator(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			violations := v.Validate(tc.kind, tc.conditions)
+
+			// assert
+			var rules []Rule
+			for _, violation := range violations {
+				rules = append(rules, violation.Rule)
+			}
+			if !reflect.DeepEqual(rules, tc.expectedViolations) {
+				t.Errorf("expected violations %v, got %v", tc.expectedViolations, violations)
+			}
+		})
+	}
+}
+
+func TestValidateObject(t *testing.T) {
+	// arrange
+	v, err := NewValidator(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	machinePool := &capiexp.MachinePool{
+		Status: capiexp.MachinePoolStatus{
+			Conditions: capi.Conditions{
+				{Type: capi.ControlPlaneReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+
+	// act
+	violations := v.ValidateObject(machinePool)
+
+	// assert
+	if len(violations) != 1 || violations[0].Rule != RuleUnknownType {
+		t.Fatalf("expected %s violation for MachinePool without type meta, got %v", RuleUnknownType, violations)
+	}
+}
+
+func TestPostCheck(t *testing.T) {
+	const conditionType capi.ConditionType = "KubeconfigSecretPresent"
+
+	registered := catalog.NewDefaultCatalog()
+	customConditionType := catalog.CustomConditionType(conditionType)
+	customConditionType.NegativePolarity = true
+	err := registered.Register(customConditionType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name             string
+		config           Config
+		negativePolarity bool
+		errorMatcher     func(error) bool
+	}{
+		{
+			name:   "case 0: True condition with positive polarity is valid",
+			config: Config{ExtraConditionTypes: []capi.ConditionType{conditionType}},
+		},
+		{
+			name:             "case 1: True condition with reason fails the handler when negative polarity is not registered",
+			config:           Config{ExtraConditionTypes: []capi.ConditionType{conditionType}},
+			negativePolarity: true,
+			errorMatcher:     errors.IsInvalidCondition,
+		},
+		{
+			name: "case 2: True condition with negative polarity and reason is valid",
+			config: Config{
+				ExtraConditionTypes:            []capi.ConditionType{conditionType},
+				NegativePolarityConditionTypes: append([]capi.ConditionType{conditionType}, DefaultNegativePolarityConditionTypes...),
+			},
+			negativePolarity: true,
+		},
+		{
+			name: "case 3: True condition with negative polarity registered in catalog and reason is valid",
+			config: Config{
+				ExtraConditionTypes: []capi.ConditionType{conditionType},
+				Catalog:             registered,
+			},
+			negativePolarity: true,
+		},
+		{
+			name:         "case 4: unknown condition type fails the handler",
+			config:       Config{},
+			errorMatcher: errors.IsInvalidCondition,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(tc.name)
+
+			// arrange
+			ctx := context.Background()
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-test", Name: "test1"},
+			}
+			client := internal.NewFakeClient(capi.AddToScheme)
+			err := client.Create(ctx, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger, err := micrologger.New(micrologger.Config{IOWriter: io.Discard})
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := NewValidator(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h, err := custom.NewHandler(custom.HandlerConfig{
+				CtrlClient:       client,
+				Logger:           logger,
+				Name:             "kubeconfigSecretPresentHandler",
+				UpdateStatus:     true,
+				PostCheck:        v.PostCheck,
+				ConditionType:    conditionType,
+				NegativePolarity: tc.negativePolarity,
+				Expressions: custom.Expressions{
+					Status: "true",
+					Reason: `"SecretMissing"`,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			err = h.EnsureCreated(ctx, cluster)
+
+			// assert
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				saved := &capi.Cluster{}
+				err = client.Get(ctx, ctrl.ObjectKeyFromObject(cluster), saved)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(saved.Status.Conditions) > 0 {
+					t.Errorf("expected invalid condition not to be saved, got %v", saved.Status.Conditions)
+				}
+			}
+		})
+	}
+}
+
+func TestNewValidator(t *testing.T) {
+	_, err := NewValidator(Config{MaxClockSkew: -time.Second})
+	if !errors.IsInvalidConfig(err) {
+		t.Fatalf("error == %#v, want invalid config error", err)
+	}
+}
@@ -0,0 +1,194 @@
+package watch
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrlhandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+)
+
+var (
+	ClusterGVK     = capi.GroupVersion.WithKind("Cluster")
+	MachinePoolGVK = capiexp.GroupVersion.WithKind("MachinePool")
+)
+
+// Source is an object kind read by a handler, together with a func that maps
+// objects of that kind to reconciled objects, so that reconciled objects can
+// be reconciled again when objects they depend on are changed.
+type Source struct {
+	GVK     schema.GroupVersionKind
+	MapFunc ctrlhandler.MapFunc
+}
+
+// Watcher is implemented by handlers that read objects other than the
+// reconciled one, e.g. provider-specific infrastructure objects or node
+// pools.
+type Watcher interface {
+	// Watches returns sources read by the handler when reconciling objects
+	// of the specified kind.
+	Watches(reconciled schema.GroupVersionKind) []Source
+}
+
+// compositeHandler is implemented by handlers that consist of multiple
+// handlers, like composite.Handler.
+type compositeHandler interface {
+	Handlers() []handler.Interface
+}
+
+// Sources returns sources of the specified handler and of all handlers it
+// consists of. Sources of the same kind are merged into a single source,
+// whose MapFunc returns requests of all merged sources without duplicates.
+func Sources(h handler.Interface, reconciled schema.GroupVersionKind) []Source {
+	var sources []Source
+	collectSources(h, reconciled, &sources)
+
+	return sources
+}
+
+func collectSources(h handler.Interface, reconciled schema.GroupVersionKind, sources *[]Source) {
+	if watcher, ok := h.(Watcher); ok {
+		for _, s := range watcher.Watches(reconciled) {
+			addSource(sources, s)
+		}
+	}
+
+	if composite, ok := h.(compositeHandler); ok {
+		for _, child := range composite.Handlers() {
+			collectSources(child, reconciled, sources)
+		}
+	}
+}
+
+func addSource(sources *[]Source, s Source) {
+	for i, existing := range *sources {
+		if existing.GVK != s.GVK {
+			continue
+		}
+
+		// MapFuncs are always merged, since funcs cannot be compared, e.g.
+		// ToOwner returns different closures for different owner kinds.
+		(*sources)[i].MapFunc = mergeMapFuncs(existing.MapFunc, s.MapFunc)
+		return
+	}
+
+	*sources = append(*sources, s)
+}
+
+func mergeMapFuncs(first, second ctrlhandler.MapFunc) ctrlhandler.MapFunc {
+	return func(object ctrl.Object) []reconcile.Request {
+		var requests []reconcile.Request
+		seen := map[types.NamespacedName]bool{}
+		for _, request := range append(first(object), second(object)...) {
+			if seen[request.NamespacedName] {
+				continue
+			}
+			seen[request.NamespacedName] = true
+			requests = append(requests, request)
+		}
+
+		return requests
+	}
+}
+
+// SetupWatches adds watches for all sources of the specified handler to the
+// specified controller builder. Watched objects trigger reconciliation only
+// when their conditions are changed, and optionally only when the specified
+// predicates pass.
+func SetupWatches(b *builder.Builder, h handler.Interface, reconciled schema.GroupVersionKind, predicates ...predicate.Predicate) *builder.Builder {
+	for _, s := range Sources(h, reconciled) {
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(s.GVK)
+
+		b = b.Watches(
+			&source.Kind{Type: object},
+			ctrlhandler.EnqueueRequestsFromMapFunc(s.MapFunc),
+			builder.WithPredicates(append([]predicate.Predicate{ConditionsChanged()}, predicates...)...))
+	}
+
+	return b
+}
+
+// ConditionsChanged returns a predicate that passes update events only when
+// the object conditions are changed. Other events always pass.
+func ConditionsChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(getConditions(e.ObjectOld), getConditions(e.ObjectNew))
+		},
+	}
+}
+
+func getConditions(object ctrl.Object) capi.Conditions {
+	switch o := object.(type) {
+	case *unstructured.Unstructured:
+		return capiconditions.UnstructuredGetter(o).GetConditions()
+	case capiconditions.Getter:
+		return o.GetConditions()
+	default:
+		return nil
+	}
+}
+
+// MachinePoolToCluster maps a MachinePool to its Cluster by cluster name
+// label, or by Spec.ClusterName when the label is not set.
+func MachinePoolToCluster(object ctrl.Object) []reconcile.Request {
+	clusterName := object.GetLabels()[capi.ClusterLabelName]
+	if clusterName == "" {
+		switch o := object.(type) {
+		case *capiexp.MachinePool:
+			clusterName = o.Spec.ClusterName
+		case *unstructured.Unstructured:
+			clusterName, _, _ = unstructured.NestedString(o.Object, "spec", "clusterName")
+		}
+	}
+
+	if clusterName == "" {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Namespace: object.GetNamespace(),
+				Name:      clusterName,
+			},
+		},
+	}
+}
+
+// ToOwner returns a MapFunc that maps an object to its owners of the
+// specified kind, e.g. a provider-specific infrastructure object to its
+// Cluster or MachinePool.
+func ToOwner(owner schema.GroupVersionKind) ctrlhandler.MapFunc {
+	return func(object ctrl.Object) []reconcile.Request {
+		var requests []reconcile.Request
+		for _, ownerReference := range object.GetOwnerReferences() {
+			gv, err := schema.ParseGroupVersion(ownerReference.APIVersion)
+			if err != nil || gv.Group != owner.Group || ownerReference.Kind != owner.Kind {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: object.GetNamespace(),
+					Name:      ownerReference.Name,
+				},
+			})
+		}
+
+		return requests
+	}
+}
@@ -0,0 +1,146 @@
+package watch
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	capiexp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	capiconditions "sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/giantswarm/conditions-handler/pkg/handler"
+)
+
+type testHandler struct {
+	sources  []Source
+	children []handler.Interface
+}
+
+func (h *testHandler) EnsureCreated(_ context.Context, _ interface{}) error { return nil }
+func (h *testHandler) EnsureDeleted(_ context.Context, _ interface{}) error { return nil }
+func (h *testHandler) Name() string                                         { return "test" }
+
+func (h *testHandler) Watches(_ schema.GroupVersionKind) []Source { return h.sources }
+func (h *testHandler) Handlers() []handler.Interface              { return h.children }
+
+func TestSources(t *testing.T) {
+	testName := "sources of nested handlers are collected and sources of the same kind are merged"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		infrastructureGVK := schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "AzureCluster"}
+		h := &testHandler{
+			children: []handler.Interface{
+				&testHandler{sources: []Source{{GVK: MachinePoolGVK, MapFunc: MachinePoolToCluster}}},
+				&testHandler{sources: []Source{{GVK: MachinePoolGVK, MapFunc: MachinePoolToCluster}}},
+				&testHandler{sources: []Source{{GVK: infrastructureGVK, MapFunc: ToOwner(ClusterGVK)}}},
+			},
+		}
+
+		// act
+		sources := Sources(h, ClusterGVK)
+
+		// assert
+		if len(sources) != 2 || sources[0].GVK != MachinePoolGVK || sources[1].GVK != infrastructureGVK {
+			t.Fatalf("expected MachinePool and AzureCluster sources, got %v", sources)
+		}
+	})
+}
+
+func TestMergedSources(t *testing.T) {
+	testName := "merged source maps objects with MapFuncs of all sources of the same kind without duplicate requests"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		infrastructureGVK := schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "AzureMachinePool"}
+		h := &testHandler{
+			children: []handler.Interface{
+				&testHandler{sources: []Source{{GVK: infrastructureGVK, MapFunc: ToOwner(ClusterGVK)}}},
+				&testHandler{sources: []Source{{GVK: infrastructureGVK, MapFunc: ToOwner(MachinePoolGVK)}}},
+				&testHandler{sources: []Source{{GVK: infrastructureGVK, MapFunc: ToOwner(MachinePoolGVK)}}},
+			},
+		}
+		infrastructure := &unstructured.Unstructured{}
+		infrastructure.SetNamespace("org-test")
+		infrastructure.SetOwnerReferences([]metav1.OwnerReference{
+			{APIVersion: capi.GroupVersion.String(), Kind: "Cluster", Name: "test1"},
+			{APIVersion: capiexp.GroupVersion.String(), Kind: "MachinePool", Name: "np1"},
+		})
+
+		// act
+		sources := Sources(h, MachinePoolGVK)
+
+		// assert
+		if len(sources) != 1 {
+			t.Fatalf("expected single AzureMachinePool source, got %v", sources)
+		}
+		requests := sources[0].MapFunc(infrastructure)
+		if len(requests) != 2 || requests[0].Name != "test1" || requests[1].Name != "np1" {
+			t.Errorf("expected requests for org-test/test1 and org-test/np1, got %v", requests)
+		}
+	})
+}
+
+func TestMapFuncs(t *testing.T) {
+	testName := "MachinePools are mapped to Clusters by label or Spec.ClusterName, other objects by owner references"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		labeled := &capiexp.MachinePool{ObjectMeta: metav1.ObjectMeta{Namespace: "org-test", Name: "np1", Labels: map[string]string{capi.ClusterLabelName: "test1"}}}
+		unlabeled := &capiexp.MachinePool{ObjectMeta: metav1.ObjectMeta{Namespace: "org-test", Name: "np2"}, Spec: capiexp.MachinePoolSpec{ClusterName: "test2"}}
+		owned := &capiexp.MachinePool{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-test",
+			Name:      "np3",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: capi.GroupVersion.String(), Kind: "Cluster", Name: "test3"},
+				{APIVersion: "other.io/v1", Kind: "Cluster", Name: "other"},
+			},
+		}}
+
+		// act
+		labeledRequests := MachinePoolToCluster(labeled)
+		unlabeledRequests := MachinePoolToCluster(unlabeled)
+		ownedRequests := ToOwner(ClusterGVK)(owned)
+
+		// assert
+		if len(labeledRequests) != 1 || labeledRequests[0].Name != "test1" || labeledRequests[0].Namespace != "org-test" {
+			t.Errorf("expected request for org-test/test1, got %v", labeledRequests)
+		}
+		if len(unlabeledRequests) != 1 || unlabeledRequests[0].Name != "test2" {
+			t.Errorf("expected request for org-test/test2, got %v", unlabeledRequests)
+		}
+		if len(ownedRequests) != 1 || ownedRequests[0].Name != "test3" {
+			t.Errorf("expected request for org-test/test3, got %v", ownedRequests)
+		}
+	})
+}
+
+func TestConditionsChanged(t *testing.T) {
+	testName := "update events pass only when conditions are changed"
+	t.Run(testName, func(t *testing.T) {
+		// arrange
+		t.Log(testName)
+		old := &capiexp.MachinePool{}
+		capiconditions.MarkTrue(old, capi.ReadyCondition)
+		relabeled := old.DeepCopy()
+		relabeled.SetLabels(map[string]string{"changed": "true"})
+		notReady := old.DeepCopy()
+		capiconditions.MarkFalse(notReady, capi.ReadyCondition, "NotReady", capi.ConditionSeverityWarning, "")
+
+		// act
+		relabeledPassed := ConditionsChanged().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: relabeled})
+		notReadyPassed := ConditionsChanged().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: notReady})
+
+		// assert
+		if relabeledPassed {
+			t.Error("expected update without condition changes to be filtered out")
+		}
+		if !notReadyPassed {
+			t.Error("expected update with condition changes to pass")
+		}
+	})
+}